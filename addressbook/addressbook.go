@@ -0,0 +1,237 @@
+// Package addressbook names Solana accounts: well-known programs, sysvars
+// and mints, labels from the user, and the token accounts of the wallets
+// being watched. Renderers show the names next to or instead of addresses,
+// and filters accept them in place of addresses.
+package addressbook
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+
+	"go-solana-tx-explorer/classify"
+	"go-solana-tx-explorer/decode"
+)
+
+// Kind tells where a label came from. User labels replace every other kind,
+// wallet labels replace token account and built-in ones, and token account
+// labels replace built-in ones.
+type Kind string
+
+const (
+	KindProgram      Kind = "program"       // well-known program
+	KindSysvar       Kind = "sysvar"        // sysvar account
+	KindMint         Kind = "mint"          // well-known token mint
+	KindTokenAccount Kind = "token_account" // a watched wallet's token account
+	KindWallet       Kind = "wallet"        // a watched wallet
+	KindUser         Kind = "user"          // the user's label file
+)
+
+var kindRank = map[Kind]int{
+	KindProgram:      0,
+	KindSysvar:       0,
+	KindMint:         0,
+	KindTokenAccount: 1,
+	KindWallet:       2,
+	KindUser:         3,
+}
+
+// Entry is one labeled account.
+type Entry struct {
+	Address solana.PublicKey `json:"address"`
+	Name    string           `json:"name"`
+	Kind    Kind             `json:"kind"`
+}
+
+// Book maps addresses to labels. It is safe for concurrent use, and a nil
+// Book knows no labels.
+type Book struct {
+	mu      sync.RWMutex
+	entries map[solana.PublicKey]Entry
+	// owned are the watched wallets whose token accounts get labels.
+	owned map[solana.PublicKey]bool
+}
+
+// New returns a book holding the well-known programs, sysvars and mints.
+func New() *Book {
+	b := &Book{
+		entries: make(map[solana.PublicKey]Entry, len(wellKnown)),
+		owned:   make(map[solana.PublicKey]bool),
+	}
+	for _, e := range wellKnown {
+		b.entries[e.Address] = e
+	}
+	return b
+}
+
+// Add labels address as name unless it already has a label of a higher
+// kind.
+func (b *Book) Add(address solana.PublicKey, name string, kind Kind) {
+	name = strings.TrimSpace(name)
+	if b == nil || name == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.add(Entry{Address: address, Name: name, Kind: kind})
+}
+
+func (b *Book) add(e Entry) {
+	if prev, ok := b.entries[e.Address]; ok && kindRank[prev.Kind] > kindRank[e.Kind] {
+		return
+	}
+	b.entries[e.Address] = e
+}
+
+// AddWallet marks address as a watched wallet, labeled name when it is not
+// empty. LabelTokenAccounts names the wallet's token accounts after it.
+func (b *Book) AddWallet(address solana.PublicKey, name string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.owned[address] = true
+	if name = strings.TrimSpace(name); name != "" {
+		b.add(Entry{Address: address, Name: name, Kind: KindWallet})
+	}
+}
+
+// Lookup returns the label of address.
+func (b *Book) Lookup(address solana.PublicKey) (Entry, bool) {
+	if b == nil {
+		return Entry{}, false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	e, ok := b.entries[address]
+	return e, ok
+}
+
+// Name returns the label of address, or "" when it has none.
+func (b *Book) Name(address solana.PublicKey) string {
+	e, _ := b.Lookup(address)
+	return e.Name
+}
+
+// Entries returns every label: the user's first, then watched wallets and
+// their token accounts, then the built-in ones, each sorted by name.
+func (b *Book) Entries() []Entry {
+	if b == nil {
+		return nil
+	}
+	b.mu.RLock()
+	out := make([]Entry, 0, len(b.entries))
+	for _, e := range b.entries {
+		out = append(out, e)
+	}
+	b.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		ri, rj := kindRank[out[i].Kind], kindRank[out[j].Kind]
+		if ri != rj {
+			return ri > rj
+		}
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// ResolveAddress returns the address labeled name. Names match ignoring
+// case, spaces, '-' and '_', so "token-program" finds "Token Program".
+func (b *Book) ResolveAddress(name string) (solana.PublicKey, error) {
+	if b == nil {
+		return solana.PublicKey{}, fmt.Errorf("unknown label %q", name)
+	}
+	want := normalize(name)
+	var found []Entry
+	b.mu.RLock()
+	for _, e := range b.entries {
+		if normalize(e.Name) == want {
+			found = append(found, e)
+		}
+	}
+	b.mu.RUnlock()
+	switch len(found) {
+	case 0:
+		return solana.PublicKey{}, fmt.Errorf("unknown label %q", name)
+	case 1:
+		return found[0].Address, nil
+	default:
+		return solana.PublicKey{}, fmt.Errorf("label %q is ambiguous (%s and %s)", name, found[0].Address, found[1].Address)
+	}
+}
+
+func normalize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// LabelTokenAccounts labels the token accounts in tx owned by watched
+// wallets, e.g. "alice USDC ATA" for an associated token account and
+// "alice USDC token account" for any other.
+func (b *Book) LabelTokenAccounts(tx decode.TransactionInfo) {
+	if b == nil || tx.Meta == nil {
+		return
+	}
+	keys := classify.TransactionAccountKeys(tx)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.owned) == 0 {
+		return
+	}
+	for _, tb := range tx.Meta.PostTokenBalances {
+		if tb.Owner == nil || !b.owned[*tb.Owner] || int(tb.AccountIndex) >= len(keys) {
+			continue
+		}
+		account := keys[tb.AccountIndex]
+		if _, ok := b.entries[account]; ok {
+			continue
+		}
+		program := solana.TokenProgramID
+		if tb.ProgramId != nil {
+			program = *tb.ProgramId
+		}
+		kind := "token account"
+		if ata, err := associatedTokenAddress(*tb.Owner, tb.Mint, program); err == nil && ata == account {
+			kind = "ATA"
+		}
+		b.add(Entry{
+			Address: account,
+			Name:    fmt.Sprintf("%s %s %s", b.shortName(*tb.Owner, "wallet"), b.shortName(tb.Mint, ""), kind),
+			Kind:    KindTokenAccount,
+		})
+	}
+}
+
+// shortName is the label of address, fallback when there is none, or else
+// the address's first characters. Callers hold b.mu.
+func (b *Book) shortName(address solana.PublicKey, fallback string) string {
+	if e, ok := b.entries[address]; ok {
+		return e.Name
+	}
+	if fallback != "" {
+		return fallback
+	}
+	return address.String()[:8]
+}
+
+// associatedTokenAddress derives the associated token account of owner for
+// mint under a token program (Token or Token-2022).
+func associatedTokenAddress(owner, mint, program solana.PublicKey) (solana.PublicKey, error) {
+	addr, _, err := solana.FindProgramAddress(
+		[][]byte{owner[:], program[:], mint[:]},
+		solana.SPLAssociatedTokenAccountProgramID,
+	)
+	return addr, err
+}
@@ -0,0 +1,50 @@
+package addressbook
+
+import (
+	"github.com/gagliardetto/solana-go"
+
+	"go-solana-tx-explorer/decode"
+)
+
+// wellKnown are the built-in labels. Mints are mainnet addresses; they mean
+// nothing elsewhere, so labeling them everywhere is harmless.
+var wellKnown = []Entry{
+	{solana.SystemProgramID, "System Program", KindProgram},
+	{solana.TokenProgramID, "Token Program", KindProgram},
+	{solana.Token2022ProgramID, "Token-2022 Program", KindProgram},
+	{solana.SPLAssociatedTokenAccountProgramID, "Associated Token Program", KindProgram},
+	{solana.MemoProgramID, "Memo Program", KindProgram},
+	{solana.MustPublicKeyFromBase58("Memo1UhkJRfHyvLMcVucJwxXeuD728EqVDDwQDxFMNo"), "Memo Program v1", KindProgram},
+	{decode.ComputeBudgetProgramID, "Compute Budget Program", KindProgram},
+	{solana.StakeProgramID, "Stake Program", KindProgram},
+	{solana.VoteProgramID, "Vote Program", KindProgram},
+	{solana.ConfigProgramID, "Config Program", KindProgram},
+	{solana.AddressLookupTableProgramID, "Address Lookup Table Program", KindProgram},
+	{solana.BPFLoaderDeprecatedProgramID, "BPF Loader (deprecated)", KindProgram},
+	{solana.BPFLoaderProgramID, "BPF Loader", KindProgram},
+	{solana.BPFLoaderUpgradeableProgramID, "BPF Upgradeable Loader", KindProgram},
+	{solana.MustPublicKeyFromBase58("Ed25519SigVerify111111111111111111111111111"), "Ed25519 Program", KindProgram},
+	{solana.Secp256k1ProgramID, "Secp256k1 Program", KindProgram},
+	{solana.TokenMetadataProgramID, "Metaplex Token Metadata", KindProgram},
+	{solana.MustPublicKeyFromBase58("JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4"), "Jupiter v6", KindProgram},
+	{solana.MustPublicKeyFromBase58("whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc"), "Orca Whirlpools", KindProgram},
+	{solana.MustPublicKeyFromBase58("675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8"), "Raydium AMM v4", KindProgram},
+	{solana.MustPublicKeyFromBase58("CAMMCzo5YL8w4VFF8KVHrK22GGUsp5VTaW7grrKgrWqK"), "Raydium CLMM", KindProgram},
+
+	{solana.SysVarClockPubkey, "Clock Sysvar", KindSysvar},
+	{solana.SysVarEpochSchedulePubkey, "Epoch Schedule Sysvar", KindSysvar},
+	{solana.SysVarFeesPubkey, "Fees Sysvar", KindSysvar},
+	{solana.SysVarInstructionsPubkey, "Instructions Sysvar", KindSysvar},
+	{solana.SysVarRecentBlockHashesPubkey, "Recent Blockhashes Sysvar", KindSysvar},
+	{solana.SysVarRentPubkey, "Rent Sysvar", KindSysvar},
+	{solana.SysVarRewardsPubkey, "Rewards Sysvar", KindSysvar},
+	{solana.SysVarSlotHashesPubkey, "Slot Hashes Sysvar", KindSysvar},
+	{solana.SysVarSlotHistoryPubkey, "Slot History Sysvar", KindSysvar},
+	{solana.SysVarStakeHistoryPubkey, "Stake History Sysvar", KindSysvar},
+	{solana.MustPublicKeyFromBase58("SysvarEpochRewards1111111111111111111111111"), "Epoch Rewards Sysvar", KindSysvar},
+	{solana.MustPublicKeyFromBase58("SysvarLastRestartS1ot1111111111111111111111"), "Last Restart Slot Sysvar", KindSysvar},
+
+	{solana.SolMint, "Wrapped SOL", KindMint},
+	{solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"), "USDC", KindMint},
+	{solana.MustPublicKeyFromBase58("Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB"), "USDT", KindMint},
+}
@@ -0,0 +1,519 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"go-solana-tx-explorer/classify"
+	"go-solana-tx-explorer/decode"
+)
+
+// defaultDedupWindow is how long an alert with the same rule, wallet and key
+// stays suppressed after it fired.
+const defaultDedupWindow = 10 * time.Minute
+
+// defaultSeedLimit is how many recent transactions new_counterparty learns
+// existing counterparties from; maxSeedLimit is one getSignaturesForAddress
+// page.
+const (
+	defaultSeedLimit = 200
+	maxSeedLimit     = 1000
+)
+
+// Severity ranks alerts. Unknown values are rejected when rules are loaded.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Rank orders severities so sinks can filter by a minimum level.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func parseSeverity(v string) (Severity, error) {
+	switch s := Severity(strings.ToLower(v)); s {
+	case "":
+		return SeverityWarning, nil
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown severity %q (want info, warning or critical)", v)
+}
+
+// TransactionEvent is a new transaction seen by the listener for one wallet,
+// already fetched and classified from that wallet's point of view.
+type TransactionEvent struct {
+	Wallet         WatchedWallet
+	Tx             decode.TransactionInfo
+	Classification classify.Classification
+}
+
+// Alert is a rule match that survived deduplication.
+type Alert struct {
+	Rule      string    `json:"rule"`
+	Severity  Severity  `json:"severity"`
+	Wallet    string    `json:"wallet"`
+	Address   string    `json:"address"`
+	Signature string    `json:"signature"`
+	Slot      uint64    `json:"slot"`
+	Message   string    `json:"message"`
+	Time      time.Time `json:"time"`
+	// DedupKey identifies repeats of the same alert; sinks may use it as an
+	// idempotency key.
+	DedupKey string `json:"dedup_key"`
+
+	// account and key locate the finding for Release.
+	account solana.PublicKey
+	key     string
+}
+
+// RuleConfig is one rule in a rules file. Type-specific fields are ignored by
+// other rule types.
+type RuleConfig struct {
+	Name        string   `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Type        string   `json:"type" yaml:"type" toml:"type"`
+	Severity    string   `json:"severity,omitempty" yaml:"severity,omitempty" toml:"severity,omitempty"`
+	Wallets     []string `json:"wallets,omitempty" yaml:"wallets,omitempty" toml:"wallets,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty" toml:"tags,omitempty"`
+	DedupWindow string   `json:"dedup_window,omitempty" yaml:"dedup_window,omitempty" toml:"dedup_window,omitempty"`
+
+	// outgoing_sol
+	MinSOL float64 `json:"min_sol,omitempty" yaml:"min_sol,omitempty" toml:"min_sol,omitempty"`
+	// program_allowlist
+	Programs []string `json:"programs,omitempty" yaml:"programs,omitempty" toml:"programs,omitempty"`
+	Strict   bool     `json:"strict,omitempty" yaml:"strict,omitempty" toml:"strict,omitempty"`
+	// new_counterparty
+	SeedLimit int `json:"seed_limit,omitempty" yaml:"seed_limit,omitempty" toml:"seed_limit,omitempty"`
+}
+
+// RulesFile is the on-disk rules definition.
+type RulesFile struct {
+	DedupWindow string       `json:"dedup_window,omitempty" yaml:"dedup_window,omitempty" toml:"dedup_window,omitempty"`
+	Rules       []RuleConfig `json:"rules" yaml:"rules" toml:"rules"`
+}
+
+// Finding is a raw rule match before severity, scope and dedup are applied.
+// Key distinguishes matches of the same rule for the same wallet.
+type Finding struct {
+	Message string
+	Key     string
+}
+
+// Rule inspects one transaction event.
+type Rule interface {
+	Evaluate(ev TransactionEvent) []Finding
+}
+
+// seeder is implemented by rules that need the wallet's recent history before
+// the first live event (e.g. to know existing counterparties).
+type seeder interface {
+	// SeedLimit is how many recent transactions Seed wants.
+	SeedLimit() int
+	Seed(wallet solana.PublicKey, txs []decode.TransactionInfo)
+}
+
+// releaser is implemented by rules that change state when they report a
+// finding; Release undoes that so the finding is reported again.
+type releaser interface {
+	Release(wallet solana.PublicKey, key string)
+}
+
+type compiledRule struct {
+	name     string
+	severity Severity
+	wallets  []string
+	tags     []string
+	window   time.Duration
+	rule     Rule
+}
+
+func (r *compiledRule) applies(w WatchedWallet) bool {
+	if len(r.wallets) == 0 && len(r.tags) == 0 {
+		return true
+	}
+	for _, want := range r.wallets {
+		if want == w.Address || want == w.Account.String() || strings.EqualFold(want, w.Label) {
+			return true
+		}
+	}
+	return len(r.tags) > 0 && w.hasAnyTag(r.tags)
+}
+
+// AlertEngine evaluates rules against transaction events and suppresses
+// repeats within each rule's dedup window. It is safe for concurrent use.
+type AlertEngine struct {
+	rules []*compiledRule
+
+	mu    sync.Mutex
+	fired map[string]time.Time
+	now   func() time.Time
+}
+
+// LoadAlertRules reads a rules file (.yaml, .json or .toml).
+func LoadAlertRules(path string) (*AlertEngine, error) {
+	var file RulesFile
+	if err := decodeConfigFile(path, &file); err != nil {
+		return nil, fmt.Errorf("rules %s: %w", path, err)
+	}
+	engine, err := NewAlertEngine(file)
+	if err != nil {
+		return nil, fmt.Errorf("rules %s: %w", path, err)
+	}
+	return engine, nil
+}
+
+// NewAlertEngine validates and compiles the rules in file.
+func NewAlertEngine(file RulesFile) (*AlertEngine, error) {
+	if len(file.Rules) == 0 {
+		return nil, errors.New("no rules defined")
+	}
+	window := defaultDedupWindow
+	if file.DedupWindow != "" {
+		d, err := time.ParseDuration(file.DedupWindow)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid dedup_window %q", file.DedupWindow)
+		}
+		window = d
+	}
+
+	e := &AlertEngine{fired: make(map[string]time.Time), now: time.Now}
+	names := make(map[string]bool)
+	for i, rc := range file.Rules {
+		cr, err := compileRule(rc, window)
+		if err != nil {
+			return nil, fmt.Errorf("rule #%d: %w", i+1, err)
+		}
+		if names[cr.name] {
+			return nil, fmt.Errorf("rule #%d: duplicate name %q", i+1, cr.name)
+		}
+		names[cr.name] = true
+		e.rules = append(e.rules, cr)
+	}
+	return e, nil
+}
+
+func compileRule(rc RuleConfig, window time.Duration) (*compiledRule, error) {
+	cr := &compiledRule{name: rc.Name, wallets: rc.Wallets, tags: rc.Tags, window: window}
+	if cr.name == "" {
+		cr.name = rc.Type
+	}
+	var err error
+	if cr.severity, err = parseSeverity(rc.Severity); err != nil {
+		return nil, err
+	}
+	if rc.DedupWindow != "" {
+		d, err := time.ParseDuration(rc.DedupWindow)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid dedup_window %q", rc.DedupWindow)
+		}
+		cr.window = d
+	}
+
+	switch rc.Type {
+	case "outgoing_sol":
+		if rc.MinSOL <= 0 {
+			return nil, errors.New("outgoing_sol needs min_sol > 0")
+		}
+		cr.rule = outgoingSOLRule{minLamports: int64(math.Round(rc.MinSOL * 1e9))}
+	case "failed_tx":
+		cr.rule = failedTxRule{}
+	case "program_allowlist":
+		if len(rc.Programs) == 0 && rc.Strict {
+			return nil, errors.New("program_allowlist with strict needs at least one program")
+		}
+		allowed := make(map[solana.PublicKey]bool)
+		if !rc.Strict {
+			for _, p := range baselinePrograms {
+				allowed[p] = true
+			}
+		}
+		for _, p := range rc.Programs {
+			key, err := solana.PublicKeyFromBase58(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid program %q", p)
+			}
+			allowed[key] = true
+		}
+		cr.rule = programAllowlistRule{allowed: allowed}
+	case "token_approval":
+		cr.rule = tokenApprovalRule{}
+	case "new_counterparty":
+		limit := defaultSeedLimit
+		if rc.SeedLimit != 0 {
+			limit = rc.SeedLimit
+		}
+		if limit < 0 || limit > maxSeedLimit {
+			return nil, fmt.Errorf("seed_limit must be between 1 and %d", maxSeedLimit)
+		}
+		cr.rule = &newCounterpartyRule{seedLimit: limit, seen: make(map[solana.PublicKey]map[solana.PublicKey]bool)}
+	case "":
+		return nil, errors.New("missing type")
+	default:
+		return nil, fmt.Errorf("unknown type %q (want outgoing_sol, failed_tx, program_allowlist, token_approval or new_counterparty)", rc.Type)
+	}
+	return cr, nil
+}
+
+// SeedLimit returns how many of w's recent transactions the rules that apply
+// to it want before the first live event; 0 means none.
+func (e *AlertEngine) SeedLimit(w WatchedWallet) int {
+	limit := 0
+	for _, r := range e.rules {
+		if s, ok := r.rule.(seeder); ok && r.applies(w) {
+			limit = max(limit, s.SeedLimit())
+		}
+	}
+	return limit
+}
+
+// Seed primes the stateful rules that apply to w with its recent history.
+func (e *AlertEngine) Seed(w WatchedWallet, txs []decode.TransactionInfo) {
+	for _, r := range e.rules {
+		if s, ok := r.rule.(seeder); ok && r.applies(w) {
+			s.Seed(w.Account, txs)
+		}
+	}
+}
+
+// Evaluate runs every rule that applies to the event's wallet and returns the
+// alerts not suppressed by deduplication.
+func (e *AlertEngine) Evaluate(ev TransactionEvent) []Alert {
+	var alerts []Alert
+	for _, r := range e.rules {
+		if !r.applies(ev.Wallet) {
+			continue
+		}
+		for _, f := range r.rule.Evaluate(ev) {
+			key := r.name + "|" + ev.Wallet.Account.String() + "|" + f.Key
+			if !e.claim(key, r.window) {
+				continue
+			}
+			alertsFiredTotal.Inc(r.name, string(r.severity))
+			alerts = append(alerts, Alert{
+				Rule:      r.name,
+				Severity:  r.severity,
+				Wallet:    ev.Wallet.Label,
+				Address:   ev.Wallet.Account.String(),
+				Signature: ev.Tx.Signature,
+				Slot:      ev.Tx.Slot,
+				Message:   f.Message,
+				Time:      e.now(),
+				DedupKey:  key,
+				account:   ev.Wallet.Account,
+				key:       f.Key,
+			})
+		}
+	}
+	return alerts
+}
+
+// claim records key as fired and reports whether it was outside its window.
+// Expired entries are pruned on the way so the map stays bounded.
+func (e *AlertEngine) claim(key string, window time.Duration) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	if last, ok := e.fired[key]; ok && now.Sub(last) < window {
+		return false
+	}
+	e.fired[key] = now
+	for k, t := range e.fired {
+		if now.Sub(t) > 24*time.Hour {
+			delete(e.fired, k)
+		}
+	}
+	return true
+}
+
+// Release forgets that a's dedup key fired, and rolls back the state its rule
+// kept for it, so the alert is raised again when its transaction is
+// re-evaluated after a failed delivery.
+func (e *AlertEngine) Release(a Alert) {
+	e.mu.Lock()
+	delete(e.fired, a.DedupKey)
+	e.mu.Unlock()
+	for _, r := range e.rules {
+		if rel, ok := r.rule.(releaser); ok && r.name == a.Rule {
+			rel.Release(a.account, a.key)
+		}
+	}
+}
+
+// baselinePrograms are allowed by program_allowlist unless strict is set:
+// nearly every transaction touches them.
+var baselinePrograms = []solana.PublicKey{
+	solana.SystemProgramID,
+	decode.ComputeBudgetProgramID,
+	solana.TokenProgramID,
+	solana.Token2022ProgramID,
+	solana.SPLAssociatedTokenAccountProgramID,
+	solana.MemoProgramID,
+}
+
+type outgoingSOLRule struct{ minLamports int64 }
+
+func (r outgoingSOLRule) Evaluate(ev TransactionEvent) []Finding {
+	if -ev.Classification.SOLChange < r.minLamports {
+		return nil
+	}
+	return []Finding{{
+		Message: fmt.Sprintf("outgoing %.9f SOL (threshold %.9f)", float64(-ev.Classification.SOLChange)/1e9, float64(r.minLamports)/1e9),
+		Key:     ev.Tx.Signature,
+	}}
+}
+
+type failedTxRule struct{}
+
+func (failedTxRule) Evaluate(ev TransactionEvent) []Finding {
+	if ev.Tx.Meta == nil || ev.Tx.Meta.Err == nil {
+		return nil
+	}
+	return []Finding{{Message: fmt.Sprintf("transaction failed: %v", ev.Tx.Meta.Err), Key: ev.Tx.Signature}}
+}
+
+type programAllowlistRule struct{ allowed map[solana.PublicKey]bool }
+
+func (r programAllowlistRule) Evaluate(ev TransactionEvent) []Finding {
+	var out []Finding
+	for _, p := range ev.Classification.Programs {
+		if r.allowed[p] {
+			continue
+		}
+		msg := fmt.Sprintf("interacted with program %s, not on the allowlist", p)
+		if name := decode.ProgramName(p); name != "" {
+			msg = fmt.Sprintf("interacted with %s program %s, not on the allowlist", name, p)
+		}
+		out = append(out, Finding{Message: msg, Key: p.String()})
+	}
+	return out
+}
+
+type tokenApprovalRule struct{}
+
+// Evaluate reports Approve/ApproveChecked instructions, top-level or inner,
+// where the wallet is the owner granting the delegation.
+func (tokenApprovalRule) Evaluate(ev TransactionEvent) []Finding {
+	if ev.Tx.Transaction == nil {
+		return nil
+	}
+	keys := classify.TransactionAccountKeys(ev.Tx)
+	var out []Finding
+	check := func(programIdx uint16, accounts []uint16, data []byte) {
+		if int(programIdx) >= len(keys) {
+			return
+		}
+		program := keys[programIdx]
+		if !program.Equals(solana.TokenProgramID) && !program.Equals(solana.Token2022ProgramID) {
+			return
+		}
+		d := decode.DecodeInstruction(program, data)
+		var delegateIdx, ownerIdx int
+		switch d.Name {
+		case "Approve":
+			delegateIdx, ownerIdx = 1, 2
+		case "ApproveChecked":
+			delegateIdx, ownerIdx = 2, 3
+		default:
+			return
+		}
+		if ownerIdx >= len(accounts) || int(accounts[ownerIdx]) >= len(keys) || int(accounts[delegateIdx]) >= len(keys) {
+			return
+		}
+		if !keys[accounts[ownerIdx]].Equals(ev.Wallet.Account) {
+			return
+		}
+		delegate := keys[accounts[delegateIdx]]
+		msg := fmt.Sprintf("delegated tokens to %s", delegate)
+		if d.Details != "" {
+			msg += " (" + d.Details + ")"
+		}
+		out = append(out, Finding{Message: msg, Key: ev.Tx.Signature + "|" + delegate.String()})
+	}
+
+	for _, instr := range ev.Tx.Transaction.Message.Instructions {
+		check(instr.ProgramIDIndex, instr.Accounts, instr.Data)
+	}
+	if ev.Tx.Meta != nil {
+		for _, inner := range ev.Tx.Meta.InnerInstructions {
+			for _, instr := range inner.Instructions {
+				check(instr.ProgramIDIndex, instr.Accounts, instr.Data)
+			}
+		}
+	}
+	return out
+}
+
+// newCounterpartyRule remembers every counterparty per wallet and reports the
+// first transaction with one it has not seen.
+type newCounterpartyRule struct {
+	seedLimit int
+
+	mu   sync.Mutex
+	seen map[solana.PublicKey]map[solana.PublicKey]bool
+}
+
+func (r *newCounterpartyRule) known(wallet solana.PublicKey) map[solana.PublicKey]bool {
+	m, ok := r.seen[wallet]
+	if !ok {
+		m = make(map[solana.PublicKey]bool)
+		r.seen[wallet] = m
+	}
+	return m
+}
+
+func (r *newCounterpartyRule) SeedLimit() int { return r.seedLimit }
+
+func (r *newCounterpartyRule) Seed(wallet solana.PublicKey, txs []decode.TransactionInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	known := r.known(wallet)
+	for _, tx := range txs {
+		for _, cp := range classify.Counterparties(tx, wallet) {
+			known[cp] = true
+		}
+	}
+}
+
+func (r *newCounterpartyRule) Evaluate(ev TransactionEvent) []Finding {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	known := r.known(ev.Wallet.Account)
+	var out []Finding
+	for _, cp := range ev.Classification.Counterparties {
+		if known[cp] {
+			continue
+		}
+		known[cp] = true
+		out = append(out, Finding{Message: fmt.Sprintf("new counterparty %s", cp), Key: cp.String()})
+	}
+	return out
+}
+
+// Release forgets the counterparty reported under key, so an undelivered
+// alert for it fires again.
+func (r *newCounterpartyRule) Release(wallet solana.PublicKey, key string) {
+	cp, err := solana.PublicKeyFromBase58(key)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.known(wallet), cp)
+}
@@ -0,0 +1,423 @@
+// Package classify works out what a transaction did from one wallet's point
+// of view (transfer, swap, stake and so on, with its balance changes and
+// counterparties) and filters histories on those properties.
+package classify
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/decode"
+)
+
+// TransactionType is the coarse category a transaction falls into from the
+// point of view of one wallet.
+type TransactionType string
+
+const (
+	TxTypeSOLTransfer   TransactionType = "sol_transfer"
+	TxTypeTokenTransfer TransactionType = "token_transfer"
+	TxTypeSwap          TransactionType = "swap"
+	TxTypeApproval      TransactionType = "approval"
+	TxTypeStake         TransactionType = "stake"
+	TxTypeVote          TransactionType = "vote"
+	TxTypeAccount       TransactionType = "account"
+	TxTypeProgram       TransactionType = "program"
+	TxTypeUnknown       TransactionType = "unknown"
+)
+
+// TransactionTypes lists every classification, used to validate filters.
+var TransactionTypes = []TransactionType{
+	TxTypeSOLTransfer, TxTypeTokenTransfer, TxTypeSwap, TxTypeApproval,
+	TxTypeStake, TxTypeVote, TxTypeAccount, TxTypeProgram, TxTypeUnknown,
+}
+
+// swapDustLamports is the SOL movement below which a SOL delta is ignored when
+// deciding whether a transaction is a swap (covers fees and ATA rent).
+const swapDustLamports = 10_000_000
+
+// TokenChange is the net change of one mint for the classified wallet.
+type TokenChange struct {
+	Mint     solana.PublicKey `json:"mint"`
+	Delta    float64          `json:"delta"`
+	Decimals uint8            `json:"decimals"`
+}
+
+// Classification describes what a transaction did for a given wallet.
+type Classification struct {
+	Type           TransactionType    `json:"type"`
+	Direction      string             `json:"direction,omitempty"` // "in", "out" or "self"
+	SOLChange      int64              `json:"sol_change"`          // lamports, fee included
+	TokenChanges   []TokenChange      `json:"token_changes,omitempty"`
+	Programs       []solana.PublicKey `json:"programs,omitempty"`
+	Mints          []solana.PublicKey `json:"mints,omitempty"`
+	Counterparties []solana.PublicKey `json:"counterparties,omitempty"`
+}
+
+// TransactionAccountKeys returns the full account list the meta balances are
+// indexed by: static keys followed by writable then readonly loaded addresses.
+func TransactionAccountKeys(tx decode.TransactionInfo) []solana.PublicKey {
+	if tx.Transaction == nil {
+		return nil
+	}
+	keys := make([]solana.PublicKey, 0, len(tx.Transaction.Message.AccountKeys))
+	keys = append(keys, tx.Transaction.Message.AccountKeys...)
+	if tx.Meta != nil {
+		keys = append(keys, tx.Meta.LoadedAddresses.Writable...)
+		keys = append(keys, tx.Meta.LoadedAddresses.ReadOnly...)
+	}
+	return keys
+}
+
+// ClassifyTransaction derives a Classification for tx relative to wallet from
+// the decoded instructions and the balance changes in the meta.
+func ClassifyTransaction(tx decode.TransactionInfo, wallet solana.PublicKey) Classification {
+	c := Classification{Type: TxTypeUnknown}
+	keys := TransactionAccountKeys(tx)
+
+	c.Programs = involvedPrograms(tx, keys)
+	c.SOLChange = walletSOLChange(tx, keys, wallet)
+	c.TokenChanges = walletTokenChanges(tx, wallet)
+	c.Mints = involvedMints(tx)
+	c.Counterparties = Counterparties(tx, wallet)
+
+	if tx.Transaction == nil {
+		return c
+	}
+
+	var (
+		hasSystemTransfer, hasTokenTransfer, hasApproval bool
+		hasStake, hasVote, hasAccountOps, hasOther       bool
+	)
+	for _, instr := range tx.Transaction.Message.Instructions {
+		if int(instr.ProgramIDIndex) >= len(keys) {
+			continue
+		}
+		programID := keys[instr.ProgramIDIndex]
+		d := decode.DecodeInstruction(programID, instr.Data)
+		switch programID {
+		case solana.SystemProgramID:
+			if d.Name == "Transfer" || d.Name == "TransferWithSeed" {
+				hasSystemTransfer = true
+			} else {
+				hasAccountOps = true
+			}
+		case solana.TokenProgramID, solana.Token2022ProgramID:
+			switch d.Name {
+			case "Transfer", "TransferChecked":
+				hasTokenTransfer = true
+			case "Approve", "ApproveChecked", "Revoke":
+				hasApproval = true
+			case "SyncNative", "CloseAccount", "InitializeAccount", "InitializeAccount2", "InitializeAccount3":
+				hasAccountOps = true
+			default:
+				hasOther = true
+			}
+		case solana.SPLAssociatedTokenAccountProgramID:
+			hasAccountOps = true
+		case solana.StakeProgramID:
+			hasStake = true
+		case solana.VoteProgramID:
+			hasVote = true
+		case solana.MemoProgramID:
+			// memos annotate other instructions; they never decide the type
+		case decode.ComputeBudgetProgramID:
+			// budget requests accompany other instructions
+		default:
+			hasOther = true
+		}
+	}
+
+	switch {
+	case hasVote:
+		c.Type = TxTypeVote
+	case hasStake:
+		c.Type = TxTypeStake
+	case isSwap(tx, c, wallet):
+		c.Type = TxTypeSwap
+	case hasApproval && !hasTokenTransfer && !hasOther:
+		c.Type = TxTypeApproval
+	case hasTokenTransfer && !hasOther:
+		c.Type = TxTypeTokenTransfer
+	case hasSystemTransfer && !hasOther:
+		c.Type = TxTypeSOLTransfer
+	case hasOther:
+		c.Type = TxTypeProgram
+	case hasAccountOps:
+		c.Type = TxTypeAccount
+	}
+
+	c.Direction = transferDirection(tx, c, wallet)
+	return c
+}
+
+// isSwap reports whether the wallet ended up with at least one asset more and
+// one asset less, ignoring SOL dust from fees and rent.
+func isSwap(tx decode.TransactionInfo, c Classification, wallet solana.PublicKey) bool {
+	var gained, lost bool
+	for _, tc := range c.TokenChanges {
+		// wSOL moves are treated as SOL moves
+		if tc.Mint.Equals(solana.WrappedSol) {
+			continue
+		}
+		if tc.Delta > 0 {
+			gained = true
+		} else if tc.Delta < 0 {
+			lost = true
+		}
+	}
+	sol := nativeSOLChange(tx, c, wallet)
+	if sol > swapDustLamports {
+		gained = true
+	} else if sol < -swapDustLamports {
+		lost = true
+	}
+	return gained && lost
+}
+
+// nativeSOLChange is the wallet's SOL change with fees added back and wSOL
+// movements folded in, so unwrapping during a swap still counts as SOL.
+func nativeSOLChange(tx decode.TransactionInfo, c Classification, wallet solana.PublicKey) int64 {
+	sol := c.SOLChange
+	if tx.Meta != nil && tx.Transaction != nil && len(tx.Transaction.Message.AccountKeys) > 0 &&
+		tx.Transaction.Message.AccountKeys[0].Equals(wallet) {
+		sol += int64(tx.Meta.Fee)
+	}
+	for _, tc := range c.TokenChanges {
+		if tc.Mint.Equals(solana.WrappedSol) {
+			sol += int64(math.Round(tc.Delta * 1e9))
+		}
+	}
+	return sol
+}
+
+// transferDirection works out whether value flowed into or out of the wallet.
+func transferDirection(tx decode.TransactionInfo, c Classification, wallet solana.PublicKey) string {
+	switch c.Type {
+	case TxTypeSOLTransfer:
+		sol := nativeSOLChange(tx, c, wallet)
+		switch {
+		case sol > 0:
+			return "in"
+		case sol < 0:
+			return "out"
+		}
+		return "self"
+	case TxTypeTokenTransfer:
+		var in, out bool
+		for _, tc := range c.TokenChanges {
+			if tc.Delta > 0 {
+				in = true
+			} else if tc.Delta < 0 {
+				out = true
+			}
+		}
+		switch {
+		case in && !out:
+			return "in"
+		case out && !in:
+			return "out"
+		}
+		return "self"
+	}
+	return ""
+}
+
+// walletSOLChange returns post - pre lamports for wallet, or 0 when the wallet
+// is not among the transaction's accounts.
+func walletSOLChange(tx decode.TransactionInfo, keys []solana.PublicKey, wallet solana.PublicKey) int64 {
+	if tx.Meta == nil {
+		return 0
+	}
+	for i, k := range keys {
+		if k.Equals(wallet) && i < len(tx.Meta.PreBalances) && i < len(tx.Meta.PostBalances) {
+			return int64(tx.Meta.PostBalances[i]) - int64(tx.Meta.PreBalances[i])
+		}
+	}
+	return 0
+}
+
+// walletTokenChanges nets pre and post token balances owned by wallet per mint.
+func walletTokenChanges(tx decode.TransactionInfo, wallet solana.PublicKey) []TokenChange {
+	if tx.Meta == nil {
+		return nil
+	}
+	deltas := make(map[solana.PublicKey]*TokenChange)
+	apply := func(balances []rpc.TokenBalance, sign float64) {
+		for _, b := range balances {
+			if b.Owner == nil || !b.Owner.Equals(wallet) || b.UiTokenAmount == nil {
+				continue
+			}
+			amount, err := strconv.ParseFloat(b.UiTokenAmount.UiAmountString, 64)
+			if err != nil {
+				continue
+			}
+			tc, ok := deltas[b.Mint]
+			if !ok {
+				tc = &TokenChange{Mint: b.Mint, Decimals: b.UiTokenAmount.Decimals}
+				deltas[b.Mint] = tc
+			}
+			tc.Delta += sign * amount
+		}
+	}
+	apply(tx.Meta.PreTokenBalances, -1)
+	apply(tx.Meta.PostTokenBalances, 1)
+
+	out := make([]TokenChange, 0, len(deltas))
+	for _, tc := range deltas {
+		if tc.Delta != 0 {
+			out = append(out, *tc)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Mint.String() < out[j].Mint.String() })
+	return out
+}
+
+// involvedPrograms lists every program invoked, top-level and inner, once.
+func involvedPrograms(tx decode.TransactionInfo, keys []solana.PublicKey) []solana.PublicKey {
+	if tx.Transaction == nil {
+		return nil
+	}
+	seen := make(map[solana.PublicKey]bool)
+	var out []solana.PublicKey
+	add := func(idx uint16) {
+		if int(idx) >= len(keys) || seen[keys[idx]] {
+			return
+		}
+		seen[keys[idx]] = true
+		out = append(out, keys[idx])
+	}
+	for _, instr := range tx.Transaction.Message.Instructions {
+		add(instr.ProgramIDIndex)
+	}
+	if tx.Meta != nil {
+		for _, inner := range tx.Meta.InnerInstructions {
+			for _, instr := range inner.Instructions {
+				add(instr.ProgramIDIndex)
+			}
+		}
+	}
+	return out
+}
+
+// involvedMints lists every mint that appears in the token balances.
+func involvedMints(tx decode.TransactionInfo) []solana.PublicKey {
+	if tx.Meta == nil {
+		return nil
+	}
+	seen := make(map[solana.PublicKey]bool)
+	var out []solana.PublicKey
+	for _, balances := range [][]rpc.TokenBalance{tx.Meta.PreTokenBalances, tx.Meta.PostTokenBalances} {
+		for _, b := range balances {
+			if !seen[b.Mint] {
+				seen[b.Mint] = true
+				out = append(out, b.Mint)
+			}
+		}
+	}
+	return out
+}
+
+// Counterparties returns the accounts, other than wallet, whose SOL balance
+// changed or who own a token account whose balance changed. Programs, sysvars
+// and token accounts themselves are left out so the list names real parties.
+func Counterparties(tx decode.TransactionInfo, wallet solana.PublicKey) []solana.PublicKey {
+	if tx.Meta == nil {
+		return nil
+	}
+	keys := TransactionAccountKeys(tx)
+	exclude := make(map[solana.PublicKey]bool)
+	exclude[wallet] = true
+	for _, p := range involvedPrograms(tx, keys) {
+		exclude[p] = true
+	}
+	// Token accounts show up as SOL-balance holders too; report their owners instead
+	for _, balances := range [][]rpc.TokenBalance{tx.Meta.PreTokenBalances, tx.Meta.PostTokenBalances} {
+		for _, b := range balances {
+			if int(b.AccountIndex) < len(keys) {
+				exclude[keys[b.AccountIndex]] = true
+			}
+		}
+	}
+	// The fee payer pays the fee; only count it when it moved more than that
+	feePayerMovedOnlyFee := len(keys) > 0 && len(tx.Meta.PreBalances) > 0 && len(tx.Meta.PostBalances) > 0 &&
+		int64(tx.Meta.PreBalances[0])-int64(tx.Meta.PostBalances[0]) == int64(tx.Meta.Fee)
+
+	seen := make(map[solana.PublicKey]bool)
+	var out []solana.PublicKey
+	add := func(k solana.PublicKey) {
+		if exclude[k] || seen[k] {
+			return
+		}
+		seen[k] = true
+		out = append(out, k)
+	}
+
+	for i, k := range keys {
+		if i >= len(tx.Meta.PreBalances) || i >= len(tx.Meta.PostBalances) {
+			break
+		}
+		if tx.Meta.PreBalances[i] == tx.Meta.PostBalances[i] {
+			continue
+		}
+		if i == 0 && feePayerMovedOnlyFee {
+			continue
+		}
+		add(k)
+	}
+
+	changed := tokenOwnersWithChanges(tx)
+	for _, owner := range changed {
+		add(owner)
+	}
+	return out
+}
+
+// tokenOwnersWithChanges returns owners of token accounts whose raw amount
+// differs between pre and post balances.
+func tokenOwnersWithChanges(tx decode.TransactionInfo) []solana.PublicKey {
+	type key struct {
+		index uint16
+		mint  solana.PublicKey
+	}
+	pre := make(map[key]string)
+	owners := make(map[key]solana.PublicKey)
+	for _, b := range tx.Meta.PreTokenBalances {
+		k := key{b.AccountIndex, b.Mint}
+		if b.UiTokenAmount != nil {
+			pre[k] = b.UiTokenAmount.Amount
+		}
+		if b.Owner != nil {
+			owners[k] = *b.Owner
+		}
+	}
+	var out []solana.PublicKey
+	inPost := make(map[key]bool)
+	for _, b := range tx.Meta.PostTokenBalances {
+		k := key{b.AccountIndex, b.Mint}
+		inPost[k] = true
+		amount := ""
+		if b.UiTokenAmount != nil {
+			amount = b.UiTokenAmount.Amount
+		}
+		if pre[k] == amount {
+			continue
+		}
+		if b.Owner != nil {
+			out = append(out, *b.Owner)
+		} else if o, ok := owners[k]; ok {
+			out = append(out, o)
+		}
+	}
+	// Accounts closed by the transaction only appear in the pre balances
+	for k, o := range owners {
+		if !inPost[k] {
+			out = append(out, o)
+		}
+	}
+	return out
+}
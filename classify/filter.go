@@ -0,0 +1,365 @@
+package classify
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+)
+
+// TransactionFilter selects transactions from an AccountTransactions set. All
+// populated criteria must match (AND); list criteria match if any entry does.
+// The zero value matches everything.
+type TransactionFilter struct {
+	Status         string // "success" or "failed"
+	After, Before  *time.Time
+	MinSlot        uint64
+	MaxSlot        uint64
+	Programs       []solana.PublicKey
+	Mints          []solana.PublicKey
+	Counterparties []solana.PublicKey
+	MinSOL         float64 // absolute SOL change of the wallet
+	MinToken       float64 // absolute change of any single mint held by the wallet
+	MinFee         *uint64
+	MaxFee         *uint64
+	Types          []TransactionType
+}
+
+// ParseFilter parses a filter expression: whitespace-separated terms of the
+// form `field:value` or `field<op>value` with op one of >=, <=, >, <, =.
+// List fields accept comma-separated values. Supported fields:
+//
+//	status:success|failed
+//	time>=2025-01-01  time<2025-02-01T00:00:00Z  time>=24h (relative to now)
+//	slot>=N  slot<=N
+//	program:<addr>[,<addr>]  mint:<addr>  counterparty:<addr>
+//	sol>=1.5  token>=100
+//	fee>=5000  fee<=10000 (lamports)
+//	type:swap,sol_transfer
+//
+// Strict inequalities on numeric fields are treated as inclusive bounds moved
+// by one unit for slot and fee, and as inclusive for amounts and time.
+// Relative times count back from now. Addresses may also be labels that
+// names resolves, e.g. program:jupiter-v6; names may be nil.
+func ParseFilter(expr string, now time.Time, names AddressResolver) (*TransactionFilter, error) {
+	f := &TransactionFilter{}
+	for _, term := range strings.Fields(expr) {
+		field, op, value, err := splitFilterTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.apply(field, op, value, now, names); err != nil {
+			return nil, fmt.Errorf("filter term %q: %w", term, err)
+		}
+	}
+	return f, nil
+}
+
+func splitFilterTerm(term string) (field, op, value string, err error) {
+	for _, candidate := range []string{">=", "<=", ":", "=", ">", "<"} {
+		if i := strings.Index(term, candidate); i > 0 {
+			// Prefer the earliest operator so values containing ':' (times) survive
+			if j := strings.IndexAny(term, ":=<>"); j < i {
+				continue
+			}
+			field, op, value = strings.ToLower(term[:i]), candidate, term[i+len(candidate):]
+			if op == "=" {
+				op = ":"
+			}
+			if value == "" {
+				return "", "", "", fmt.Errorf("filter term %q has no value", term)
+			}
+			return field, op, value, nil
+		}
+	}
+	return "", "", "", fmt.Errorf("filter term %q is not of the form field:value or field>=value", term)
+}
+
+func (f *TransactionFilter) apply(field, op, value string, now time.Time, names AddressResolver) error {
+	switch field {
+	case "status":
+		if op != ":" {
+			return fmt.Errorf("status only supports ':'")
+		}
+		switch strings.ToLower(value) {
+		case "success", "ok":
+			f.Status = "success"
+		case "failed", "fail", "error":
+			f.Status = "failed"
+		default:
+			return fmt.Errorf("unknown status %q (want success or failed)", value)
+		}
+	case "time":
+		t, err := parseFilterTime(value, now)
+		if err != nil {
+			return err
+		}
+		switch op {
+		case ">=", ">":
+			f.After = &t
+		case "<=", "<":
+			f.Before = &t
+		default:
+			return fmt.Errorf("time needs a comparison (>=, <=, >, <)")
+		}
+	case "slot":
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid slot %q", value)
+		}
+		switch op {
+		case ">=":
+			f.MinSlot = n
+		case ">":
+			f.MinSlot = n + 1
+		case "<=":
+			f.MaxSlot = n
+		case "<":
+			if n == 0 {
+				return fmt.Errorf("slot<0 never matches")
+			}
+			f.MaxSlot = n - 1
+		default:
+			f.MinSlot, f.MaxSlot = n, n
+		}
+		// MaxSlot 0 means no upper bound, and the genesis slot holds no
+		// transactions anyway
+		if op != ">=" && op != ">" && f.MaxSlot == 0 {
+			return fmt.Errorf("slot upper bound must be at least 1")
+		}
+	case "program", "mint", "counterparty":
+		if op != ":" {
+			return fmt.Errorf("%s only supports ':'", field)
+		}
+		keys, err := parseFilterKeys(value, names)
+		if err != nil {
+			return err
+		}
+		switch field {
+		case "program":
+			f.Programs = append(f.Programs, keys...)
+		case "mint":
+			f.Mints = append(f.Mints, keys...)
+		default:
+			f.Counterparties = append(f.Counterparties, keys...)
+		}
+	case "sol", "token":
+		if op != ">=" && op != ">" {
+			return fmt.Errorf("%s only supports a minimum (>=)", field)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || v < 0 {
+			return fmt.Errorf("invalid amount %q", value)
+		}
+		if field == "sol" {
+			f.MinSOL = v
+		} else {
+			f.MinToken = v
+		}
+	case "fee":
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid fee %q (lamports)", value)
+		}
+		switch op {
+		case ">=":
+			f.MinFee = &n
+		case ">":
+			n++
+			f.MinFee = &n
+		case "<=":
+			f.MaxFee = &n
+		case "<":
+			if n == 0 {
+				return fmt.Errorf("fee<0 never matches")
+			}
+			n--
+			f.MaxFee = &n
+		default:
+			f.MinFee, f.MaxFee = &n, &n
+		}
+	case "type":
+		if op != ":" {
+			return fmt.Errorf("type only supports ':'")
+		}
+		for _, v := range strings.Split(value, ",") {
+			t, ok := parseTransactionType(v)
+			if !ok {
+				return fmt.Errorf("unknown type %q (want one of %v)", v, TransactionTypes)
+			}
+			f.Types = append(f.Types, t)
+		}
+	default:
+		return fmt.Errorf("unknown field %q", field)
+	}
+	return nil
+}
+
+func parseTransactionType(v string) (TransactionType, bool) {
+	for _, t := range TransactionTypes {
+		if string(t) == strings.ToLower(v) {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// AddressResolver turns a label used in a filter into an address.
+type AddressResolver interface {
+	ResolveAddress(name string) (solana.PublicKey, error)
+}
+
+func parseFilterKeys(value string, names AddressResolver) ([]solana.PublicKey, error) {
+	var keys []solana.PublicKey
+	for _, v := range strings.Split(value, ",") {
+		k, err := solana.PublicKeyFromBase58(v)
+		if err != nil {
+			if names == nil {
+				return nil, fmt.Errorf("invalid address %q", v)
+			}
+			if k, err = names.ResolveAddress(v); err != nil {
+				return nil, fmt.Errorf("invalid address or %w", err)
+			}
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// parseFilterTime accepts RFC3339, a plain date, or a Go duration (plus "Nd"
+// for days) meaning that long ago.
+func parseFilterTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil {
+			return now.Add(-time.Duration(days) * 24 * time.Hour), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want RFC3339, YYYY-MM-DD or a duration like 24h/7d)", value)
+}
+
+// IsEmpty reports whether the filter has no criteria.
+func (f *TransactionFilter) IsEmpty() bool {
+	return f == nil || (f.Status == "" && f.After == nil && f.Before == nil && f.MinSlot == 0 && f.MaxSlot == 0 &&
+		len(f.Programs) == 0 && len(f.Mints) == 0 && len(f.Counterparties) == 0 && f.MinSOL == 0 &&
+		f.MinToken == 0 && f.MinFee == nil && f.MaxFee == nil && len(f.Types) == 0)
+}
+
+// Match reports whether tx satisfies every criterion, evaluated from the
+// point of view of wallet.
+func (f *TransactionFilter) Match(tx decode.TransactionInfo, wallet solana.PublicKey) bool {
+	if f.IsEmpty() {
+		return true
+	}
+
+	failed := tx.Meta != nil && tx.Meta.Err != nil
+	if f.Status == "failed" && !failed || f.Status == "success" && failed {
+		return false
+	}
+
+	if f.After != nil || f.Before != nil {
+		if tx.BlockTime == nil {
+			return false
+		}
+		t := time.Unix(*tx.BlockTime, 0)
+		if f.After != nil && t.Before(*f.After) || f.Before != nil && t.After(*f.Before) {
+			return false
+		}
+	}
+
+	if f.MinSlot > 0 && tx.Slot < f.MinSlot || f.MaxSlot > 0 && tx.Slot > f.MaxSlot {
+		return false
+	}
+
+	if f.MinFee != nil || f.MaxFee != nil {
+		if tx.Meta == nil {
+			return false
+		}
+		if f.MinFee != nil && tx.Meta.Fee < *f.MinFee || f.MaxFee != nil && tx.Meta.Fee > *f.MaxFee {
+			return false
+		}
+	}
+
+	// Remaining criteria need the wallet-relative view of the transaction
+	c := ClassifyTransaction(tx, wallet)
+
+	if len(f.Programs) > 0 && !anyKeyIn(f.Programs, c.Programs) {
+		return false
+	}
+	if len(f.Mints) > 0 && !anyKeyIn(f.Mints, c.Mints) {
+		return false
+	}
+	if len(f.Counterparties) > 0 && !anyKeyIn(f.Counterparties, c.Counterparties) {
+		return false
+	}
+	if f.MinSOL > 0 && math.Abs(float64(c.SOLChange))/1e9 < f.MinSOL {
+		return false
+	}
+	if f.MinToken > 0 {
+		ok := false
+		for _, tc := range c.TokenChanges {
+			if len(f.Mints) > 0 && !anyKeyIn(f.Mints, []solana.PublicKey{tc.Mint}) {
+				continue
+			}
+			if math.Abs(tc.Delta) >= f.MinToken {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(f.Types) > 0 {
+		ok := false
+		for _, t := range f.Types {
+			if c.Type == t {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns a copy of accountTxs holding only the matching transactions.
+func (f *TransactionFilter) Apply(accountTxs *fetch.AccountTransactions) *fetch.AccountTransactions {
+	out := &fetch.AccountTransactions{
+		Account:     accountTxs.Account,
+		LastFetched: accountTxs.LastFetched,
+	}
+	for _, tx := range accountTxs.Transactions {
+		if f.Match(tx, accountTxs.Account) {
+			out.Transactions = append(out.Transactions, tx)
+		}
+	}
+	return out
+}
+
+func anyKeyIn(want, have []solana.PublicKey) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if w.Equals(h) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package classify
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/decode"
+)
+
+var (
+	alice = solana.PublicKey{1}
+	bob   = solana.PublicKey{2}
+	now   = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// labels resolves filter labels from a fixed table.
+type labels map[string]solana.PublicKey
+
+func (l labels) ResolveAddress(name string) (solana.PublicKey, error) {
+	if k, ok := l[name]; ok {
+		return k, nil
+	}
+	return solana.PublicKey{}, errors.New("unknown label " + name)
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    *TransactionFilter
+		wantErr bool
+	}{
+		{expr: "", want: &TransactionFilter{}},
+		{expr: "status:ok", want: &TransactionFilter{Status: "success"}},
+		{expr: "status=failed", want: &TransactionFilter{Status: "failed"}},
+		{expr: "status>=failed", wantErr: true},
+		{expr: "status:pending", wantErr: true},
+		{expr: "time>=2025-01-01T00:00:00Z", want: &TransactionFilter{After: ptr(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))}},
+		{expr: "time<2025-02-01", want: &TransactionFilter{Before: ptr(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))}},
+		{expr: "time>=7d", want: &TransactionFilter{After: ptr(now.Add(-7 * 24 * time.Hour))}},
+		{expr: "time:24h", wantErr: true},
+		{expr: "slot>=10 slot<=20", want: &TransactionFilter{MinSlot: 10, MaxSlot: 20}},
+		{expr: "slot>10 slot<20", want: &TransactionFilter{MinSlot: 11, MaxSlot: 19}},
+		{expr: "slot:15", want: &TransactionFilter{MinSlot: 15, MaxSlot: 15}},
+		{expr: "slot>=0", want: &TransactionFilter{}},
+		{expr: "slot<0", wantErr: true},
+		{expr: "slot<1", wantErr: true},
+		{expr: "slot<=0", wantErr: true},
+		{expr: "slot:0", wantErr: true},
+		{expr: "slot>=-1", wantErr: true},
+		{expr: "program:" + bob.String(), want: &TransactionFilter{Programs: []solana.PublicKey{bob}}},
+		{expr: "counterparty:bob,alice", want: &TransactionFilter{Counterparties: []solana.PublicKey{bob, alice}}},
+		{expr: "mint:nobody", wantErr: true},
+		{expr: "sol>=1.5 token>2", want: &TransactionFilter{MinSOL: 1.5, MinToken: 2}},
+		{expr: "sol<=1", wantErr: true},
+		{expr: "sol>=-1", wantErr: true},
+		{expr: "fee>5000 fee<=10000", want: &TransactionFilter{MinFee: ptr[uint64](5001), MaxFee: ptr[uint64](10000)}},
+		{expr: "fee:5000", want: &TransactionFilter{MinFee: ptr[uint64](5000), MaxFee: ptr[uint64](5000)}},
+		{expr: "fee<0", wantErr: true},
+		{expr: "type:swap,SOL_TRANSFER", want: &TransactionFilter{Types: []TransactionType{TxTypeSwap, TxTypeSOLTransfer}}},
+		{expr: "type:airdrop", wantErr: true},
+		{expr: "color:red", wantErr: true},
+		{expr: "status", wantErr: true},
+		{expr: "status:", wantErr: true},
+	}
+	names := labels{"alice": alice, "bob": bob}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseFilter(tt.expr, now, names)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseFilter(%q) = %+v, want an error", tt.expr, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFilter(%q) = %+v, want %+v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+// transfer is alice sending bob 2 SOL at slot 100.
+func transfer(t *testing.T, failed bool) decode.TransactionInfo {
+	t.Helper()
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{system.NewTransferInstruction(2_000_000_000, alice, bob).Build()},
+		solana.Hash{},
+		solana.TransactionPayer(alice),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := &rpc.TransactionMeta{
+		Fee:          5000,
+		PreBalances:  []uint64{10_000_000_000, 0, 1},
+		PostBalances: []uint64{7_999_995_000, 2_000_000_000, 1},
+	}
+	if failed {
+		meta.Err = map[string]any{"InstructionError": []any{0, "Custom"}}
+		meta.PostBalances = []uint64{9_999_995_000, 0, 1}
+	}
+	blockTime := now.Add(-time.Hour).Unix()
+	return decode.TransactionInfo{Signature: "sig", Slot: 100, BlockTime: &blockTime, Meta: meta, Transaction: tx}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		expr   string
+		failed bool
+		want   bool
+	}{
+		{expr: "", want: true},
+		{expr: "status:success", want: true},
+		{expr: "status:failed", want: false},
+		{expr: "status:failed", failed: true, want: true},
+		{expr: "time>=2h", want: true},
+		{expr: "time>=30m", want: false},
+		{expr: "time<=30m", want: true},
+		{expr: "slot>=100 slot<=100", want: true},
+		{expr: "slot>100", want: false},
+		{expr: "slot<100", want: false},
+		{expr: "slot<101", want: true},
+		{expr: "slot:1", want: false},
+		{expr: "fee:5000", want: true},
+		{expr: "fee<5000", want: false},
+		{expr: "program:" + solana.SystemProgramID.String(), want: true},
+		{expr: "program:" + solana.TokenProgramID.String(), want: false},
+		{expr: "counterparty:bob", want: true},
+		{expr: "counterparty:alice", want: false},
+		{expr: "sol>=2", want: true},
+		{expr: "sol>=2.1", want: false},
+		{expr: "sol>=1", failed: true, want: false},
+		{expr: "token>=1", want: false},
+		{expr: "type:sol_transfer", want: true},
+		{expr: "type:swap,token_transfer", want: false},
+		{expr: "type:swap,sol_transfer status:success counterparty:bob", want: true},
+	}
+	names := labels{"alice": alice, "bob": bob}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expr, now, names)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", tt.expr, err)
+		}
+		if got := f.Match(transfer(t, tt.failed), alice); got != tt.want {
+			t.Errorf("%q (failed %v): Match = %v, want %v", tt.expr, tt.failed, got, tt.want)
+		}
+	}
+}
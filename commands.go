@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// command is a CLI subcommand. Running the binary without a subcommand keeps
+// the original monitor behaviour.
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, cfg *Config, args []string) error
+}
+
+// commands lists every subcommand in the order it is shown in usage output.
+var commands = []command{
+	{name: "history", summary: "fetch, filter and save an account's (or watchlist's) transaction history", run: runHistory},
+	{name: "portfolio", summary: "show token holdings for a wallet or every watchlist wallet", run: runPortfolio},
+	{name: "watch", summary: "poll a wallet or every watchlist wallet for new transactions", run: runWatch},
+	{name: "report", summary: "write a self-contained HTML report of a wallet's portfolio and history", run: runReport},
+	{name: "tui", summary: "browse a wallet's history, details, portfolio and live activity interactively", run: runTUI},
+	{name: "tx", summary: "look up one or more transactions by signature", run: runTx},
+	{name: "decode", summary: "decode a raw base64/base58 transaction offline", run: runDecode},
+	{name: "simulate", summary: "simulate a transaction against an RPC node", run: runSimulate},
+	{name: "labels", summary: "list the address book of well-known, labeled and watched accounts", run: runLabels},
+	{name: "serve", summary: "serve transactions, portfolios and tokens as a JSON HTTP API", run: runServe},
+	{name: "mock", summary: "run a local mock Solana RPC node with scripted wallets and activity", run: runMock},
+}
+
+func lookupCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [global flags] [command] [flags]\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Without a command, monitors the configured wallet (history, portfolio, live listener).")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nGlobal flags:")
+	global := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	global.SetOutput(os.Stderr)
+	addConfigFlags(global)
+	global.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for command flags.\n", os.Args[0])
+}
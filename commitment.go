@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+)
+
+const (
+	// statusPollInterval is how often pending transactions are checked.
+	statusPollInterval = 2 * time.Second
+	// finalizeTimeout is how long a transaction may stay pending. Finalization
+	// normally takes well under a minute; a blockhash expires after ~150 slots.
+	finalizeTimeout = 2 * time.Minute
+	// missingPolls is how many consecutive polls a previously seen signature
+	// may be unknown before it is considered dropped with its fork.
+	missingPolls = 3
+	// maxStatusBatch is getSignatureStatuses' limit per request.
+	maxStatusBatch = 256
+)
+
+// TxStatus is how far a transaction has progressed on the cluster.
+type TxStatus string
+
+const (
+	StatusProcessed TxStatus = "processed"
+	StatusConfirmed TxStatus = "confirmed"
+	StatusFinalized TxStatus = "finalized"
+	// StatusDropped retracts a transaction that never finalized.
+	StatusDropped TxStatus = "dropped"
+)
+
+// Rank orders statuses from processed to finalized; dropped is unranked.
+func (s TxStatus) Rank() int {
+	switch s {
+	case StatusProcessed:
+		return 1
+	case StatusConfirmed:
+		return 2
+	case StatusFinalized:
+		return 3
+	}
+	return 0
+}
+
+// txStatusOf maps an RPC confirmation status, defaulting to confirmed since
+// that is what getSignaturesForAddress lists.
+func txStatusOf(s rpc.ConfirmationStatusType) TxStatus {
+	switch s {
+	case rpc.ConfirmationStatusProcessed:
+		return StatusProcessed
+	case rpc.ConfirmationStatusFinalized:
+		return StatusFinalized
+	}
+	return StatusConfirmed
+}
+
+// StatusChange reports that a watched transaction moved to a new status. From
+// is empty the first time the transaction is seen.
+type StatusChange struct {
+	Wallet    WatchedWallet
+	Signature solana.Signature
+	Slot      uint64
+	From      TxStatus
+	To        TxStatus
+	Failed    bool
+	Reason    string
+	Time      time.Time
+}
+
+// trackedKey is one wallet's sighting of a transaction. A transaction that
+// touches several watched wallets is tracked, and reported, for each.
+type trackedKey struct {
+	wallet solana.PublicKey
+	sig    solana.Signature
+}
+
+func (k trackedKey) String() string {
+	return k.wallet.String() + "/" + k.sig.String()
+}
+
+type trackedTx struct {
+	wallet    WatchedWallet
+	slot      uint64
+	status    TxStatus
+	failed    bool
+	firstSeen time.Time
+	// known is set once getSignatureStatuses has returned the signature
+	known   bool
+	missing int
+}
+
+// StatusTracker follows transactions from their first sighting until they
+// finalize, polling getSignatureStatuses and reporting every step to onChange.
+// Transactions that never finalize, or vanish after being seen (their fork
+// was abandoned), are reported as dropped.
+type StatusTracker struct {
+	client   *rpc.Client
+	onChange func(ctx context.Context, ch StatusChange)
+
+	mu      sync.Mutex
+	pending map[trackedKey]*trackedTx
+	// completed holds recently finalized and dropped sightings, so one seen
+	// again by another path or a handler retry is not reported twice
+	completed *recentSet
+	now       func() time.Time
+}
+
+// NewStatusTracker creates a tracker; call Run to start polling.
+func NewStatusTracker(client *rpc.Client, onChange func(ctx context.Context, ch StatusChange)) *StatusTracker {
+	return &StatusTracker{
+		client:    client,
+		onChange:  onChange,
+		pending:   make(map[trackedKey]*trackedTx),
+		completed: newRecentSet(defaultDedupeCapacity),
+		now:       time.Now,
+	}
+}
+
+// Observe records that w's sig was seen at status and reports whether the
+// tracker did not know it yet. Lower statuses than the one already recorded
+// are ignored, as are sightings that recently finalized or were dropped.
+func (t *StatusTracker) Observe(ctx context.Context, w WatchedWallet, sig solana.Signature, slot uint64, status TxStatus, failed bool) bool {
+	key := trackedKey{wallet: w.Account, sig: sig}
+	t.mu.Lock()
+	if t.completed.Has(key.String()) {
+		t.mu.Unlock()
+		return false
+	}
+	tx, ok := t.pending[key]
+	if ok && status.Rank() <= tx.status.Rank() {
+		t.mu.Unlock()
+		return false
+	}
+	ch := StatusChange{Wallet: w, Signature: sig, Slot: slot, To: status, Failed: failed, Time: t.now()}
+	if ok {
+		ch.From = tx.status
+		tx.status, tx.slot = status, slot
+	} else {
+		tx = &trackedTx{wallet: w, slot: slot, status: status, failed: failed, firstSeen: ch.Time}
+		t.pending[key] = tx
+	}
+	if status == StatusFinalized {
+		t.complete(key)
+	}
+	t.mu.Unlock()
+
+	t.onChange(ctx, ch)
+	return !ok
+}
+
+// Pending returns how many transactions are waiting to finalize.
+func (t *StatusTracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending)
+}
+
+// Run polls pending transactions until ctx ends.
+func (t *StatusTracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.poll(ctx); err != nil {
+				slog.WarnContext(ctx, "Status poll failed", "err", err)
+			}
+		}
+	}
+}
+
+func (t *StatusTracker) poll(ctx context.Context) error {
+	// Look each signature up once, however many wallets it touches
+	t.mu.Lock()
+	keys := make(map[solana.Signature][]trackedKey, len(t.pending))
+	sigs := make([]solana.Signature, 0, len(t.pending))
+	for key := range t.pending {
+		if _, ok := keys[key.sig]; !ok {
+			sigs = append(sigs, key.sig)
+		}
+		keys[key.sig] = append(keys[key.sig], key)
+	}
+	t.mu.Unlock()
+
+	for start := 0; start < len(sigs); start += maxStatusBatch {
+		batch := sigs[start:min(start+maxStatusBatch, len(sigs))]
+		res, err := t.statuses(ctx, batch)
+		if err != nil {
+			return fmt.Errorf("getSignatureStatuses: %w", err)
+		}
+		for i, sig := range batch {
+			for _, key := range keys[sig] {
+				if ch, ok := t.update(key, res[i]); ok {
+					t.onChange(ctx, ch)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// statuses looks batch up in the node's recent status cache, then looks the
+// signatures it does not know up again in the full transaction history. A
+// transaction that took longer than the cache to finalize, or was resumed
+// from a checkpoint, is only found there and would otherwise be dropped.
+func (t *StatusTracker) statuses(ctx context.Context, batch []solana.Signature) ([]*rpc.SignatureStatusesResult, error) {
+	res, err := t.client.GetSignatureStatuses(ctx, false, batch...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*rpc.SignatureStatusesResult, len(batch))
+	copy(out, res.Value)
+
+	var missing []solana.Signature
+	var at []int
+	for i, st := range out {
+		if st == nil {
+			missing = append(missing, batch[i])
+			at = append(at, i)
+		}
+	}
+	if len(missing) == 0 {
+		return out, nil
+	}
+	res, err = t.client.GetSignatureStatuses(ctx, true, missing...)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range at {
+		if j < len(res.Value) {
+			out[i] = res.Value[j]
+		}
+	}
+	return out, nil
+}
+
+// update applies one status result and returns the change it causes, if any.
+func (t *StatusTracker) update(key trackedKey, st *rpc.SignatureStatusesResult) (StatusChange, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tx, ok := t.pending[key]
+	if !ok {
+		return StatusChange{}, false
+	}
+	now := t.now()
+	ch := StatusChange{Wallet: tx.wallet, Signature: key.sig, Slot: tx.slot, From: tx.status, Failed: tx.failed, Time: now}
+
+	if st == nil {
+		tx.missing++
+		switch {
+		case tx.known && tx.missing >= missingPolls:
+			ch.Reason = "no longer known to the cluster; its fork was abandoned"
+		case now.Sub(tx.firstSeen) > finalizeTimeout:
+			ch.Reason = fmt.Sprintf("not found within %s", finalizeTimeout)
+		default:
+			return StatusChange{}, false
+		}
+		ch.To = StatusDropped
+		t.complete(key)
+		return ch, true
+	}
+
+	tx.known, tx.missing = true, 0
+	status := txStatusOf(st.ConfirmationStatus)
+	if st.Slot != 0 {
+		tx.slot, ch.Slot = st.Slot, st.Slot
+	}
+	tx.failed = tx.failed || st.Err != nil
+	ch.Failed = tx.failed
+	if status.Rank() <= tx.status.Rank() {
+		if now.Sub(tx.firstSeen) <= finalizeTimeout {
+			return StatusChange{}, false
+		}
+		ch.To = StatusDropped
+		ch.Reason = fmt.Sprintf("still %s after %s", tx.status, finalizeTimeout)
+		t.complete(key)
+		return ch, true
+	}
+	ch.To = status
+	tx.status = status
+	if status == StatusFinalized {
+		t.complete(key)
+	}
+	return ch, true
+}
+
+// complete stops tracking key once it finalized or was dropped. t.mu must be
+// held.
+func (t *StatusTracker) complete(key trackedKey) {
+	delete(t.pending, key)
+	t.completed.Add(key.String())
+}
+
+// subscribeProcessed feeds the tracker with transactions mentioning the
+// wallet at processed commitment over a WebSocket logsSubscribe, reconnecting
+// with backoff until ctx ends. getSignaturesForAddress cannot list processed
+// transactions, so this is the only way to see them before confirmation.
+func subscribeProcessed(ctx context.Context, wsURL string, w WatchedWallet, tracker *StatusTracker) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		err := streamProcessed(ctx, wsURL, w, tracker)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.WarnContext(ctx, "Processed stream failed, reconnecting", "backoff", backoff, "err", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+func streamProcessed(ctx context.Context, wsURL string, w WatchedWallet, tracker *StatusTracker) error {
+	client, err := ws.Connect(ctx, wsURL)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer client.Close()
+
+	sub, err := client.LogsSubscribeMentions(w.Account, rpc.CommitmentProcessed)
+	if err != nil {
+		return fmt.Errorf("logsSubscribe: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		res, err := sub.Recv(ctx)
+		if err != nil {
+			return err
+		}
+		tracker.Observe(ctx, w, res.Value.Signature, res.Context.Slot, StatusProcessed, res.Value.Err != nil)
+	}
+}
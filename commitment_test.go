@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// statusNode answers getSignatureStatuses with status for every signature.
+func statusNode(t *testing.T, status rpc.ConfirmationStatusType) *rpc.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		msgs, _, err := decodeJSONRPC(body)
+		if err != nil || len(msgs) != 1 || msgs[0].Method != "getSignatureStatuses" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var params []json.RawMessage
+		var sigs []string
+		if json.Unmarshal(msgs[0].Params, &params) != nil || len(params) == 0 || json.Unmarshal(params[0], &sigs) != nil {
+			http.Error(w, "bad params", http.StatusBadRequest)
+			return
+		}
+		value := make([]map[string]any, len(sigs))
+		for i := range sigs {
+			value[i] = map[string]any{"slot": 100, "confirmations": nil, "err": nil, "confirmationStatus": status}
+		}
+		result, _ := json.Marshal(map[string]any{"context": map[string]any{"slot": 100}, "value": value})
+		json.NewEncoder(w).Encode(jsonrpcMessage{JSONRPC: "2.0", ID: msgs[0].ID, Result: result})
+	}))
+	t.Cleanup(srv.Close)
+	return rpc.New(srv.URL)
+}
+
+// changeLog collects a tracker's status changes.
+type changeLog struct {
+	mu      sync.Mutex
+	changes []StatusChange
+}
+
+func (l *changeLog) add(_ context.Context, ch StatusChange) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes = append(l.changes, ch)
+}
+
+func (l *changeLog) steps() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []string
+	for _, ch := range l.changes {
+		out = append(out, ch.Wallet.Label+": "+string(ch.From)+" -> "+string(ch.To))
+	}
+	return out
+}
+
+func testWallet(label string, seed byte) WatchedWallet {
+	account := solana.PublicKey{seed}
+	return WatchedWallet{Address: account.String(), Label: label, Account: account}
+}
+
+func TestStatusTrackerReportsFinalizedSignatureOnce(t *testing.T) {
+	ctx := context.Background()
+	var log changeLog
+	tracker := NewStatusTracker(statusNode(t, rpc.ConfirmationStatusFinalized), log.add)
+	w := testWallet("hot", 1)
+	sig := solana.Signature{7}
+
+	// The processed WebSocket stream sees it first, then the poll finalizes it
+	if !tracker.Observe(ctx, w, sig, 90, StatusProcessed, false) {
+		t.Fatal("first sighting was not reported as new")
+	}
+	if err := tracker.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// The confirmed listener catches up afterwards, and its handler is retried
+	for range 2 {
+		if tracker.Observe(ctx, w, sig, 100, StatusConfirmed, false) {
+			t.Error("finalized signature was reported as new again")
+		}
+	}
+
+	want := []string{"hot:  -> processed", "hot: processed -> finalized"}
+	if got := log.steps(); !slices.Equal(got, want) {
+		t.Errorf("status changes = %q, want %q", got, want)
+	}
+	if n := tracker.Pending(); n != 0 {
+		t.Errorf("Pending() = %d, want 0", n)
+	}
+}
+
+func TestStatusTrackerReportsSharedSignatureToEachWallet(t *testing.T) {
+	ctx := context.Background()
+	var log changeLog
+	tracker := NewStatusTracker(statusNode(t, rpc.ConfirmationStatusFinalized), log.add)
+	sig := solana.Signature{9}
+
+	// A transfer between two watched wallets shows up in both their feeds
+	for _, w := range []WatchedWallet{testWallet("hot", 1), testWallet("cold", 2)} {
+		if !tracker.Observe(ctx, w, sig, 90, StatusConfirmed, false) {
+			t.Fatalf("%s: first sighting was not reported as new", w.Label)
+		}
+	}
+	if n := tracker.Pending(); n != 2 {
+		t.Fatalf("Pending() = %d, want 2", n)
+	}
+	if err := tracker.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got := log.steps()
+	slices.Sort(got)
+	want := []string{"cold:  -> confirmed", "cold: confirmed -> finalized", "hot:  -> confirmed", "hot: confirmed -> finalized"}
+	if !slices.Equal(got, want) {
+		t.Errorf("status changes = %q, want %q", got, want)
+	}
+	if n := tracker.Pending(); n != 0 {
+		t.Errorf("Pending() = %d, want 0", n)
+	}
+}
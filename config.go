@@ -0,0 +1,515 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+
+	"go-solana-tx-explorer/addressbook"
+	"go-solana-tx-explorer/render"
+	"go-solana-tx-explorer/rpc/client"
+)
+
+// configDirName is the directory under the user config dir searched for
+// config.{yaml,yml,json,toml} when no file is given explicitly.
+const configDirName = "solana-tx-explorer"
+
+// Settings are the values a config file (globally or per profile), the
+// environment or the command line can set. Empty fields inherit from the
+// layer below.
+type Settings struct {
+	RPCURL            string  `json:"rpc_url,omitempty" yaml:"rpc_url,omitempty" toml:"rpc_url,omitempty"`
+	WSURL             string  `json:"ws_url,omitempty" yaml:"ws_url,omitempty" toml:"ws_url,omitempty"`
+	Cluster           string  `json:"cluster,omitempty" yaml:"cluster,omitempty" toml:"cluster,omitempty"`
+	Wallet            string  `json:"wallet,omitempty" yaml:"wallet,omitempty" toml:"wallet,omitempty"`
+	RequestsPerSecond float64 `json:"requests_per_second,omitempty" yaml:"requests_per_second,omitempty" toml:"requests_per_second,omitempty"`
+	// ShutdownTimeout is a Go duration such as "15s".
+	ShutdownTimeout string `json:"shutdown_timeout,omitempty" yaml:"shutdown_timeout,omitempty" toml:"shutdown_timeout,omitempty"`
+	// LogLevel is debug, info, warn or error; LogFormat is text or json.
+	LogLevel  string `json:"log_level,omitempty" yaml:"log_level,omitempty" toml:"log_level,omitempty"`
+	LogFormat string `json:"log_format,omitempty" yaml:"log_format,omitempty" toml:"log_format,omitempty"`
+	// Output is pretty, markdown, json or csv; Theme styles pretty output.
+	Output string `json:"output,omitempty" yaml:"output,omitempty" toml:"output,omitempty"`
+	Theme  string `json:"theme,omitempty" yaml:"theme,omitempty" toml:"theme,omitempty"`
+	// Labels is the address book file naming accounts.
+	Labels string `json:"labels,omitempty" yaml:"labels,omitempty" toml:"labels,omitempty"`
+}
+
+// ConfigFile is the on-disk configuration. Top-level settings apply to every
+// profile; a profile overrides them field by field.
+type ConfigFile struct {
+	DefaultProfile string `json:"default_profile,omitempty" yaml:"default_profile,omitempty" toml:"default_profile,omitempty"`
+
+	Settings `yaml:",inline"`
+
+	Profiles map[string]Settings `json:"profiles,omitempty" yaml:"profiles,omitempty" toml:"profiles,omitempty"`
+}
+
+// Config is the resolved configuration handed to commands and services.
+type Config struct {
+	// Profile is the selected profile name, empty when none is in use.
+	Profile string
+	// File is the config file that was read, empty when none was found.
+	File string
+
+	// Cluster picks default endpoints, token registry sources and explorer
+	// links. Without an explicit setting it starts as mainnet and is replaced
+	// by the detected cluster once ResolveCluster has run.
+	Cluster         *client.Cluster
+	clusterExplicit bool
+
+	RPCURL            string
+	WSURL             string
+	Wallet            solana.PublicKey
+	RequestsPerSecond float64
+	// ShutdownTimeout bounds how long a signalled shutdown may take to flush
+	// checkpoints and notifications before the process exits anyway.
+	ShutdownTimeout time.Duration
+	// LogLevel and LogFormat configure the stderr logger.
+	LogLevel  slog.Level
+	LogFormat string
+	// Output selects how transactions and portfolios are printed.
+	Output render.Format
+	// Theme colors pretty output on a terminal.
+	Theme render.Theme
+	// Labels names well-known accounts, the user's labeled ones and the
+	// watched wallets with their token accounts.
+	Labels *addressbook.Book
+}
+
+// ConfigFlags are the global flags accepted before the command name. They are
+// the highest-precedence layer.
+type ConfigFlags struct {
+	file    *string
+	profile *string
+	cluster *string
+	rpc     *string
+	ws      *string
+	wallet  *string
+	rps     *float64
+	stop    *time.Duration
+	level   *string
+	verbose *bool
+	format  *string
+	output  *string
+	theme   *string
+	labels  *string
+	record  *string
+	replay  *string
+}
+
+func addConfigFlags(fs *flag.FlagSet) ConfigFlags {
+	return ConfigFlags{
+		file:    fs.String("config", "", "config file (.yaml, .json or .toml); default: $EXPLORER_CONFIG, ./explorer.*, then the user config dir"),
+		profile: fs.String("profile", "", "config profile to use (default: $EXPLORER_PROFILE or default_profile)"),
+		cluster: fs.String("cluster", "", "mainnet, devnet, testnet or localnet (overrides cluster / SOLANA_CLUSTER)"),
+		rpc:     fs.String("rpc", "", "RPC HTTP endpoint (overrides rpc_url / RPC_URL; default: the cluster's public endpoint)"),
+		ws:      fs.String("ws", "", "RPC WebSocket endpoint (overrides ws_url / WS_URL)"),
+		wallet:  fs.String("wallet", "", "default wallet address (overrides wallet / WALLET_ADDRESS)"),
+		rps:     fs.Float64("rps", 0, "RPC requests per second (overrides requests_per_second / RPC_RPS)"),
+		stop:    fs.Duration("shutdown-timeout", 0, "time allowed for a clean shutdown after SIGINT/SIGTERM (overrides shutdown_timeout / SHUTDOWN_TIMEOUT; default 10s)"),
+		level:   fs.String("log-level", "", "debug, info, warn or error (overrides log_level / LOG_LEVEL; default info)"),
+		verbose: fs.Bool("v", false, "verbose: log at debug level, including every RPC call"),
+		format:  fs.String("log-format", "", "text or json (overrides log_format / LOG_FORMAT; default text)"),
+		output:  fs.String("output", "", "pretty, markdown, json or csv (overrides output / OUTPUT_FORMAT; default pretty)"),
+		theme:   fs.String("theme", "", "default, dark, minimal or ascii (overrides theme / OUTPUT_THEME)"),
+		labels:  fs.String("labels", "", "address book file (.yaml, .json or .toml) naming accounts (overrides labels / LABELS_FILE; default: labels.* in the user config dir)"),
+		record:  fs.String("record", "", "record every JSON-RPC call and answer to this fixture file"),
+		replay:  fs.String("replay", "", "answer RPC calls from this fixture file instead of a node (offline)"),
+	}
+}
+
+// LoadConfig resolves the configuration once, layering the config file, the
+// environment (including .env) and the global flags, in increasing order of
+// precedence. Missing values are not an error here; commands ask for what
+// they need through the Require* accessors.
+func LoadConfig(flags ConfigFlags) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("load .env: %w", err)
+	}
+
+	path := firstNonEmpty(*flags.file, os.Getenv("EXPLORER_CONFIG"))
+	explicit := path != ""
+	if !explicit {
+		path = findConfigFile()
+	}
+
+	var file ConfigFile
+	if path != "" {
+		if err := decodeConfigFile(path, &file); err != nil {
+			if explicit || !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("config %s: %w", path, err)
+			}
+			path = ""
+		}
+	}
+
+	cfg := &Config{File: path}
+	cfg.Profile = firstNonEmpty(*flags.profile, os.Getenv("EXPLORER_PROFILE"), file.DefaultProfile)
+
+	layers := []Settings{file.Settings}
+	if cfg.Profile != "" {
+		profile, ok := file.Profiles[cfg.Profile]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q (available: %s)", cfg.Profile, profileNames(file.Profiles))
+		}
+		layers = append(layers, profile)
+	}
+
+	env, err := envSettings()
+	if err != nil {
+		return nil, err
+	}
+	top := Settings{
+		Cluster:           *flags.cluster,
+		RPCURL:            *flags.rpc,
+		WSURL:             *flags.ws,
+		Wallet:            *flags.wallet,
+		RequestsPerSecond: *flags.rps,
+		LogLevel:          *flags.level,
+		LogFormat:         *flags.format,
+		Output:            *flags.output,
+		Theme:             *flags.theme,
+		Labels:            *flags.labels,
+	}
+	if *flags.verbose {
+		top.LogLevel = "debug"
+	}
+	if *flags.stop != 0 {
+		top.ShutdownTimeout = flags.stop.String()
+	}
+	layers = append(layers, env, top)
+
+	var merged Settings
+	for _, l := range layers {
+		merged = merged.overlay(l)
+	}
+	if err := cfg.apply(merged); err != nil {
+		if cfg.Profile != "" {
+			return nil, fmt.Errorf("profile %q: %w", cfg.Profile, err)
+		}
+		return nil, err
+	}
+	if cfg.Labels, err = LoadAddressBook(merged.Labels); err != nil {
+		return nil, err
+	}
+	if !cfg.Wallet.IsZero() {
+		cfg.Labels.AddWallet(cfg.Wallet, "")
+	}
+	return cfg, nil
+}
+
+// overlay returns s with every non-empty field of top applied over it.
+func (s Settings) overlay(top Settings) Settings {
+	if top.Cluster != "" {
+		s.Cluster = top.Cluster
+	}
+	if top.RPCURL != "" {
+		s.RPCURL = top.RPCURL
+	}
+	if top.WSURL != "" {
+		s.WSURL = top.WSURL
+	}
+	if top.Wallet != "" {
+		s.Wallet = top.Wallet
+	}
+	if top.RequestsPerSecond != 0 {
+		s.RequestsPerSecond = top.RequestsPerSecond
+	}
+	if top.ShutdownTimeout != "" {
+		s.ShutdownTimeout = top.ShutdownTimeout
+	}
+	if top.LogLevel != "" {
+		s.LogLevel = top.LogLevel
+	}
+	if top.LogFormat != "" {
+		s.LogFormat = top.LogFormat
+	}
+	if top.Output != "" {
+		s.Output = top.Output
+	}
+	if top.Theme != "" {
+		s.Theme = top.Theme
+	}
+	if top.Labels != "" {
+		s.Labels = top.Labels
+	}
+	return s
+}
+
+func envSettings() (Settings, error) {
+	s := Settings{
+		Cluster: os.Getenv("SOLANA_CLUSTER"),
+		RPCURL:  os.Getenv("RPC_URL"),
+		WSURL:   os.Getenv("WS_URL"),
+		Wallet:  os.Getenv("WALLET_ADDRESS"),
+
+		ShutdownTimeout: os.Getenv("SHUTDOWN_TIMEOUT"),
+		LogLevel:        os.Getenv("LOG_LEVEL"),
+		LogFormat:       os.Getenv("LOG_FORMAT"),
+		Output:          os.Getenv("OUTPUT_FORMAT"),
+		Theme:           os.Getenv("OUTPUT_THEME"),
+		Labels:          os.Getenv("LABELS_FILE"),
+	}
+	if v := os.Getenv("RPC_RPS"); v != "" {
+		rps, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return Settings{}, fmt.Errorf("RPC_RPS: invalid number %q", v)
+		}
+		s.RequestsPerSecond = rps
+	}
+	return s, nil
+}
+
+// apply validates the merged settings and stores them in c. Endpoints not
+// set anywhere default to the cluster's public ones.
+func (c *Config) apply(s Settings) error {
+	var errs []error
+	c.Cluster = client.Mainnet
+	if s.Cluster != "" {
+		cluster, err := client.LookupCluster(s.Cluster)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cluster: %w", err))
+		} else {
+			c.Cluster, c.clusterExplicit = cluster, true
+		}
+	}
+	if s.RPCURL == "" {
+		s.RPCURL = c.Cluster.RPCURL
+	}
+	if s.WSURL == "" && s.RPCURL == c.Cluster.RPCURL {
+		s.WSURL = c.Cluster.WSURL
+	}
+	if s.RPCURL != "" {
+		if err := validateEndpoint(s.RPCURL, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("rpc_url: %w", err))
+		}
+	}
+	if s.WSURL != "" {
+		if err := validateEndpoint(s.WSURL, "ws", "wss"); err != nil {
+			errs = append(errs, fmt.Errorf("ws_url: %w", err))
+		}
+	}
+	if s.Wallet != "" {
+		account, err := solana.PublicKeyFromBase58(strings.TrimSpace(s.Wallet))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("wallet: invalid address %q", s.Wallet))
+		}
+		c.Wallet = account
+	}
+	if s.RequestsPerSecond < 0 {
+		errs = append(errs, errors.New("requests_per_second must not be negative"))
+	}
+	c.ShutdownTimeout = defaultShutdownTimeout
+	if s.ShutdownTimeout != "" {
+		d, err := time.ParseDuration(s.ShutdownTimeout)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("shutdown_timeout: invalid duration %q", s.ShutdownTimeout))
+		case d <= 0:
+			errs = append(errs, errors.New("shutdown_timeout must be positive"))
+		default:
+			c.ShutdownTimeout = d
+		}
+	}
+	c.LogLevel = slog.LevelInfo
+	if s.LogLevel != "" {
+		level, err := parseLogLevel(s.LogLevel)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("log_level: %w", err))
+		}
+		c.LogLevel = level
+	}
+	switch c.LogFormat = strings.ToLower(s.LogFormat); c.LogFormat {
+	case "":
+		c.LogFormat = logFormatText
+	case logFormatText, logFormatJSON:
+	default:
+		errs = append(errs, fmt.Errorf("log_format: unknown format %q (want text or json)", s.LogFormat))
+	}
+	output, err := render.ParseFormat(s.Output)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("output: %w", err))
+	}
+	c.Output = output
+	theme, err := render.LookupTheme(s.Theme)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("theme: %w", err))
+	}
+	c.Theme = theme
+	if len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, err := range errs {
+			msgs[i] = err.Error()
+		}
+		return errors.New(strings.Join(msgs, "; "))
+	}
+
+	c.RPCURL = s.RPCURL
+	c.WSURL = s.WSURL
+	c.RequestsPerSecond = s.RequestsPerSecond
+	return nil
+}
+
+func validateEndpoint(raw string, schemes ...string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	for _, s := range schemes {
+		if u.Scheme == s {
+			if u.Host == "" {
+				return fmt.Errorf("URL %q has no host", raw)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("URL %q must use %s", raw, strings.Join(schemes, " or "))
+}
+
+// RequireRPCURL returns the HTTP endpoint or an error explaining how to set one.
+func (c *Config) RequireRPCURL() (string, error) {
+	if c.RPCURL == "" {
+		return "", errors.New("no RPC endpoint configured: set rpc_url in the config file, RPC_URL, or -rpc")
+	}
+	return c.RPCURL, nil
+}
+
+// RequireWSURL returns the WebSocket endpoint, deriving it from the HTTP
+// endpoint (https → wss, http → ws) when none is configured.
+func (c *Config) RequireWSURL() (string, error) {
+	if c.WSURL != "" {
+		return c.WSURL, nil
+	}
+	if strings.HasPrefix(c.RPCURL, "https://") {
+		return "wss://" + strings.TrimPrefix(c.RPCURL, "https://"), nil
+	}
+	if strings.HasPrefix(c.RPCURL, "http://") {
+		return "ws://" + strings.TrimPrefix(c.RPCURL, "http://"), nil
+	}
+	return "", errors.New("no WebSocket endpoint configured: set ws_url or rpc_url in the config file, WS_URL/RPC_URL, or -ws/-rpc")
+}
+
+// RequireWallet returns the configured default wallet.
+func (c *Config) RequireWallet() (solana.PublicKey, error) {
+	if c.Wallet.IsZero() {
+		return solana.PublicKey{}, errors.New("no wallet configured: pass an address, set wallet in the config file, WALLET_ADDRESS, or -wallet")
+	}
+	return c.Wallet, nil
+}
+
+// NewClient returns an RPC client for the configured endpoint, rate limited
+// when requests_per_second is set.
+func (c *Config) NewClient() (*rpc.Client, error) {
+	rpcURL, err := c.RequireRPCURL()
+	if err != nil {
+		return nil, err
+	}
+	return NewRateLimitedClient(rpcURL, c.RequestsPerSecond), nil
+}
+
+// ResolveCluster checks which cluster client is connected to. When no cluster
+// was configured the detected one is adopted; otherwise a mismatch is logged
+// as a warning, since token names and explorer links would be wrong.
+// Detection is best effort: failures only log.
+func (c *Config) ResolveCluster(ctx context.Context, rpcClient *rpc.Client) {
+	detected, hash, err := client.DetectCluster(ctx, rpcClient)
+	if err != nil {
+		slog.WarnContext(ctx, "Could not detect cluster", "err", err)
+		return
+	}
+	if detected == nil {
+		// Unknown genesis: a local or private validator; link the explorer
+		// to the endpoint actually in use
+		detected = client.CustomCluster(c.RPCURL)
+	}
+
+	if !c.clusterExplicit {
+		c.Cluster = detected
+		return
+	}
+	if detected.Name != c.Cluster.Name {
+		slog.WarnContext(ctx, "Configured cluster does not match the endpoint; token names and explorer links may be wrong",
+			"cluster", c.Cluster.Name, "endpoint", client.EndpointHost(c.RPCURL), "genesis", hash, "detected", detected.Name)
+	}
+}
+
+// findConfigFile returns the first existing default config file, or "".
+func findConfigFile() string {
+	var candidates []string
+	for _, ext := range []string{".yaml", ".yml", ".json", ".toml"} {
+		candidates = append(candidates, "explorer"+ext)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		for _, ext := range []string{".yaml", ".yml", ".json", ".toml"} {
+			candidates = append(candidates, filepath.Join(dir, configDirName, "config"+ext))
+		}
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c
+		}
+	}
+	return ""
+}
+
+// decodeConfigFile reads a YAML, JSON or TOML file into v, picking the format
+// from the file extension. Watchlists use the same loader.
+func decodeConfigFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, v)
+	case ".json":
+		err = json.Unmarshal(data, v)
+	case ".toml":
+		err = toml.Unmarshal(data, v)
+	default:
+		return errors.New("unsupported extension (want .yaml, .yml, .json or .toml)")
+	}
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return nil
+}
+
+func profileNames(profiles map[string]Settings) string {
+	if len(profiles) == 0 {
+		return "none defined"
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"go-solana-tx-explorer/decode"
+)
+
+// readTransactionInput returns the raw transaction text from the positional
+// argument, the given file, or stdin (when neither is set or the arg is "-").
+func readTransactionInput(args []string, file string) (string, error) {
+	switch {
+	case file != "":
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", file, err)
+		}
+		return string(b), nil
+	case len(args) > 0 && args[0] != "-":
+		return args[0], nil
+	default:
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("read stdin: %w", err)
+		}
+		return string(b), nil
+	}
+}
+
+// runDecode implements `decode [flags] [tx]`.
+func runDecode(_ context.Context, cfg *Config, args []string) error {
+	fs := flag.NewFlagSet("decode", flag.ContinueOnError)
+	file := fs.String("file", "", "read the transaction from a file instead of an argument or stdin")
+	encoding := fs.String("encoding", "auto", "input encoding: auto, base64 or base58")
+	verify := fs.Bool("verify", false, "verify signatures locally")
+	full := fs.Bool("full", false, "show all accounts and instructions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	raw, err := readTransactionInput(fs.Args(), *file)
+	if err != nil {
+		return err
+	}
+	tx, err := decode.DecodeRawTransaction(raw, *encoding)
+	if err != nil {
+		return err
+	}
+
+	info := decode.TransactionInfo{Transaction: tx}
+	if len(tx.Signatures) > 0 && !tx.Signatures[0].IsZero() {
+		info.Signature = tx.Signatures[0].String()
+	}
+
+	formatter := newFormatter(cfg, *full, nil)
+	if err := formatter.FormatTransactionDetails(info, 0); err != nil {
+		return err
+	}
+
+	if *verify {
+		checks, err := decode.VerifyTransactionSignatures(tx)
+		if err != nil {
+			return err
+		}
+		return formatter.FormatSignatureChecks(checks)
+	}
+	return nil
+}
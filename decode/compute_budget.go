@@ -0,0 +1,262 @@
+package decode
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// ComputeBudgetProgramID is the native Compute Budget program. Its instructions
+// carry the CU limit and CU price a transaction asks for.
+var ComputeBudgetProgramID = solana.MustPublicKeyFromBase58("ComputeBudget111111111111111111111111111111")
+
+const (
+	// lamportsPerSignature is the base fee charged for every required signature.
+	lamportsPerSignature = 5000
+	// defaultInstructionCU is the CU budget granted per non-compute-budget
+	// instruction when no SetComputeUnitLimit instruction is present.
+	defaultInstructionCU = 200_000
+	// maxTransactionCU is the hard per-transaction CU cap.
+	maxTransactionCU = 1_400_000
+	// microLamportsPerLamport converts CU price units into lamports.
+	microLamportsPerLamport = 1_000_000
+)
+
+// Compute Budget instruction discriminators (first byte of instruction data).
+const (
+	cbRequestUnitsDeprecated         = 0
+	cbRequestHeapFrame               = 1
+	cbSetComputeUnitLimit            = 2
+	cbSetComputeUnitPrice            = 3
+	cbSetLoadedAccountsDataSizeLimit = 4
+)
+
+// ComputeBudget holds the decoded Compute Budget program instructions of a
+// single transaction. Nil fields mean the instruction was not present.
+type ComputeBudget struct {
+	UnitLimit             *uint32 `json:"unit_limit,omitempty"`
+	UnitPrice             *uint64 `json:"unit_price_micro_lamports,omitempty"`
+	HeapFrameBytes        *uint32 `json:"heap_frame_bytes,omitempty"`
+	LoadedAccountsDataMax *uint32 `json:"loaded_accounts_data_size_limit,omitempty"`
+	Instructions          int     `json:"instructions"`
+}
+
+// FeeBreakdown splits a transaction fee into its base and priority parts and
+// relates the requested compute budget to what was actually consumed.
+type FeeBreakdown struct {
+	TotalFee       uint64        `json:"total_fee"`
+	BaseFee        uint64        `json:"base_fee"`
+	PriorityFee    uint64        `json:"priority_fee"`
+	Signatures     int           `json:"signatures"`
+	Budget         ComputeBudget `json:"budget"`
+	RequestedCU    uint64        `json:"requested_cu"`
+	ConsumedCU     *uint64       `json:"consumed_cu,omitempty"`
+	UtilizationPct float64       `json:"utilization_pct"`
+	WastedCU       uint64        `json:"wasted_cu"`
+}
+
+// DecodeComputeBudget walks the top-level instructions of tx and decodes every
+// Compute Budget program instruction it finds. Malformed instruction data is
+// skipped rather than treated as an error.
+func DecodeComputeBudget(tx *solana.Transaction) ComputeBudget {
+	var cb ComputeBudget
+	if tx == nil {
+		return cb
+	}
+	keys := tx.Message.AccountKeys
+	for _, instr := range tx.Message.Instructions {
+		if int(instr.ProgramIDIndex) >= len(keys) || keys[instr.ProgramIDIndex] != ComputeBudgetProgramID {
+			continue
+		}
+		data := []byte(instr.Data)
+		if len(data) == 0 {
+			continue
+		}
+		cb.Instructions++
+		switch data[0] {
+		case cbRequestUnitsDeprecated:
+			// units: u32, additional_fee: u32 (fee is expressed in lamports)
+			if len(data) >= 9 {
+				units := binary.LittleEndian.Uint32(data[1:5])
+				fee := uint64(binary.LittleEndian.Uint32(data[5:9]))
+				cb.UnitLimit = &units
+				if units > 0 {
+					price := fee * microLamportsPerLamport / uint64(units)
+					cb.UnitPrice = &price
+				}
+			}
+		case cbRequestHeapFrame:
+			if len(data) >= 5 {
+				v := binary.LittleEndian.Uint32(data[1:5])
+				cb.HeapFrameBytes = &v
+			}
+		case cbSetComputeUnitLimit:
+			if len(data) >= 5 {
+				v := binary.LittleEndian.Uint32(data[1:5])
+				cb.UnitLimit = &v
+			}
+		case cbSetComputeUnitPrice:
+			if len(data) >= 9 {
+				v := binary.LittleEndian.Uint64(data[1:9])
+				cb.UnitPrice = &v
+			}
+		case cbSetLoadedAccountsDataSizeLimit:
+			if len(data) >= 5 {
+				v := binary.LittleEndian.Uint32(data[1:5])
+				cb.LoadedAccountsDataMax = &v
+			}
+		}
+	}
+	return cb
+}
+
+// AnalyzeFee computes the fee breakdown for a fetched transaction. It returns
+// nil when the transaction has no meta, since the fee is only known on-chain.
+func AnalyzeFee(tx TransactionInfo) *FeeBreakdown {
+	if tx.Meta == nil {
+		return nil
+	}
+	fb := &FeeBreakdown{TotalFee: tx.Meta.Fee}
+
+	if tx.Transaction != nil {
+		fb.Signatures = int(tx.Transaction.Message.Header.NumRequiredSignatures)
+		fb.Budget = DecodeComputeBudget(tx.Transaction)
+		fb.RequestedCU = requestedComputeUnits(tx.Transaction, fb.Budget)
+	}
+
+	fb.BaseFee = uint64(fb.Signatures) * lamportsPerSignature
+	if tx.Transaction == nil || fb.BaseFee > fb.TotalFee {
+		// Without a decoded message the signature count is unknown; attribute
+		// the whole fee to the base fee rather than guess a priority fee.
+		fb.BaseFee = fb.TotalFee
+	}
+	fb.PriorityFee = fb.TotalFee - fb.BaseFee
+
+	if tx.Meta.ComputeUnitsConsumed != nil {
+		consumed := *tx.Meta.ComputeUnitsConsumed
+		fb.ConsumedCU = &consumed
+		if fb.RequestedCU > 0 {
+			fb.UtilizationPct = float64(consumed) / float64(fb.RequestedCU) * 100
+			if fb.RequestedCU > consumed {
+				fb.WastedCU = fb.RequestedCU - consumed
+			}
+		}
+	}
+	return fb
+}
+
+// requestedComputeUnits returns the CU limit the runtime applied: the explicit
+// SetComputeUnitLimit value when present, otherwise the per-instruction default.
+func requestedComputeUnits(tx *solana.Transaction, cb ComputeBudget) uint64 {
+	if cb.UnitLimit != nil {
+		return min(uint64(*cb.UnitLimit), maxTransactionCU)
+	}
+	n := len(tx.Message.Instructions) - cb.Instructions
+	return min(uint64(n)*defaultInstructionCU, maxTransactionCU)
+}
+
+// EstimateFee returns the fee the runtime charges for tx before it lands: the
+// base fee per signature plus ceil(CU price × CU limit / 1e6) lamports.
+func EstimateFee(tx *solana.Transaction) uint64 {
+	cb := DecodeComputeBudget(tx)
+	fee := uint64(tx.Message.Header.NumRequiredSignatures) * lamportsPerSignature
+	if cb.UnitPrice != nil {
+		units := requestedComputeUnits(tx, cb)
+		fee += (*cb.UnitPrice*units + microLamportsPerLamport - 1) / microLamportsPerLamport
+	}
+	return fee
+}
+
+// FeeStats aggregates fee and compute usage across a set of transactions.
+type FeeStats struct {
+	Transactions      int     `json:"transactions"`
+	TotalFee          uint64  `json:"total_fee"`
+	TotalBaseFee      uint64  `json:"total_base_fee"`
+	TotalPriorityFee  uint64  `json:"total_priority_fee"`
+	WithPriorityFee   int     `json:"with_priority_fee"`
+	PriorityFeeP50    uint64  `json:"priority_fee_p50"`
+	PriorityFeeP75    uint64  `json:"priority_fee_p75"`
+	PriorityFeeP90    uint64  `json:"priority_fee_p90"`
+	PriorityFeeP99    uint64  `json:"priority_fee_p99"`
+	PriorityFeeMax    uint64  `json:"priority_fee_max"`
+	TotalRequestedCU  uint64  `json:"total_requested_cu"`
+	TotalConsumedCU   uint64  `json:"total_consumed_cu"`
+	TotalWastedCU     uint64  `json:"total_wasted_cu"`
+	AvgUtilizationPct float64 `json:"avg_utilization_pct"`
+	UnitPriceP50      uint64  `json:"unit_price_p50_micro_lamports"`
+	UnitPriceP90      uint64  `json:"unit_price_p90_micro_lamports"`
+	WithoutUnitLimit  int     `json:"without_unit_limit"`
+	CUMeasured        int     `json:"measured_cu_transactions"`
+}
+
+// ComputeFeeStats aggregates AnalyzeFee over txs. Transactions without meta
+// are ignored.
+func ComputeFeeStats(txs []TransactionInfo) FeeStats {
+	var stats FeeStats
+	priorityFees := make([]uint64, 0, len(txs))
+	unitPrices := make([]uint64, 0, len(txs))
+	var utilSum float64
+
+	for _, tx := range txs {
+		fb := AnalyzeFee(tx)
+		if fb == nil {
+			continue
+		}
+		stats.Transactions++
+		stats.TotalFee += fb.TotalFee
+		stats.TotalBaseFee += fb.BaseFee
+		stats.TotalPriorityFee += fb.PriorityFee
+		priorityFees = append(priorityFees, fb.PriorityFee)
+		if fb.PriorityFee > 0 {
+			stats.WithPriorityFee++
+		}
+		if fb.Budget.UnitPrice != nil {
+			unitPrices = append(unitPrices, *fb.Budget.UnitPrice)
+		}
+		if fb.Budget.UnitLimit == nil {
+			stats.WithoutUnitLimit++
+		}
+		if fb.ConsumedCU != nil && fb.RequestedCU > 0 {
+			stats.CUMeasured++
+			stats.TotalRequestedCU += fb.RequestedCU
+			stats.TotalConsumedCU += *fb.ConsumedCU
+			stats.TotalWastedCU += fb.WastedCU
+			utilSum += fb.UtilizationPct
+		}
+	}
+
+	sortUint64s(priorityFees)
+	stats.PriorityFeeP50 = percentile(priorityFees, 50)
+	stats.PriorityFeeP75 = percentile(priorityFees, 75)
+	stats.PriorityFeeP90 = percentile(priorityFees, 90)
+	stats.PriorityFeeP99 = percentile(priorityFees, 99)
+	if len(priorityFees) > 0 {
+		stats.PriorityFeeMax = priorityFees[len(priorityFees)-1]
+	}
+	sortUint64s(unitPrices)
+	stats.UnitPriceP50 = percentile(unitPrices, 50)
+	stats.UnitPriceP90 = percentile(unitPrices, 90)
+	if stats.CUMeasured > 0 {
+		stats.AvgUtilizationPct = utilSum / float64(stats.CUMeasured)
+	}
+	return stats
+}
+
+func sortUint64s(v []uint64) {
+	sort.Slice(v, func(i, j int) bool { return v[i] < v[j] })
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of an ascending
+// slice, or 0 for an empty slice.
+func percentile(sorted []uint64, p float64) uint64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
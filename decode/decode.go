@@ -0,0 +1,95 @@
+// Package decode turns Solana transactions into structured data without an
+// RPC node: raw wire-format parsing, signature verification, instruction
+// names for the native and SPL programs, and compute budget and fee analysis.
+// TransactionInfo, the fetched-transaction type shared by the other packages,
+// lives here so they can depend on it without pulling in an RPC client.
+package decode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// TransactionInfo is a transaction with its execution meta, as fetched from a
+// node, loaded from disk or simulated. Transaction is nil when the payload
+// could not be parsed; Meta is nil for transactions that have not executed.
+type TransactionInfo struct {
+	Signature   string               `json:"signature"`
+	Slot        uint64               `json:"slot"`
+	BlockTime   *int64               `json:"blockTime,omitempty"`
+	Meta        *rpc.TransactionMeta `json:"meta,omitempty"`
+	Transaction *solana.Transaction  `json:"transaction,omitempty"`
+}
+
+// SignatureCheck is the local verification result for one required signer.
+type SignatureCheck struct {
+	Signer    solana.PublicKey `json:"signer"`
+	Signature solana.Signature `json:"signature"`
+	Status    string           `json:"status"` // "valid", "invalid" or "missing"
+}
+
+// DecodeRawTransaction parses a serialized transaction. encoding is "base64",
+// "base58" or "auto"; auto tries base64 first since that is what wallets and
+// RPC responses usually carry, then falls back to base58.
+func DecodeRawTransaction(raw string, encoding string) (*solana.Transaction, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, errors.New("empty transaction input")
+	}
+
+	var candidates []string
+	switch encoding {
+	case "base64":
+		candidates = []string{"base64"}
+	case "base58":
+		candidates = []string{"base58"}
+	case "", "auto":
+		candidates = []string{"base64", "base58"}
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q (want base64, base58 or auto)", encoding)
+	}
+
+	var lastErr error
+	for _, enc := range candidates {
+		var tx *solana.Transaction
+		var err error
+		if enc == "base64" {
+			tx, err = solana.TransactionFromBase64(raw)
+		} else {
+			tx, err = solana.TransactionFromBase58(raw)
+		}
+		if err != nil {
+			lastErr = fmt.Errorf("decode %s transaction: %w", enc, err)
+			continue
+		}
+		return tx, nil
+	}
+	return nil, lastErr
+}
+
+// VerifyTransactionSignatures checks every required signature against the
+// serialized message without contacting an RPC node.
+func VerifyTransactionSignatures(tx *solana.Transaction) ([]SignatureCheck, error) {
+	msg, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("serialize message: %w", err)
+	}
+	signers := tx.Message.Signers()
+	checks := make([]SignatureCheck, 0, len(signers))
+	for i, signer := range signers {
+		check := SignatureCheck{Signer: signer, Status: "missing"}
+		if i < len(tx.Signatures) && !tx.Signatures[i].IsZero() {
+			check.Signature = tx.Signatures[i]
+			check.Status = "invalid"
+			if tx.Signatures[i].Verify(signer, msg) {
+				check.Status = "valid"
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
@@ -0,0 +1,141 @@
+package decode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+)
+
+// DecodedInstruction is a human-readable view of a compiled instruction for
+// the handful of native and SPL programs we know how to read without an IDL.
+type DecodedInstruction struct {
+	ProgramID solana.PublicKey `json:"program_id"`
+	Program   string           `json:"program"`
+	Name      string           `json:"name"`
+	Details   string           `json:"details,omitempty"`
+}
+
+// knownPrograms maps program IDs to short display names.
+var knownPrograms = map[solana.PublicKey]string{
+	solana.SystemProgramID:                    "System",
+	solana.TokenProgramID:                     "Token",
+	solana.Token2022ProgramID:                 "Token-2022",
+	solana.SPLAssociatedTokenAccountProgramID: "Associated Token",
+	solana.MemoProgramID:                      "Memo",
+	solana.StakeProgramID:                     "Stake",
+	solana.VoteProgramID:                      "Vote",
+	solana.AddressLookupTableProgramID:        "Address Lookup Table",
+	solana.BPFLoaderUpgradeableProgramID:      "BPF Upgradeable Loader",
+	ComputeBudgetProgramID:                    "Compute Budget",
+}
+
+// ProgramName returns the short display name of a well-known program, or ""
+// for any other.
+func ProgramName(programID solana.PublicKey) string {
+	return knownPrograms[programID]
+}
+
+// DecodeInstruction names a top-level instruction and, where cheap to do so,
+// extracts its most relevant arguments. Unknown programs yield an empty Name.
+func DecodeInstruction(programID solana.PublicKey, data []byte) DecodedInstruction {
+	d := DecodedInstruction{ProgramID: programID, Program: knownPrograms[programID]}
+
+	switch programID {
+	case solana.SystemProgramID:
+		if len(data) < 4 {
+			return d
+		}
+		id := binary.LittleEndian.Uint32(data[:4])
+		d.Name = system.InstructionIDToName(id)
+		if id == system.Instruction_Transfer && len(data) >= 12 {
+			lamports := binary.LittleEndian.Uint64(data[4:12])
+			d.Details = fmt.Sprintf("%.9f SOL", float64(lamports)/1e9)
+		}
+	case solana.TokenProgramID, solana.Token2022ProgramID:
+		if len(data) < 1 {
+			return d
+		}
+		d.Name = token.InstructionIDToName(data[0])
+		switch data[0] {
+		case token.Instruction_Transfer, token.Instruction_Approve, token.Instruction_MintTo, token.Instruction_Burn:
+			if len(data) >= 9 {
+				d.Details = fmt.Sprintf("amount %d", binary.LittleEndian.Uint64(data[1:9]))
+			}
+		case token.Instruction_TransferChecked, token.Instruction_ApproveChecked, token.Instruction_MintToChecked, token.Instruction_BurnChecked:
+			if len(data) >= 10 {
+				d.Details = FormatTokenAmount(binary.LittleEndian.Uint64(data[1:9]), data[9])
+			}
+		}
+	case solana.SPLAssociatedTokenAccountProgramID:
+		switch {
+		case len(data) == 0 || data[0] == 0:
+			d.Name = "Create"
+		case data[0] == 1:
+			d.Name = "CreateIdempotent"
+		case data[0] == 2:
+			d.Name = "RecoverNested"
+		}
+	case solana.MemoProgramID:
+		d.Name = "Memo"
+		if utf8.Valid(data) {
+			d.Details = string(data)
+		}
+	case ComputeBudgetProgramID:
+		d.Name, d.Details = decodeComputeBudgetInstruction(data)
+	}
+	return d
+}
+
+// decodeComputeBudgetInstruction returns the name and argument of a single
+// Compute Budget instruction.
+func decodeComputeBudgetInstruction(data []byte) (string, string) {
+	if len(data) == 0 {
+		return "", ""
+	}
+	switch data[0] {
+	case cbRequestUnitsDeprecated:
+		return "RequestUnits", ""
+	case cbRequestHeapFrame:
+		if len(data) >= 5 {
+			return "RequestHeapFrame", fmt.Sprintf("%d bytes", binary.LittleEndian.Uint32(data[1:5]))
+		}
+		return "RequestHeapFrame", ""
+	case cbSetComputeUnitLimit:
+		if len(data) >= 5 {
+			return "SetComputeUnitLimit", fmt.Sprintf("%d CU", binary.LittleEndian.Uint32(data[1:5]))
+		}
+		return "SetComputeUnitLimit", ""
+	case cbSetComputeUnitPrice:
+		if len(data) >= 9 {
+			return "SetComputeUnitPrice", fmt.Sprintf("%d µlamports/CU", binary.LittleEndian.Uint64(data[1:9]))
+		}
+		return "SetComputeUnitPrice", ""
+	case cbSetLoadedAccountsDataSizeLimit:
+		if len(data) >= 5 {
+			return "SetLoadedAccountsDataSizeLimit", fmt.Sprintf("%d bytes", binary.LittleEndian.Uint32(data[1:5]))
+		}
+		return "SetLoadedAccountsDataSizeLimit", ""
+	}
+	return "", ""
+}
+
+// FormatTokenAmount renders a raw token amount using the given decimals,
+// without trailing zeros.
+func FormatTokenAmount(amount uint64, decimals uint8) string {
+	if decimals == 0 {
+		return fmt.Sprintf("%d", amount)
+	}
+	s := fmt.Sprintf("%0*d", int(decimals)+1, amount)
+	whole, frac := s[:len(s)-int(decimals)], s[len(s)-int(decimals):]
+	for len(frac) > 0 && frac[len(frac)-1] == '0' {
+		frac = frac[:len(frac)-1]
+	}
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
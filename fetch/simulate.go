@@ -0,0 +1,93 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/decode"
+)
+
+// SimulateOptions mirrors the simulateTransaction config we expose on the CLI.
+type SimulateOptions struct {
+	SigVerify              bool
+	ReplaceRecentBlockhash bool
+	Commitment             rpc.CommitmentType
+}
+
+// SimulateTransaction runs tx through simulateTransaction and shapes the result
+// like a landed transaction so it can go through the regular formatter. SOL
+// balance changes are derived by reading the static accounts before the
+// simulation and comparing them with the post-simulation state the node
+// returns. The fee is estimated locally since simulation does not charge one.
+func (t *TransactionService) SimulateTransaction(ctx context.Context, tx *solana.Transaction, opts SimulateOptions) (*decode.TransactionInfo, error) {
+	if opts.SigVerify && opts.ReplaceRecentBlockhash {
+		return nil, errors.New("sig-verify and replace-recent-blockhash are mutually exclusive")
+	}
+	if opts.Commitment == "" {
+		opts.Commitment = rpc.CommitmentConfirmed
+	}
+
+	// Unsigned transactions may arrive without signature slots; pad them so
+	// the wire format stays valid. The node ignores them unless SigVerify is set.
+	for len(tx.Signatures) < int(tx.Message.Header.NumRequiredSignatures) {
+		tx.Signatures = append(tx.Signatures, solana.Signature{})
+	}
+
+	keys := tx.Message.AccountKeys
+	pre, err := t.client.GetMultipleAccountsWithOpts(ctx, keys, &rpc.GetMultipleAccountsOpts{
+		Commitment: opts.Commitment,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pre-simulation accounts: %w", err)
+	}
+
+	sim, err := t.client.SimulateTransactionWithOpts(ctx, tx, &rpc.SimulateTransactionOpts{
+		SigVerify:              opts.SigVerify,
+		ReplaceRecentBlockhash: opts.ReplaceRecentBlockhash,
+		Commitment:             opts.Commitment,
+		Accounts: &rpc.SimulateTransactionAccountsOpts{
+			Encoding:  solana.EncodingBase64,
+			Addresses: keys,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate transaction: %w", err)
+	}
+	if sim.Value == nil {
+		return nil, errors.New("simulateTransaction returned no result")
+	}
+
+	meta := &rpc.TransactionMeta{
+		Err:                  sim.Value.Err,
+		Fee:                  decode.EstimateFee(tx),
+		LogMessages:          sim.Value.Logs,
+		ComputeUnitsConsumed: sim.Value.UnitsConsumed,
+		PreBalances:          make([]uint64, len(keys)),
+		PostBalances:         make([]uint64, len(keys)),
+	}
+	for i := range keys {
+		if pre != nil && i < len(pre.Value) && pre.Value[i] != nil {
+			meta.PreBalances[i] = pre.Value[i].Lamports
+		}
+		if i < len(sim.Value.Accounts) && sim.Value.Accounts[i] != nil {
+			meta.PostBalances[i] = sim.Value.Accounts[i].Lamports
+		} else if sim.Value.Err != nil {
+			// Failed simulations return no account state; nothing changed.
+			meta.PostBalances[i] = meta.PreBalances[i]
+		}
+	}
+
+	info := &decode.TransactionInfo{
+		Slot:        sim.Context.Slot,
+		Meta:        meta,
+		Transaction: tx,
+	}
+	if len(tx.Signatures) > 0 && !tx.Signatures[0].IsZero() {
+		info.Signature = tx.Signatures[0].String()
+	}
+	return info, nil
+}
@@ -0,0 +1,239 @@
+// Package fetch reads account histories and single transactions from a
+// Solana RPC node and simulates unsigned ones.
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/decode"
+)
+
+// ErrTransactionNotFound is returned when the RPC node has no transaction for
+// a signature at the requested commitment.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// AccountTransactions is a page of an account's history, newest first.
+type AccountTransactions struct {
+	Account      solana.PublicKey         `json:"account"`
+	Transactions []decode.TransactionInfo `json:"transactions"`
+	LastFetched  time.Time                `json:"last_fetched"`
+	// NextBefore is the oldest listed signature when the listing filled the
+	// requested limit, to pass as before for the next page; zero at the end
+	// of history. It is set even when that transaction failed to load.
+	NextBefore solana.Signature `json:"-"`
+}
+
+// Config configures a TransactionService.
+type Config struct {
+	// Commitment is used to list and fetch account history: confirmed (the
+	// default) or finalized. getSignaturesForAddress and getTransaction do not
+	// serve processed data.
+	Commitment rpc.CommitmentType
+	// Now stamps AccountTransactions.LastFetched; nil uses time.Now.
+	Now func() time.Time
+}
+
+// TransactionService fetches transactions through one RPC client. It is safe
+// for concurrent use.
+type TransactionService struct {
+	client *rpc.Client
+	cfg    Config
+}
+
+// NewTransactionService creates a service for client configured by cfg.
+func NewTransactionService(client *rpc.Client, cfg Config) (*TransactionService, error) {
+	switch cfg.Commitment {
+	case "":
+		cfg.Commitment = rpc.CommitmentConfirmed
+	case rpc.CommitmentConfirmed, rpc.CommitmentFinalized:
+	default:
+		return nil, fmt.Errorf("unsupported commitment %q for account history; use confirmed or finalized", cfg.Commitment)
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &TransactionService{client: client, cfg: cfg}, nil
+}
+
+// FetchAccountTransactions fetches up to limit of the account's newest
+// transactions; a non-positive limit takes what the node returns in one page.
+// Transactions that fail to load are logged and left out.
+func (t *TransactionService) FetchAccountTransactions(ctx context.Context, account solana.PublicKey, limit int) (*AccountTransactions, error) {
+	return t.FetchAccountTransactionsBefore(ctx, account, limit, solana.Signature{})
+}
+
+// FetchAccountTransactionsBefore is FetchAccountTransactions starting below
+// the given signature, for paging back through history. A zero signature
+// starts at the newest transaction.
+func (t *TransactionService) FetchAccountTransactionsBefore(ctx context.Context, account solana.PublicKey, limit int, before solana.Signature) (*AccountTransactions, error) {
+	opts := &rpc.GetSignaturesForAddressOpts{
+		Commitment: t.cfg.Commitment,
+		Before:     before,
+	}
+	if limit > 0 {
+		opts.Limit = &limit
+	}
+	signatures, err := t.client.GetSignaturesForAddressWithOpts(ctx, account, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signatures for account %s: %w", account.String(), err)
+	}
+
+	processCount := len(signatures)
+	if limit > 0 && limit < processCount {
+		processCount = limit
+	}
+	slog.DebugContext(ctx, "Fetching transactions", "count", processCount)
+
+	type transactionResult struct {
+		info  decode.TransactionInfo
+		index int
+		err   error
+	}
+
+	resultChan := make(chan transactionResult, processCount)
+	var wg sync.WaitGroup
+
+	for i := 0; i < processCount; i++ {
+		wg.Add(1)
+		go func(index int, sig *rpc.TransactionSignature) {
+			defer wg.Done()
+
+			txInfo, err := t.getTransactionInfo(ctx, sig.Signature, t.cfg.Commitment)
+			if err != nil {
+				slog.WarnContext(ctx, "Fetching transaction failed", "signature", sig.Signature.String(), "err", err)
+				resultChan <- transactionResult{err: err, index: index}
+				return
+			}
+
+			// Prefer the slot and block time reported by the signature listing
+			if sig.BlockTime != nil {
+				timestamp := int64(*sig.BlockTime)
+				txInfo.BlockTime = &timestamp
+			}
+			txInfo.Slot = sig.Slot
+
+			resultChan <- transactionResult{info: *txInfo, index: index, err: nil}
+		}(i, signatures[i])
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	results := make([]decode.TransactionInfo, 0, processCount)
+	resultMap := make(map[int]decode.TransactionInfo)
+
+	for result := range resultChan {
+		if result.err == nil {
+			resultMap[result.index] = result.info
+		}
+	}
+
+	for i := 0; i < processCount; i++ {
+		if txInfo, exists := resultMap[i]; exists {
+			results = append(results, txInfo)
+		}
+	}
+
+	// Do not hand out a partial history when the fetch was interrupted
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	transactions := results
+
+	page := &AccountTransactions{
+		Account:      account,
+		Transactions: transactions,
+		LastFetched:  t.cfg.Now(),
+	}
+	if limit > 0 && processCount == limit {
+		page.NextBefore = signatures[processCount-1].Signature
+	}
+	return page, nil
+}
+
+// FetchTransaction fetches a single transaction by signature. getTransaction
+// only serves confirmed or finalized transactions, so processed is rejected.
+func (t *TransactionService) FetchTransaction(ctx context.Context, signature solana.Signature, commitment rpc.CommitmentType) (*decode.TransactionInfo, error) {
+	if commitment == rpc.CommitmentProcessed {
+		return nil, fmt.Errorf("commitment %q is not supported by getTransaction; use confirmed or finalized", commitment)
+	}
+	txInfo, err := t.getTransactionInfo(ctx, signature, commitment)
+	if err != nil {
+		if errors.Is(err, rpc.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s at %s commitment", ErrTransactionNotFound, signature.String(), commitment)
+		}
+		return nil, fmt.Errorf("failed to get transaction %s: %w", signature.String(), err)
+	}
+	return txInfo, nil
+}
+
+// FetchRawTransaction returns the unmodified getTransaction JSON result, using
+// the json encoding so the dump is readable without further decoding.
+func (t *TransactionService) FetchRawTransaction(ctx context.Context, signature solana.Signature, commitment rpc.CommitmentType) (json.RawMessage, error) {
+	var raw json.RawMessage
+	params := []interface{}{
+		signature.String(),
+		map[string]interface{}{
+			"encoding":                       "json",
+			"commitment":                     commitment,
+			"maxSupportedTransactionVersion": 0,
+		},
+	}
+	if err := t.client.RPCCallForInto(ctx, &raw, "getTransaction", params); err != nil {
+		return nil, fmt.Errorf("failed to get raw transaction %s: %w", signature.String(), err)
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, fmt.Errorf("transaction %s not found at %s commitment", signature.String(), commitment)
+	}
+	return raw, nil
+}
+
+// getTransactionInfo calls getTransaction and decodes the binary payload into
+// a TransactionInfo. A payload that fails to parse is logged and left nil so
+// the meta can still be shown.
+func (t *TransactionService) getTransactionInfo(ctx context.Context, signature solana.Signature, commitment rpc.CommitmentType) (*decode.TransactionInfo, error) {
+	maxVersion := uint64(0)
+	txResult, err := t.client.GetTransaction(ctx, signature, &rpc.GetTransactionOpts{
+		Encoding:                       solana.EncodingBase64,
+		Commitment:                     commitment,
+		MaxSupportedTransactionVersion: &maxVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var blockTime *int64
+	if txResult.BlockTime != nil {
+		timestamp := int64(*txResult.BlockTime)
+		blockTime = &timestamp
+	}
+
+	txInfo := &decode.TransactionInfo{
+		Signature: signature.String(),
+		Slot:      txResult.Slot,
+		BlockTime: blockTime,
+		Meta:      txResult.Meta,
+	}
+
+	if txResult.Transaction != nil {
+		parsedTx, err := txResult.Transaction.GetTransaction()
+		if err != nil {
+			slog.WarnContext(ctx, "Parsing transaction failed, showing meta only", "signature", signature.String(), "err", err)
+		} else {
+			txInfo.Transaction = parsedTx
+		}
+	}
+	return txInfo, nil
+}
@@ -0,0 +1,182 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/rpc/fixture"
+)
+
+// The fixtures in testdata were recorded from the mock chain's default
+// scenario. To record them again, run `go run . mock` and then
+// `go test ./fetch -record http://127.0.0.1:8899`.
+var (
+	record = flag.String("record", "", "record testdata fixtures from this RPC endpoint instead of replaying them")
+	update = flag.Bool("update", false, "rewrite testdata golden files")
+)
+
+// alice is the first wallet of the mock scenario.
+var alice = solana.MustPublicKeyFromBase58("6UeLw8eMAULzV1kvuQRvcWmdm2u7yvLnWuSXvRM4o9DS")
+
+func replay(t *testing.T, name string) *fixture.Server {
+	t.Helper()
+	srv, err := fixture.Serve(filepath.Join("testdata", name), *record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return srv
+}
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update || *record != "" {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run go test -update to accept it):\n%s", path, got)
+	}
+}
+
+// pageSummary is the part of a page the golden file pins down.
+type pageSummary struct {
+	LastFetched  string      `json:"last_fetched"`
+	NextBefore   string      `json:"next_before,omitempty"`
+	Transactions []txSummary `json:"transactions"`
+}
+
+type txSummary struct {
+	Signature    string `json:"signature"`
+	Slot         uint64 `json:"slot"`
+	BlockTime    *int64 `json:"block_time"`
+	Fee          uint64 `json:"fee"`
+	Failed       bool   `json:"failed,omitempty"`
+	Instructions int    `json:"instructions"`
+}
+
+func summarize(page *AccountTransactions) pageSummary {
+	s := pageSummary{LastFetched: page.LastFetched.UTC().Format("2006-01-02T15:04:05Z")}
+	if !page.NextBefore.IsZero() {
+		s.NextBefore = page.NextBefore.String()
+	}
+	for _, tx := range page.Transactions {
+		ts := txSummary{Signature: tx.Signature, Slot: tx.Slot, BlockTime: tx.BlockTime}
+		if tx.Meta != nil {
+			ts.Fee, ts.Failed = tx.Meta.Fee, tx.Meta.Err != nil
+		}
+		if tx.Transaction != nil {
+			ts.Instructions = len(tx.Transaction.Message.Instructions)
+		}
+		s.Transactions = append(s.Transactions, ts)
+	}
+	return s
+}
+
+func newService(t *testing.T, srv *fixture.Server) *TransactionService {
+	t.Helper()
+	recordedAt := srv.RecordedAt
+	svc, err := NewTransactionService(rpc.New(srv.URL), Config{Now: func() time.Time { return recordedAt }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func TestFetchAccountTransactionsBefore(t *testing.T) {
+	ctx := context.Background()
+	svc := newService(t, replay(t, "history.json"))
+
+	first, err := svc.FetchAccountTransactions(ctx, alice, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Transactions) != 3 {
+		t.Fatalf("first page has %d transactions, want 3", len(first.Transactions))
+	}
+	if got, want := first.NextBefore.String(), first.Transactions[2].Signature; got != want {
+		t.Fatalf("NextBefore = %s, want the oldest listed signature %s", got, want)
+	}
+
+	second, err := svc.FetchAccountTransactionsBefore(ctx, alice, 3, first.NextBefore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Transactions) == 0 || second.Transactions[0].Slot > first.Transactions[2].Slot {
+		t.Fatalf("second page does not continue below the first: %+v", summarize(second))
+	}
+
+	out, err := json.MarshalIndent([]pageSummary{summarize(first), summarize(second)}, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "history.golden.json", append(out, '\n'))
+}
+
+// A transaction that fails to load is left out of the page, but the cursor
+// still comes from the signature listing, so paging does not stop early.
+func TestFetchAccountTransactionsKeepsCursorPastFailedFetch(t *testing.T) {
+	f, err := fixture.Load(filepath.Join("testdata", "history.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listing []struct {
+		Signature string `json:"signature"`
+	}
+	for _, in := range f.Interactions {
+		if in.Method == "getSignaturesForAddress" && !strings.Contains(string(in.Params), `"before"`) {
+			if err := json.Unmarshal(in.Result, &listing); err != nil {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+	if len(listing) != 3 {
+		t.Fatalf("fixture lists %d signatures, want 3", len(listing))
+	}
+	oldest := listing[2].Signature
+	for i, in := range f.Interactions {
+		if in.Method == "getTransaction" && strings.Contains(string(in.Params), oldest) {
+			f.Interactions[i].Result = nil
+			f.Interactions[i].Error = json.RawMessage(`{"code":-32005,"message":"node is behind"}`)
+		}
+	}
+
+	srv := fixture.NewReplayServer(f)
+	defer srv.Close()
+	svc, err := NewTransactionService(rpc.New(srv.URL), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := svc.FetchAccountTransactions(context.Background(), alice, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Transactions) != 2 {
+		t.Errorf("page has %d transactions, want the 2 that loaded", len(page.Transactions))
+	}
+	if page.NextBefore.String() != oldest {
+		t.Errorf("NextBefore = %s, want %s", page.NextBefore, oldest)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go-solana-tx-explorer/registry"
+	"go-solana-tx-explorer/rpc/fixture"
+)
+
+// startFixtures applies the -record and -replay global flags. Recording routes
+// every RPC client through a fixture.Recorder; replaying points the config at
+// a local replay server, pins the clock to the recording time and skips the
+// token registry download, so output depends only on the fixture. The
+// returned function saves the recording or stops the server.
+func startFixtures(cfg *Config, recordPath, replayPath string) (func(), error) {
+	switch {
+	case recordPath != "" && replayPath != "":
+		return nil, errors.New("-record and -replay cannot be combined")
+	case recordPath != "":
+		rec := fixture.NewRecorder(nil)
+		rpcTransport = rec
+		return func() {
+			if err := rec.Save(recordPath); err != nil {
+				slog.Error("Saving RPC recording failed", "path", recordPath, "err", err)
+				return
+			}
+			slog.Info("Saved RPC recording", "path", recordPath, "interactions", rec.Len())
+		}, nil
+	case replayPath != "":
+		f, err := fixture.Load(replayPath)
+		if err != nil {
+			return nil, fmt.Errorf("replay: %w", err)
+		}
+		srv := fixture.NewReplayServer(f)
+		cfg.RPCURL = srv.URL
+		cfg.WSURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+		recordedAt := f.RecordedAt
+		now = func() time.Time { return recordedAt }
+		tokenRegistry = registry.New(registry.Config{Offline: true})
+		return srv.Close, nil
+	}
+	return func() {}, nil
+}
@@ -2,408 +2,289 @@ package main
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
-	"github.com/gagliardetto/solana-go/rpc"
-	"github.com/jedib0t/go-pretty/v6/table"
-	"github.com/jedib0t/go-pretty/v6/text"
+
+	"go-solana-tx-explorer/classify"
+	"go-solana-tx-explorer/portfolio"
+	"go-solana-tx-explorer/render"
+	"go-solana-tx-explorer/rpc/client"
 )
 
-// TransactionFormatter handles pretty printing of transaction data
-type TransactionFormatter struct {
-	showFullData bool
+// cliFormatter adds the CLI's own views (watchlists, alerts, commitment
+// updates and notification delivery) to the library formatter.
+type cliFormatter struct {
+	*render.TransactionFormatter
+	cluster *client.Cluster
 }
 
-// NewTransactionFormatter creates a new formatter instance
-func NewTransactionFormatter(showFullData bool) *TransactionFormatter {
-	return &TransactionFormatter{
-		showFullData: showFullData,
+// newFormatter builds a formatter for the configured output format and
+// theme. cluster is nil for transactions that are not on chain.
+func newFormatter(cfg *Config, showFullData bool, cluster *client.Cluster) *cliFormatter {
+	return &cliFormatter{
+		TransactionFormatter: render.NewTransactionFormatter(render.Config{
+			ShowFullData: showFullData,
+			Cluster:      cluster,
+			Labels:       cfg.Labels,
+			Format:       cfg.Output,
+			Theme:        cfg.Theme,
+		}),
+		cluster: cluster,
 	}
 }
 
-// FormatTransactionSummary displays a summary table of all transactions
-func (f *TransactionFormatter) FormatTransactionSummary(accountTxs *AccountTransactions) {
-	// Print header with account info
-	fmt.Printf("\n%s\n", text.Colors{text.BgBlue, text.FgWhite}.Sprint(" SOLANA TRANSACTION EXPLORER "))
-	fmt.Printf("Account: %s\n", text.FgCyan.Sprint(accountTxs.Account.String()))
-	fmt.Printf("Total Transactions: %s\n", text.FgGreen.Sprint(len(accountTxs.Transactions)))
-	fmt.Printf("Last Fetched: %s\n\n", text.FgYellow.Sprint(accountTxs.LastFetched.Format(time.RFC3339)))
-
-	// Create summary table
-	t := table.NewWriter()
-	t.SetTitle("Transaction Summary")
-	t.AppendHeader(table.Row{"#", "Signature (Short)", "Status", "Slot", "Time", "Fee (SOL)", "Balance Change"})
-
-	for i, tx := range accountTxs.Transactions {
-		// Truncate signature for readability
-		shortSig := tx.Signature
-		if len(shortSig) > 16 {
-			shortSig = shortSig[:8] + "..." + shortSig[len(shortSig)-8:]
-		}
-
-		// Determine status
-		status := "✅ SUCCESS"
-		if tx.Meta != nil && tx.Meta.Err != nil {
-			status = "❌ FAILED"
-		}
-
-		// Format time
-		timeStr := "N/A"
-		if tx.BlockTime != nil {
-			timestamp := time.Unix(*tx.BlockTime, 0)
-			timeStr = timestamp.Format("01-02 15:04")
-		}
-
-		// Calculate fee in SOL
-		feeSOL := "0"
-		if tx.Meta != nil {
-			feeSOL = fmt.Sprintf("%.6f", float64(tx.Meta.Fee)/1e9)
-		}
-
-		// Calculate balance change for the main account
-		balanceChange := "0"
-		if tx.Meta != nil && len(tx.Meta.PreBalances) > 0 && len(tx.Meta.PostBalances) > 0 {
-			change := int64(tx.Meta.PostBalances[0]) - int64(tx.Meta.PreBalances[0])
-			if change != 0 {
-				balanceChange = fmt.Sprintf("%+.6f", float64(change)/1e9)
-			}
-		}
-
-		t.AppendRow(table.Row{
-			i + 1,
-			shortSig,
-			status,
-			tx.Slot,
-			timeStr,
-			feeSOL,
-			balanceChange,
-		})
+// FormatHint prints tips for a person at a terminal; structured and
+// document formats leave them out.
+func (f *cliFormatter) FormatHint(lines ...string) {
+	if f.Format() != render.Pretty {
+		return
 	}
-
-	// Style the table
-	t.SetStyle(table.StyleColoredBright)
-	t.Style().Options.SeparateRows = true
-
-	fmt.Println(t.Render())
+	fmt.Print(strings.Join(lines, "\n") + "\n")
 }
 
-// FormatTransactionDetails displays detailed information for a specific transaction
-func (f *TransactionFormatter) FormatTransactionDetails(tx TransactionInfo, index int) {
-	fmt.Printf("\n%s\n",
-		text.Colors{text.BgGreen, text.FgWhite}.Sprintf(" TRANSACTION #%d DETAILS ", index+1))
-
-	// Basic info table
-	basicInfo := table.NewWriter()
-	basicInfo.SetTitle("Basic Information")
-	basicInfo.AppendRow(table.Row{"Signature", tx.Signature})
-	basicInfo.AppendRow(table.Row{"Slot", tx.Slot})
-
-	if tx.BlockTime != nil {
-		timestamp := time.Unix(*tx.BlockTime, 0)
-		basicInfo.AppendRow(table.Row{"Block Time", timestamp.Format(time.RFC3339)})
+// FormatWalletHeader displays a banner introducing one wallet of a watchlist
+func (f *cliFormatter) FormatWalletHeader(w WatchedWallet) error {
+	// The banner already carries the label
+	address := render.AccountCell(f.cluster, nil, w.Account, "")
+	address.Tone = render.ToneInfo
+	doc := render.Document{
+		render.Banner{Title: "👛 WALLET: " + w.Label, Tone: render.ToneAccent},
+		render.Field{Label: "Address", Value: address},
 	}
-
-	basicInfo.SetStyle(table.StyleColoredDark)
-	fmt.Println(basicInfo.Render())
-
-	// Transaction meta information
-	if tx.Meta != nil {
-		f.formatTransactionMeta(tx.Meta)
+	if f.cluster != nil {
+		doc = append(doc, render.Field{Label: "Explorer", Value: render.Cell{Text: f.cluster.AccountURL(w.Account.String())}})
 	}
-
-	// Transaction message information
-	if tx.Transaction != nil {
-		f.formatTransactionMessage(tx.Transaction)
+	if len(w.Tags) > 0 {
+		doc = append(doc, render.Field{Label: "Tags", Value: render.Cell{Text: strings.Join(w.Tags, ", "), Tone: render.ToneWarning}})
 	}
+	return f.Render(render.DocumentView{Name: "wallet", Data: w, Doc: doc})
 }
 
-// formatTransactionMeta formats the transaction metadata
-func (f *TransactionFormatter) formatTransactionMeta(meta *rpc.TransactionMeta) {
-	fmt.Printf("\n%s\n", text.FgYellow.Sprint("💰 TRANSACTION META"))
-
-	metaTable := table.NewWriter()
-	metaTable.SetTitle("Meta Information")
-
-	// Fee
-	metaTable.AppendRow(table.Row{"Fee (lamports)", fmt.Sprintf("%d", meta.Fee)})
-	metaTable.AppendRow(table.Row{"Fee (SOL)", fmt.Sprintf("%.9f", float64(meta.Fee)/1e9)})
-
-	// Status
-	status := "SUCCESS ✅"
-	if meta.Err != nil {
-		status = fmt.Sprintf("FAILED ❌ - %v", meta.Err)
-	}
-	metaTable.AppendRow(table.Row{"Status", status})
-
-	// Compute units
-	if meta.ComputeUnitsConsumed != nil {
-		metaTable.AppendRow(table.Row{"Compute Units", fmt.Sprintf("%d", *meta.ComputeUnitsConsumed)})
-	}
-
-	metaTable.SetStyle(table.StyleLight)
-	fmt.Println(metaTable.Render())
-
-	// Balance changes
-	f.formatBalanceChanges(meta)
-
-	// Token balance changes
-	f.formatTokenBalances(meta)
-
-	// Program logs (limited)
-	if len(meta.LogMessages) > 0 {
-		f.formatProgramLogs(meta.LogMessages)
-	}
+// combinedRow is one transaction of the combined watchlist summary,
+// classified from its wallet's point of view.
+type combinedRow struct {
+	Wallet    string           `json:"wallet"`
+	Address   solana.PublicKey `json:"address"`
+	Signature string           `json:"signature"`
+	Status    string           `json:"status"` // success or failed
+	Type      string           `json:"type"`
+	Direction string           `json:"direction,omitempty"`
+	Slot      uint64           `json:"slot"`
+	BlockTime *time.Time       `json:"block_time,omitempty"`
+	Fee       uint64           `json:"fee"`
+	SOLChange int64            `json:"sol_change"`
 }
 
-// formatBalanceChanges displays SOL balance changes
-func (f *TransactionFormatter) formatBalanceChanges(meta *rpc.TransactionMeta) {
-	if len(meta.PreBalances) == 0 || len(meta.PostBalances) == 0 {
-		return
-	}
-
-	fmt.Printf("\n%s\n", text.FgCyan.Sprint("📊 SOL BALANCE CHANGES"))
-
-	balanceTable := table.NewWriter()
-	balanceTable.SetTitle("Account Balance Changes")
-	balanceTable.AppendHeader(table.Row{"Account", "Pre (SOL)", "Post (SOL)", "Change (SOL)"})
-
-	for i, preBalance := range meta.PreBalances {
-		if i < len(meta.PostBalances) {
-			postBalance := meta.PostBalances[i]
-			change := int64(postBalance) - int64(preBalance)
-
-			if change != 0 {
-				changeStr := fmt.Sprintf("%+.6f", float64(change)/1e9)
-				if change > 0 {
-					changeStr = text.FgGreen.Sprint(changeStr)
-				} else {
-					changeStr = text.FgRed.Sprint(changeStr)
+// FormatCombinedSummary displays every wallet's transactions in one table,
+// newest first, with the wallet label as the leading column
+func (f *cliFormatter) FormatCombinedSummary(groups []WalletHistory) error {
+	var rows []combinedRow
+	for _, g := range groups {
+		for _, tx := range g.History.Transactions {
+			// Same transaction may appear under several wallets; each row is
+			// classified from its own wallet's point of view
+			c := classify.ClassifyTransaction(tx, g.Wallet.Account)
+			row := combinedRow{
+				Wallet:    g.Wallet.Label,
+				Address:   g.Wallet.Account,
+				Signature: tx.Signature,
+				Status:    "success",
+				Type:      string(c.Type),
+				Direction: c.Direction,
+				Slot:      tx.Slot,
+				SOLChange: c.SOLChange,
+			}
+			if tx.BlockTime != nil {
+				t := time.Unix(*tx.BlockTime, 0).UTC()
+				row.BlockTime = &t
+			}
+			if tx.Meta != nil {
+				if tx.Meta.Err != nil {
+					row.Status = "failed"
 				}
-
-				balanceTable.AppendRow(table.Row{
-					fmt.Sprintf("Account[%d]", i),
-					fmt.Sprintf("%.6f", float64(preBalance)/1e9),
-					fmt.Sprintf("%.6f", float64(postBalance)/1e9),
-					changeStr,
-				})
+				row.Fee = tx.Meta.Fee
 			}
+			rows = append(rows, row)
 		}
 	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Slot > rows[j].Slot })
 
-	if balanceTable.Length() > 0 {
-		balanceTable.SetStyle(table.StyleLight)
-		fmt.Println(balanceTable.Render())
+	doc := render.Document{
+		render.Banner{Title: "🗂️  COMBINED VIEW", Tone: render.ToneInfo},
+		render.Field{Label: "Wallets", Value: render.Cell{Text: strconv.Itoa(len(groups)), Tone: render.TonePositive}},
+		render.Field{Label: "Transactions", Value: render.Cell{Text: strconv.Itoa(len(rows)), Tone: render.TonePositive}},
 	}
-}
-
-// formatTokenBalances displays token balance information
-func (f *TransactionFormatter) formatTokenBalances(meta *rpc.TransactionMeta) {
-	if len(meta.PostTokenBalances) == 0 {
-		return
+	if len(rows) == 0 {
+		doc = append(doc, render.Note{Text: "No transactions across the selected wallets.", Tone: render.ToneWarning})
+		return f.Render(render.DocumentView{Name: "combined_summary", Data: rows, Doc: doc})
 	}
 
-	fmt.Printf("\n%s\n", text.FgMagenta.Sprint("🪙 TOKEN BALANCES"))
-
-	tokenTable := table.NewWriter()
-	tokenTable.SetTitle("Token Information")
-	tokenTable.AppendHeader(table.Row{"Mint", "Amount", "Decimals"})
-
-	for _, tokenBalance := range meta.PostTokenBalances {
-		if tokenBalance.UiTokenAmount != nil {
-			tokenTable.AppendRow(table.Row{
-				tokenBalance.Mint.String()[:8] + "...",
-				tokenBalance.UiTokenAmount.UiAmountString,
-				tokenBalance.UiTokenAmount.Decimals,
-			})
+	t := render.Table{
+		Title:        "All Wallets",
+		Header:       []string{"#", "Wallet", "Signature (Short)", "Status", "Type", "Slot", "Time", "Fee (SOL)", "SOL Change"},
+		Emphasized:   true,
+		SeparateRows: true,
+	}
+	for i, r := range rows {
+		status := "✅ SUCCESS"
+		if r.Status == "failed" {
+			status = "❌ FAILED"
+		}
+		timeStr := "N/A"
+		if r.BlockTime != nil {
+			timeStr = r.BlockTime.Local().Format("01-02 15:04")
+		}
+		txType := r.Type
+		if r.Direction != "" {
+			txType += " (" + r.Direction + ")"
+		}
+		change := "0"
+		if r.SOLChange != 0 {
+			change = fmt.Sprintf("%+.6f", float64(r.SOLChange)/1e9)
 		}
+		t.Rows = append(t.Rows, []any{i + 1, r.Wallet, render.TxCell(f.cluster, r.Signature, render.ShortAddress(r.Signature)),
+			status, txType, r.Slot, timeStr, fmt.Sprintf("%.6f", float64(r.Fee)/1e9), change})
 	}
-
-	tokenTable.SetStyle(table.StyleLight)
-	fmt.Println(tokenTable.Render())
+	return f.Render(render.DocumentView{Name: "combined_summary", Data: rows, Doc: append(doc, t)})
 }
 
-// formatProgramLogs displays program execution logs
-func (f *TransactionFormatter) formatProgramLogs(logs []string) {
-	fmt.Printf("\n%s\n", text.FgYellow.Sprint("📝 PROGRAM LOGS"))
-
-	logTable := table.NewWriter()
-	logTable.SetTitle("Program Execution Logs")
-	logTable.AppendHeader(table.Row{"#", "Message"})
-
-	maxLogs := 5
-	if f.showFullData {
-		maxLogs = len(logs)
-	}
+// combinedHolding is one token summed across the wallets holding it.
+type combinedHolding struct {
+	portfolio.TokenHolding
+	Total   float64 `json:"total"`
+	Wallets int     `json:"wallets"`
+}
 
-	for i, logMsg := range logs {
-		if i >= maxLogs {
-			break
+// FormatCombinedPortfolio displays token holdings summed across wallets
+func (f *cliFormatter) FormatCombinedPortfolio(results []WalletPortfolio) error {
+	byMint := make(map[string]*combinedHolding)
+	for _, r := range results {
+		if r.Err != nil {
+			continue
 		}
-
-		// Truncate very long log messages
-		if len(logMsg) > 80 && !f.showFullData {
-			logMsg = logMsg[:77] + "..."
+		for _, h := range r.Holdings {
+			amount, err := strconv.ParseFloat(h.UiAmount, 64)
+			if err != nil || amount == 0 {
+				continue
+			}
+			tot, ok := byMint[h.Mint]
+			if !ok {
+				tot = &combinedHolding{TokenHolding: h}
+				byMint[h.Mint] = tot
+			}
+			tot.Total += amount
+			tot.Wallets++
 		}
-
-		logTable.AppendRow(table.Row{i + 1, logMsg})
 	}
 
-	if len(logs) > maxLogs {
-		logTable.AppendRow(table.Row{"...", fmt.Sprintf("and %d more logs", len(logs)-maxLogs)})
+	totals := make([]*combinedHolding, 0, len(byMint))
+	for _, tot := range byMint {
+		totals = append(totals, tot)
 	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Wallets != totals[j].Wallets {
+			return totals[i].Wallets > totals[j].Wallets
+		}
+		return totals[i].Mint < totals[j].Mint
+	})
 
-	logTable.SetStyle(table.StyleLight)
-	logTable.Style().Options.SeparateRows = true
-	fmt.Println(logTable.Render())
-}
-
-// formatTransactionMessage displays transaction message details
-func (f *TransactionFormatter) formatTransactionMessage(tx *solana.Transaction) {
-	fmt.Printf("\n%s\n", text.FgBlue.Sprint("📄 TRANSACTION MESSAGE"))
-
-	msg := tx.Message
-
-	// Basic message info
-	msgTable := table.NewWriter()
-	msgTable.SetTitle("Message Information")
-	msgTable.AppendRow(table.Row{"Recent Blockhash", msg.RecentBlockhash.String()})
-	msgTable.AppendRow(table.Row{"Required Signatures", msg.Header.NumRequiredSignatures})
-	msgTable.AppendRow(table.Row{"Readonly Signed", msg.Header.NumReadonlySignedAccounts})
-	msgTable.AppendRow(table.Row{"Readonly Unsigned", msg.Header.NumReadonlyUnsignedAccounts})
-	msgTable.AppendRow(table.Row{"Total Accounts", len(msg.AccountKeys)})
-	msgTable.AppendRow(table.Row{"Total Instructions", len(msg.Instructions)})
-
-	msgTable.SetStyle(table.StyleLight)
-	fmt.Println(msgTable.Render())
-
-	// Account keys
-	if len(msg.AccountKeys) > 0 {
-		f.formatAccountKeys(msg.AccountKeys)
+	doc := render.Document{render.Banner{Title: "🗂️  COMBINED PORTFOLIO", Tone: render.ToneInfo}}
+	if len(totals) == 0 {
+		doc = append(doc, render.Note{Text: "No non-zero token balances across the selected wallets.", Tone: render.ToneWarning})
+		return f.Render(render.DocumentView{Name: "combined_portfolio", Data: totals, Doc: doc})
 	}
 
-	// Instructions
-	if len(msg.Instructions) > 0 {
-		f.formatInstructions(msg.Instructions, msg.AccountKeys)
+	t := render.Table{
+		Title:  fmt.Sprintf("Holdings Across %d Wallets", len(results)),
+		Header: []string{"#", "Name", "Symbol", "Mint", "Total (UI)", "Wallets"},
+	}
+	for i, tot := range totals {
+		name, symbol := tot.Name, tot.Symbol
+		if name == "" {
+			name = "—"
+		}
+		if symbol == "" {
+			symbol = "—"
+		}
+		t.Rows = append(t.Rows, []any{i + 1, name, symbol, render.MintCell(f.cluster, f.Labels(), tot.Mint),
+			strconv.FormatFloat(tot.Total, 'f', -1, 64), tot.Wallets})
 	}
+	return f.Render(render.DocumentView{Name: "combined_portfolio", Data: totals, Doc: append(doc, t)})
 }
 
-// formatAccountKeys displays account keys used in the transaction
-func (f *TransactionFormatter) formatAccountKeys(accountKeys []solana.PublicKey) {
-	fmt.Printf("\n%s\n", text.FgGreen.Sprint("🔑 ACCOUNT KEYS"))
-
-	accountTable := table.NewWriter()
-	accountTable.SetTitle("Transaction Account Keys")
-	accountTable.AppendHeader(table.Row{"Index", "Public Key"})
-
-	maxAccounts := 5
-	if f.showFullData {
-		maxAccounts = len(accountKeys)
+// FormatAlert displays a fired alert rule as a single highlighted block
+func (f *cliFormatter) FormatAlert(a Alert) error {
+	tone, icon := render.ToneWarning, "⚠️"
+	switch a.Severity {
+	case SeverityCritical:
+		tone, icon = render.ToneNegative, "🚨"
+	case SeverityInfo:
+		tone, icon = render.ToneInfo, "ℹ️"
 	}
 
-	for i, account := range accountKeys {
-		if i >= maxAccounts {
-			break
-		}
-		accountTable.AppendRow(table.Row{i, account.String()})
+	doc := render.Document{
+		render.Banner{Title: fmt.Sprintf("%s %s: %s", icon, strings.ToUpper(string(a.Severity)), a.Rule), Tone: tone},
+		render.Field{Label: "Wallet", Value: render.Cell{Text: fmt.Sprintf("%s (%s)", a.Wallet, a.Address), Tone: render.ToneInfo}},
+		render.Field{Label: "Signature", Value: render.Cell{Text: fmt.Sprintf("%s (slot %d)", a.Signature, a.Slot)}},
+		render.Field{Label: "Message", Value: render.Cell{Text: a.Message}},
 	}
-
-	if len(accountKeys) > maxAccounts {
-		accountTable.AppendRow(table.Row{"...", fmt.Sprintf("and %d more accounts", len(accountKeys)-maxAccounts)})
+	if f.cluster != nil {
+		doc = append(doc, render.Field{Label: "Explorer", Value: render.Cell{Text: f.cluster.TxURL(a.Signature)}})
 	}
-
-	accountTable.SetStyle(table.StyleLight)
-	fmt.Println(accountTable.Render())
+	// Renderers write each view at once, so alerts from concurrent wallets
+	// do not interleave
+	return f.Render(render.DocumentView{Name: "alert", Data: a, Doc: doc})
 }
 
-// formatInstructions displays transaction instructions
-func (f *TransactionFormatter) formatInstructions(instructions []solana.CompiledInstruction, accountKeys []solana.PublicKey) {
-	fmt.Printf("\n%s\n", text.FgRed.Sprint("⚙️ INSTRUCTIONS"))
-
-	instrTable := table.NewWriter()
-	instrTable.SetTitle("Transaction Instructions")
-	instrTable.AppendHeader(table.Row{"#", "Program", "Accounts", "Data Size"})
-
-	maxInstr := 3
-	if f.showFullData {
-		maxInstr = len(instructions)
-	}
-
-	for i, instr := range instructions {
-		if i >= maxInstr {
-			break
-		}
-
-		programID := "Unknown"
-		if int(instr.ProgramIDIndex) < len(accountKeys) {
-			programID = accountKeys[instr.ProgramIDIndex].String()[:8] + "..."
-		}
-
-		accounts := fmt.Sprintf("%v", instr.Accounts)
-		if len(accounts) > 20 {
-			accounts = accounts[:17] + "..."
-		}
-
-		instrTable.AppendRow(table.Row{
-			i + 1,
-			programID,
-			accounts,
-			fmt.Sprintf("%d bytes", len(instr.Data)),
-		})
+// FormatStatusChange prints a one-line commitment update for a watched transaction
+func (f *cliFormatter) FormatStatusChange(ch StatusChange) error {
+	icon, tone := "⏳", render.ToneWarning
+	switch ch.To {
+	case StatusConfirmed:
+		icon, tone = "✅", render.TonePositive
+	case StatusFinalized:
+		icon, tone = "🔒", render.TonePositive
+	case StatusDropped:
+		icon, tone = "↩️", render.ToneNegative
 	}
-
-	if len(instructions) > maxInstr {
-		instrTable.AppendRow(table.Row{"...", fmt.Sprintf("and %d more instructions", len(instructions)-maxInstr), "", ""})
+	wallet := ""
+	if ch.Wallet.Label != "" {
+		wallet = "[" + ch.Wallet.Label + "] "
 	}
-
-	instrTable.SetStyle(table.StyleLight)
-	fmt.Println(instrTable.Render())
+	line := fmt.Sprintf("%s %s%s %s (slot %d) %s", icon, wallet, strings.ToUpper(string(ch.To)),
+		ch.Signature, ch.Slot, statusMessage(ch))
+	data := struct {
+		Wallet    string           `json:"wallet,omitempty"`
+		Address   string           `json:"address"`
+		Signature solana.Signature `json:"signature"`
+		Slot      uint64           `json:"slot"`
+		From      TxStatus         `json:"from,omitempty"`
+		To        TxStatus         `json:"to"`
+		Failed    bool             `json:"failed,omitempty"`
+		Reason    string           `json:"reason,omitempty"`
+		Time      time.Time        `json:"time"`
+	}{ch.Wallet.Label, ch.Wallet.Account.String(), ch.Signature, ch.Slot, ch.From, ch.To, ch.Failed, ch.Reason, ch.Time}
+	return f.Render(render.DocumentView{Name: "status_change", Data: data, Doc: render.Document{render.Note{Text: line, Tone: tone}}})
 }
 
-// FormatUserPortfolio displays a pretty table for a slice of token holdings.
-func (f *TransactionFormatter) FormatUserPortfolio(owner solana.PublicKey, tokens []TokenHolding) {
-	fmt.Printf("\n%s\n", text.Colors{text.BgHiBlue, text.FgBlack}.Sprint(" USER TOKEN PORTFOLIO "))
-	fmt.Printf("Owner: %s\n\n", text.Colors{text.FgHiCyan}.Sprint(owner.String()))
-
-	t := table.NewWriter()
-	t.SetTitle("SPL Token Holdings")
-	t.AppendHeader(table.Row{"#", "Name", "Symbol", "Mint", "Amount (UI)", "Decimals"})
-
-	nonZero := 0
-	for i, h := range tokens {
-		if h.UiAmount == "" || h.UiAmount == "0" || h.UiAmount == "0.0" || h.UiAmount == "0.00" {
-			continue
-		}
-		name := h.Name
-		if name == "" {
-			name = "—"
-		}
-		symbol := h.Symbol
-		if symbol == "" {
-			symbol = "—"
-		}
-		shortMint := h.Mint
-		if len(shortMint) > 16 {
-			shortMint = shortMint[:8] + "..." + shortMint[len(shortMint)-8:]
-		}
-		t.AppendRow(table.Row{i + 1, name, symbol, shortMint, h.UiAmount, h.Decimals})
-		nonZero++
+// FormatDeliveryStatus displays per-sink notification delivery counters
+func (f *cliFormatter) FormatDeliveryStatus(statuses []SinkStatus) error {
+	if len(statuses) == 0 {
+		return nil
 	}
 
-	if nonZero == 0 {
-		fmt.Println(text.Colors{text.FgHiYellow}.Sprint("No non-zero token balances found."))
-		return
+	t := render.Table{Title: "Sinks", Header: []string{"Sink", "Type", "Delivered", "Failed", "Dropped", "Retries", "Last Error"}}
+	for _, s := range statuses {
+		lastErr := s.LastError
+		if lastErr == "" {
+			lastErr = "—"
+		}
+		t.Rows = append(t.Rows, []any{s.Name, s.Type, s.Delivered, s.Failed, s.Dropped, s.Retries, lastErr})
 	}
-
-	// Modern minimal style: thin borders, subtle separators, high-contrast header
-	st := table.StyleLight
-	st.Color.Header = text.Colors{text.FgHiWhite, text.BgBlack}
-	st.Color.Row = text.Colors{text.FgHiWhite}
-	st.Color.RowAlternate = text.Colors{text.FgWhite}
-	t.SetStyle(st)
-	t.Style().Options.SeparateRows = false
-	t.Style().Options.DrawBorder = true
-	t.Style().Box = table.StyleBoxLight
-
-	fmt.Println(t.Render())
+	doc := render.Document{render.Section{Title: "📬 NOTIFICATION DELIVERY", Tone: render.ToneAccent}, t}
+	return f.Render(render.DocumentView{Name: "notification_delivery", Data: statuses, Doc: doc})
 }
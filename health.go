@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// readyTimeout bounds each readiness check.
+const readyTimeout = 3 * time.Second
+
+// readinessCheck is one dependency /readyz reports on.
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// ReadinessReport is the /readyz response body.
+type ReadinessReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// registerOpsRoutes adds the operational endpoints to mux: /metrics in the
+// Prometheus text format, /healthz (the process is up) and /readyz (every
+// check passes).
+func registerOpsRoutes(mux *http.ServeMux, checks ...readinessCheck) {
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := WriteMetrics(w); err != nil {
+			slog.Warn("Writing metrics failed", "err", err)
+		}
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		report := ReadinessReport{Status: "ready", Checks: make(map[string]string, len(checks))}
+		status := http.StatusOK
+		for _, c := range checks {
+			ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+			err := c.check(ctx)
+			cancel()
+			if err != nil {
+				report.Checks[c.name] = err.Error()
+				report.Status, status = "unavailable", http.StatusServiceUnavailable
+				continue
+			}
+			report.Checks[c.name] = "ok"
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+// rpcReadiness passes while the RPC node reports itself healthy.
+func rpcReadiness(client *rpc.Client) readinessCheck {
+	return readinessCheck{name: "rpc", check: func(ctx context.Context) error {
+		if _, err := client.GetHealth(ctx); err != nil {
+			// RPCError formats as a struct dump; the message reads better
+			var rpcErr *jsonrpc.RPCError
+			if errors.As(err, &rpcErr) {
+				return fmt.Errorf("getHealth: %s (code %d)", rpcErr.Message, rpcErr.Code)
+			}
+			return fmt.Errorf("getHealth: %w", err)
+		}
+		return nil
+	}}
+}
+
+// listenerReadiness passes while every wallet's listener has polled
+// successfully within three of its poll intervals.
+func listenerReadiness(intervals map[solana.PublicKey]time.Duration) readinessCheck {
+	return readinessCheck{name: "listeners", check: func(context.Context) error {
+		var stale []error
+		for wallet, interval := range intervals {
+			last, ok := listenerLastPoll.Value(wallet.String())
+			if !ok {
+				stale = append(stale, fmt.Errorf("%s has not polled yet", shortAddress(wallet.String())))
+				continue
+			}
+			if age := time.Since(time.Unix(int64(last), 0)); age > 3*interval {
+				stale = append(stale, fmt.Errorf("%s last polled %s ago", shortAddress(wallet.String()), age.Round(time.Second)))
+			}
+		}
+		return errors.Join(stale...)
+	}}
+}
+
+// serveOps serves the operational endpoints on addr until ctx ends, then
+// shuts the server down within the shutdown timeout.
+func serveOps(ctx context.Context, cfg *Config, addr string, checks ...readinessCheck) error {
+	mux := http.NewServeMux()
+	registerOpsRoutes(mux, checks...)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("metrics listener: %w", err)
+	}
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	slog.Info("Serving metrics and health checks", "url", "http://"+ln.Addr().String())
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := shutdownContext(ctx, cfg)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server stopped", "err", err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/classify"
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+	"go-solana-tx-explorer/store"
+)
+
+// runHistory implements `history [flags] [address]`: fetch (or load) recent
+// transactions for one wallet or a whole watchlist, optionally filter them,
+// and print the summary and fee stats per wallet plus a combined view.
+func runHistory(ctx context.Context, cfg *Config, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	wf := addWalletFlags(fs)
+	limit := fs.Int("limit", 0, "number of recent transactions to fetch per wallet (default: watchlist setting or 5)")
+	filterExpr := fs.String("filter", "", "filter expression, e.g. 'status:failed type:swap sol>=1 time>=7d'")
+	load := fs.String("load", "", "read history saved with -save instead of the RPC (a directory with -watchlist)")
+	save := fs.String("save", "", "merge fetched transactions into this history file (a directory with -watchlist)")
+	details := fs.Bool("details", false, "print the details view for every matching transaction")
+	full := fs.Bool("full", false, "show all logs, accounts and instructions in details")
+	commitment := fs.String("commitment", string(rpc.CommitmentConfirmed), "commitment level: confirmed or finalized")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	filter, err := classify.ParseFilter(*filterExpr, now(), cfg.Labels)
+	if err != nil {
+		return err
+	}
+
+	var results []WalletHistory
+	if *load != "" && *wf.watchlist == "" {
+		// A single saved file carries its own account; no address needed
+		accountTxs, err := store.LoadHistory(*load)
+		if err != nil {
+			return err
+		}
+		results = []WalletHistory{{
+			Wallet:  WatchedWallet{Account: accountTxs.Account, Label: shortAddress(accountTxs.Account.String())},
+			History: accountTxs,
+		}}
+	} else {
+		wl, wallets, err := wf.resolve(cfg, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		rpcURL, err := cfg.RequireRPCURL()
+		if err != nil {
+			return err
+		}
+		multi := *wf.watchlist != ""
+		client := NewRateLimitedClient(rpcURL, wl.RateLimit())
+		if *load == "" {
+			cfg.ResolveCluster(ctx, client)
+		}
+		service, err := fetch.NewTransactionService(client, fetch.Config{Commitment: rpc.CommitmentType(*commitment), Now: now})
+		if err != nil {
+			return err
+		}
+
+		results = make([]WalletHistory, len(wallets))
+		forEachWallet(ctx, wallets, wl.Workers(), func(ctx context.Context, i int, w WatchedWallet) {
+			settings := wl.Resolve(w)
+			if *limit > 0 {
+				settings.Limit = *limit
+			}
+			accountTxs, err := loadOrFetchHistory(ctx, service, w, settings.Limit,
+				historyPath(*load, w, multi), historyPath(*save, w, multi))
+			if err == nil {
+				accountTxs = settings.Filter.Apply(accountTxs)
+			}
+			results[i] = WalletHistory{Wallet: w, History: accountTxs, Err: err}
+		})
+	}
+
+	formatter := newFormatter(cfg, *full, cfg.Cluster)
+	multi := len(results) > 1
+	failed := 0
+	var combined []WalletHistory
+	for _, r := range results {
+		if r.Err != nil {
+			slog.Error("History failed", "wallet", r.Wallet.Account.String(), "label", r.Wallet.Label, "err", r.Err)
+			failed++
+			continue
+		}
+		r.History = filter.Apply(r.History)
+		combined = append(combined, r)
+
+		if multi {
+			if err := formatter.FormatWalletHeader(r.Wallet); err != nil {
+				return err
+			}
+		}
+		if len(r.History.Transactions) == 0 {
+			slog.Info("No transactions matched", "wallet", r.History.Account.String())
+			continue
+		}
+		if err := formatter.FormatTransactionSummary(r.History); err != nil {
+			return err
+		}
+		if err := formatter.FormatFeeStats(decode.ComputeFeeStats(r.History.Transactions)); err != nil {
+			return err
+		}
+		if *details {
+			for i, tx := range r.History.Transactions {
+				if err := formatter.FormatTransactionDetails(tx, i); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if multi {
+		if err := formatter.FormatCombinedSummary(combined); err != nil {
+			return err
+		}
+		var all []decode.TransactionInfo
+		for _, r := range combined {
+			all = append(all, r.History.Transactions...)
+		}
+		if err := formatter.FormatFeeStats(decode.ComputeFeeStats(all)); err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d wallets failed", failed, len(results))
+	}
+	return nil
+}
+
+// loadOrFetchHistory returns the stored history at loadPath when set, or
+// fetches from the RPC and merges the result into savePath when that is set.
+func loadOrFetchHistory(ctx context.Context, service *fetch.TransactionService, w WatchedWallet, limit int, loadPath, savePath string) (*fetch.AccountTransactions, error) {
+	if loadPath != "" {
+		return store.LoadHistory(loadPath)
+	}
+
+	accountTxs, err := service.FetchAccountTransactions(ctx, w.Account, limit)
+	if err != nil {
+		return nil, err
+	}
+	if savePath != "" {
+		var stored *fetch.AccountTransactions
+		if _, statErr := os.Stat(savePath); statErr == nil {
+			if stored, err = store.LoadHistory(savePath); err != nil {
+				return nil, err
+			}
+		}
+		merged, err := store.MergeHistory(stored, accountTxs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", savePath, err)
+		}
+		if err := store.SaveHistory(savePath, merged); err != nil {
+			return nil, err
+		}
+		slog.InfoContext(ctx, "Saved history", "path", savePath)
+	}
+	return accountTxs, nil
+}
+
+// historyPath maps the -load/-save argument to a wallet's history file: the
+// argument itself for a single wallet, <dir>/<address>.json for a watchlist.
+func historyPath(arg string, w WatchedWallet, multi bool) string {
+	if arg == "" || !multi {
+		return arg
+	}
+	return filepath.Join(arg, w.Account.String()+".json")
+}
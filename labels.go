@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+
+	"go-solana-tx-explorer/addressbook"
+	"go-solana-tx-explorer/render"
+)
+
+// LabelFile is the user's address book: account names by address.
+type LabelFile struct {
+	Labels map[string]string `json:"labels" yaml:"labels" toml:"labels"`
+}
+
+// LoadAddressBook returns the built-in labels plus those of the label file
+// at path, or of the first default label file when path is empty. Only an
+// explicit path must exist.
+func LoadAddressBook(path string) (*addressbook.Book, error) {
+	book := addressbook.New()
+	explicit := path != ""
+	if !explicit {
+		if path = findLabelsFile(); path == "" {
+			return book, nil
+		}
+	}
+
+	var file LabelFile
+	if err := decodeConfigFile(path, &file); err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return book, nil
+		}
+		return nil, fmt.Errorf("labels %s: %w", path, err)
+	}
+	for address, name := range file.Labels {
+		account, err := solana.PublicKeyFromBase58(strings.TrimSpace(address))
+		if err != nil {
+			return nil, fmt.Errorf("labels %s: invalid address %q", path, address)
+		}
+		book.Add(account, name, addressbook.KindUser)
+	}
+	return book, nil
+}
+
+// findLabelsFile returns the first existing labels.{yaml,yml,json,toml} in
+// the user config dir, or "".
+func findLabelsFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	for _, ext := range []string{".yaml", ".yml", ".json", ".toml"} {
+		c := filepath.Join(dir, configDirName, "labels"+ext)
+		if _, err := os.Stat(c); err == nil {
+			return c
+		}
+	}
+	return ""
+}
+
+// accountName shows address as its label, or else abbreviated when short.
+// Without short a label follows the full address instead.
+func accountName(labels *addressbook.Book, address solana.PublicKey, short bool) string {
+	name := labels.Name(address)
+	switch {
+	case short && name != "":
+		return name
+	case short:
+		return render.ShortAddress(address.String())
+	case name != "":
+		return address.String() + " (" + name + ")"
+	default:
+		return address.String()
+	}
+}
+
+// runLabels implements `labels [flags] [query]`: list the address book,
+// optionally only entries whose name or address contains query.
+func runLabels(_ context.Context, cfg *Config, args []string) error {
+	fs := flag.NewFlagSet("labels", flag.ContinueOnError)
+	kind := fs.String("kind", "", "only list one kind: user, wallet, token_account, program, sysvar or mint")
+	watchlist := fs.String("watchlist", "", "also list the wallets of this watchlist file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *watchlist != "" {
+		if _, err := LoadWatchlist(*watchlist, cfg.Labels); err != nil {
+			return err
+		}
+	}
+
+	query := strings.ToLower(fs.Arg(0))
+	entries := []addressbook.Entry{}
+	for _, e := range cfg.Labels.Entries() {
+		if *kind != "" && string(e.Kind) != *kind {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(e.Name), query) && !strings.Contains(e.Address.String(), fs.Arg(0)) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	formatter := newFormatter(cfg, false, cfg.Cluster)
+	doc := render.Document{render.Banner{Title: "📇 ADDRESS BOOK", Tone: render.ToneInfo}}
+	if len(entries) == 0 {
+		doc = append(doc, render.Note{Text: "No matching labels.", Tone: render.ToneWarning})
+	} else {
+		t := render.Table{Title: fmt.Sprintf("%d Labels", len(entries)), Header: []string{"Name", "Kind", "Address"}}
+		for _, e := range entries {
+			t.Rows = append(t.Rows, []any{e.Name, string(e.Kind), render.AccountCell(cfg.Cluster, nil, e.Address, "")})
+		}
+		doc = append(doc, t)
+	}
+	return formatter.Render(render.DocumentView{Name: "address_book", Data: entries, Doc: doc})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/fetch"
+	"go-solana-tx-explorer/render"
+)
+
+// runTx implements `tx [flags] <signature>...`: fetch one or more transactions
+// by signature and print the full details view for each.
+func runTx(ctx context.Context, cfg *Config, args []string) error {
+	fs := flag.NewFlagSet("tx", flag.ContinueOnError)
+	commitment := fs.String("commitment", string(rpc.CommitmentConfirmed), "commitment level: confirmed or finalized")
+	raw := fs.Bool("raw", false, "also print the raw getTransaction JSON")
+	full := fs.Bool("full", false, "show all logs, accounts and instructions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("at least one transaction signature is required")
+	}
+
+	signatures := make([]solana.Signature, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		sig, err := solana.SignatureFromBase58(arg)
+		if err != nil {
+			return fmt.Errorf("invalid signature %q: %w", arg, err)
+		}
+		signatures = append(signatures, sig)
+	}
+
+	client, err := cfg.NewClient()
+	if err != nil {
+		return err
+	}
+	cfg.ResolveCluster(ctx, client)
+	service, err := fetch.NewTransactionService(client, fetch.Config{Now: now})
+	if err != nil {
+		return err
+	}
+	formatter := newFormatter(cfg, *full, cfg.Cluster)
+	level := rpc.CommitmentType(*commitment)
+
+	failed := 0
+	for i, sig := range signatures {
+		txInfo, err := service.FetchTransaction(ctx, sig, level)
+		if err != nil {
+			slog.ErrorContext(ctx, "Lookup failed", "signature", sig.String(), "err", err)
+			failed++
+			continue
+		}
+		if err := formatter.FormatTransactionDetails(*txInfo, i); err != nil {
+			return err
+		}
+
+		if *raw {
+			rawJSON, err := service.FetchRawTransaction(ctx, sig, level)
+			if err != nil {
+				slog.ErrorContext(ctx, "Raw lookup failed", "signature", sig.String(), "err", err)
+				failed++
+				continue
+			}
+			var pretty bytes.Buffer
+			if err := json.Indent(&pretty, rawJSON, "", "  "); err != nil {
+				pretty.Reset()
+				pretty.Write(rawJSON)
+			}
+			err = formatter.Render(render.DocumentView{
+				Name: "raw_transaction",
+				Data: json.RawMessage(rawJSON),
+				Doc: render.Document{
+					render.Section{Title: "🧾 RAW RPC JSON", Tone: render.ToneAccent},
+					render.Code{Lang: "json", Lines: strings.Split(pretty.String(), "\n")},
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d lookups failed", failed, len(signatures))
+	}
+	return nil
+}
@@ -2,51 +2,157 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
 
-	"github.com/gagliardetto/solana-go/rpc"
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+	"go-solana-tx-explorer/portfolio"
+	"go-solana-tx-explorer/rpc/client"
 )
 
 func main() {
-	rpcURL := GetRPCURL()
-	client := rpc.New(rpcURL)
-	ctx := context.Background()
-	transactionService := NewTransactionService(client)
-	portfolioService := NewUserPortfolioService(client)
+	global := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	global.Usage = printUsage
+	configFlags := addConfigFlags(global)
+	if err := global.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
+	}
+
+	cfg, err := LoadConfig(configFlags)
+	if err != nil {
+		fatal("invalid configuration", err)
+	}
+	setupLogging(cfg)
 
-	accountsToMonitor := GetWalletAddress()
+	finish, err := startFixtures(cfg, *configFlags.record, *configFlags.replay)
+	if err != nil {
+		fatal("invalid configuration", err)
+	}
 
-	log.Println("Solana Transaction Monitor Starting...")
+	ctx, stop := signalContext(cfg.ShutdownTimeout)
+	defer stop()
 
-	account, err := GetAccountFromPublicKey(accountsToMonitor)
+	args := global.Args()
+	name, run := "monitor", runMonitor
+	if len(args) > 0 {
+		if args[0] == "help" {
+			printUsage()
+			return
+		}
+		cmd, ok := lookupCommand(args[0])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+			printUsage()
+			os.Exit(2)
+		}
+		name = cmd.name
+		run = func(ctx context.Context, cfg *Config) error { return cmd.run(ctx, cfg, args[1:]) }
+	}
+
+	err = run(ctx, cfg)
+	finish()
+	if err != nil && !errors.Is(err, flag.ErrHelp) && !stopped(ctx, err) {
+		stop()
+		fatal(name+" failed", err)
+	}
+}
+
+// stopped reports whether err only says that a signalled shutdown
+// interrupted the command, which counts as a clean exit.
+func stopped(ctx context.Context, err error) bool {
+	return ctx.Err() != nil && errors.Is(err, context.Canceled)
+}
+
+// runMonitor is the default mode: fetch recent history and the token
+// portfolio for the configured wallet, then keep listening for new
+// transactions until ctx ends.
+func runMonitor(ctx context.Context, cfg *Config) error {
+	rpcClient, err := cfg.NewClient()
+	if err != nil {
+		return err
+	}
+	account, err := cfg.RequireWallet()
 	if err != nil {
-		log.Printf("Invalid account address %s: %v", accountsToMonitor, err)
+		return err
 	}
+	wsURL, err := cfg.RequireWSURL()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Solana transaction monitor starting", "wallet", account.String())
+	cfg.ResolveCluster(ctx, rpcClient)
+	if cfg.Profile != "" {
+		slog.Info("Using profile", "profile", cfg.Profile, "cluster", cfg.Cluster.Name, "endpoint", client.EndpointHost(cfg.RPCURL))
+	}
+	ctx = withLogAttrs(ctx, "wallet", account.String())
+
+	transactionService, err := fetch.NewTransactionService(rpcClient, fetch.Config{Now: now})
+	if err != nil {
+		return err
+	}
+	portfolioService := portfolio.NewUserPortfolioService(rpcClient, portfolio.Config{Cluster: cfg.Cluster.Name, Registry: tokenRegistry})
 
 	accountTxs, err := transactionService.FetchAccountTransactions(ctx, account, TRANSACTIONS_LIMIT)
 	if err != nil {
-		log.Printf("Error fetching transactions for account %s: %v", account.String(), err)
+		slog.ErrorContext(ctx, "Fetching transactions failed", "err", err)
 	}
 
-	if len(accountTxs.Transactions) > 0 {
-		transactionService.AnalyzeTransactions(accountTxs)
+	formatter := newFormatter(cfg, false, cfg.Cluster)
+	if accountTxs != nil && len(accountTxs.Transactions) > 0 {
+		if err := analyzeTransactions(formatter, accountTxs); err != nil {
+			return err
+		}
 	} else {
-		log.Printf("No recent transactions found for account: %s", account.String())
+		slog.InfoContext(ctx, "No recent transactions found")
 	}
 
-	if err := portfolioService.PrintUserTokens(ctx, account); err != nil {
-		log.Printf("Error printing user tokens: %v", err)
+	holdings, err := portfolioService.FetchUserTokens(ctx, account)
+	if err != nil {
+		slog.ErrorContext(ctx, "Printing user tokens failed", "err", err)
+	} else if err := formatter.FormatUserPortfolio(account, holdings); err != nil {
+		return err
 	}
 
-	// Start a WS listener to stream new transactions mentioning the wallet.
-	// Uses WS_URL if set; otherwise derives from RPC_URL.
-	go func() {
-		wsURL := GetWSURL()
-		if err := ListenWalletTransactions(ctx, wsURL, account); err != nil {
-			log.Printf("WS listener error: %v", err)
-		}
-	}()
+	// Stream new transactions mentioning the wallet until shutdown. Uses the
+	// configured WS endpoint; otherwise derives it from the RPC one.
+	if err := ListenWalletTransactions(ctx, wsURL, account); err != nil {
+		return fmt.Errorf("WS listener error: %w", err)
+	}
+	return nil
+}
 
-	// Keep the process alive briefly so we can observe events in this demo.
-	select {}
+// analyzeTransactions prints the monitor's overview of accountTxs: the
+// summary table, fee statistics and every transaction in detail.
+func analyzeTransactions(formatter *cliFormatter, accountTxs *fetch.AccountTransactions) error {
+	if err := formatter.FormatTransactionSummary(accountTxs); err != nil {
+		return err
+	}
+
+	// Aggregate fee and compute budget usage across the fetched history
+	if err := formatter.FormatFeeStats(decode.ComputeFeeStats(accountTxs.Transactions)); err != nil {
+		return err
+	}
+
+	// Point the user at the single-transaction lookup
+	formatter.FormatHint(
+		"\n💡 To see detailed information for a specific transaction, run:",
+		"   go run . tx <signature>",
+		fmt.Sprintf("   or browse them all with: go run . tui %s\n", accountTxs.Account),
+	)
+
+	for index, tx := range accountTxs.Transactions {
+		formatter.FormatHint(fmt.Sprintf("\n📋 Showing detailed view of the transaction %d as example:", index+1))
+		if err := formatter.FormatTransactionDetails(tx, index); err != nil {
+			return err
+		}
+	}
+	return nil
 }
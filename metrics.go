@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A minimal Prometheus registry: enough for counters, gauges and histograms
+// with labels in the text exposition format, without pulling in the client
+// library and its dependency tree.
+
+type metricKind string
+
+const (
+	kindCounter   metricKind = "counter"
+	kindGauge     metricKind = "gauge"
+	kindHistogram metricKind = "histogram"
+)
+
+// metricVec is one metric family. Series are keyed by their label values.
+type metricVec struct {
+	name    string
+	help    string
+	kind    metricKind
+	labels  []string
+	buckets []float64
+	// collect, when set, replaces the stored series at scrape time
+	collect func(set func(v float64, labelValues ...string))
+
+	mu     sync.Mutex
+	series map[string]*metricSeries
+}
+
+type metricSeries struct {
+	labelValues []string
+	value       float64
+	counts      []uint64 // observations <= each bucket bound
+	sum         float64
+	count       uint64
+}
+
+var (
+	metricsMu       sync.Mutex
+	metricsRegistry []*metricVec
+)
+
+func register(m *metricVec) *metricVec {
+	m.series = make(map[string]*metricSeries)
+	metricsMu.Lock()
+	metricsRegistry = append(metricsRegistry, m)
+	metricsMu.Unlock()
+	return m
+}
+
+func newCounter(name, help string, labels ...string) *metricVec {
+	return register(&metricVec{name: name, help: help, kind: kindCounter, labels: labels})
+}
+
+func newGauge(name, help string, labels ...string) *metricVec {
+	return register(&metricVec{name: name, help: help, kind: kindGauge, labels: labels})
+}
+
+func newHistogram(name, help string, buckets []float64, labels ...string) *metricVec {
+	return register(&metricVec{name: name, help: help, kind: kindHistogram, labels: labels, buckets: buckets})
+}
+
+// newGaugeFunc registers a gauge whose series are produced by collect on
+// every scrape.
+func newGaugeFunc(name, help string, collect func(set func(v float64, labelValues ...string)), labels ...string) *metricVec {
+	return register(&metricVec{name: name, help: help, kind: kindGauge, labels: labels, collect: collect})
+}
+
+func (m *metricVec) seriesLocked(labelValues []string) *metricSeries {
+	if len(labelValues) != len(m.labels) {
+		panic(fmt.Sprintf("metric %s: got %d label values, want %d", m.name, len(labelValues), len(m.labels)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := m.series[key]
+	if !ok {
+		s = &metricSeries{labelValues: append([]string(nil), labelValues...)}
+		if m.kind == kindHistogram {
+			s.counts = make([]uint64, len(m.buckets))
+		}
+		m.series[key] = s
+	}
+	return s
+}
+
+// Inc adds one to a counter.
+func (m *metricVec) Inc(labelValues ...string) { m.Add(1, labelValues...) }
+
+// Add adds v to a counter or gauge.
+func (m *metricVec) Add(v float64, labelValues ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seriesLocked(labelValues).value += v
+}
+
+// Set sets a gauge.
+func (m *metricVec) Set(v float64, labelValues ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seriesLocked(labelValues).value = v
+}
+
+// Observe records v in a histogram.
+func (m *metricVec) Observe(v float64, labelValues ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.seriesLocked(labelValues)
+	for i, b := range m.buckets {
+		if v <= b {
+			s.counts[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+// Value returns the current value of a counter or gauge series.
+func (m *metricVec) Value(labelValues ...string) (float64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.series[strings.Join(labelValues, "\xff")]
+	if !ok {
+		return 0, false
+	}
+	return s.value, true
+}
+
+// WriteMetrics writes every registered metric in the Prometheus text format.
+func WriteMetrics(w io.Writer) error {
+	metricsMu.Lock()
+	families := append([]*metricVec(nil), metricsRegistry...)
+	metricsMu.Unlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	var b strings.Builder
+	for _, m := range families {
+		m.write(&b)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (m *metricVec) write(b *strings.Builder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.collect != nil {
+		m.series = make(map[string]*metricSeries)
+		m.collect(func(v float64, labelValues ...string) {
+			m.seriesLocked(labelValues).value = v
+		})
+	}
+
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+	keys := make([]string, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := m.series[k]
+		if m.kind != kindHistogram {
+			fmt.Fprintf(b, "%s%s %s\n", m.name, formatLabels(m.labels, s.labelValues, "", ""), formatFloat(s.value))
+			continue
+		}
+		for i, bound := range m.buckets {
+			fmt.Fprintf(b, "%s_bucket%s %d\n", m.name, formatLabels(m.labels, s.labelValues, "le", formatFloat(bound)), s.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", m.name, formatLabels(m.labels, s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", m.name, formatLabels(m.labels, s.labelValues, "", ""), formatFloat(s.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", m.name, formatLabels(m.labels, s.labelValues, "", ""), s.count)
+	}
+}
+
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	parts := make([]string, 0, len(names)+1)
+	for i, n := range names {
+		parts = append(parts, n+"="+quoteLabelValue(values[i]))
+	}
+	if extraName != "" {
+		parts = append(parts, extraName+"="+quoteLabelValue(extraValue))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// labelValueEscaper applies the exposition format's only escapes; any other
+// byte, UTF-8 included, is written as is.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabelValue(v string) string {
+	return `"` + labelValueEscaper.Replace(v) + `"`
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// latencyBuckets suit RPC round trips, in seconds.
+var latencyBuckets = []float64{0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics exported on /metrics by watch and serve.
+var (
+	rpcRequestsTotal = newCounter("explorer_rpc_requests_total",
+		"JSON-RPC calls by method, endpoint host and result (ok, error, rate_limited).", "method", "endpoint", "result")
+	rpcRequestDuration = newHistogram("explorer_rpc_request_duration_seconds",
+		"JSON-RPC call latency, excluding time spent waiting on the rate limiter.", latencyBuckets, "method", "endpoint")
+
+	transactionsFetchedTotal = newCounter("explorer_transactions_fetched_total",
+		"Transactions fetched with getTransaction, by result (ok, failed).", "result")
+
+	listenerLagSlots = newGauge("explorer_listener_lag_slots",
+		"Slots between the cluster tip and the newest transaction when the listener observed it.", "wallet")
+	listenerLagSeconds = newGauge("explorer_listener_lag_seconds",
+		"Seconds between a transaction's block time and the listener observing it.", "wallet")
+	listenerLastPoll = newGauge("explorer_listener_last_poll_timestamp_seconds",
+		"Unix time of the listener's last successful poll.", "wallet")
+
+	alertsFiredTotal = newCounter("explorer_alerts_fired_total",
+		"Alerts raised by the rules engine.", "rule", "severity")
+	notificationsTotal = newCounter("explorer_notifications_total",
+		"Notification deliveries by sink and result (delivered, failed, dropped).", "sink", "type", "result")
+	notificationRetriesTotal = newCounter("explorer_notification_retries_total",
+		"Notification delivery attempts beyond the first.", "sink")
+
+	streamSubscribers = newGauge("explorer_stream_subscribers",
+		"Clients connected to the live SSE and WebSocket streams.")
+)
+
+// Registry size and age are read from the token registry at scrape time.
+var (
+	_ = newGaugeFunc("explorer_token_registry_tokens", "Tokens in the loaded registry.",
+		func(set func(float64, ...string)) {
+			for _, st := range tokenRegistry.Stats() {
+				set(float64(st.Tokens), st.Cluster)
+			}
+		}, "cluster")
+	_ = newGaugeFunc("explorer_token_registry_age_seconds", "Seconds since the registry was loaded.",
+		func(set func(float64, ...string)) {
+			for _, st := range tokenRegistry.Stats() {
+				set(time.Since(st.LoadedAt).Seconds(), st.Cluster)
+			}
+		}, "cluster")
+)
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestFormatLabelsEscapesOnlyExpositionSpecials(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"hot", `{wallet="hot"}`},
+		{`C:\keys "main"`, `{wallet="C:\\keys \"main\""}`},
+		{"line\nbreak", `{wallet="line\nbreak"}`},
+		// Go escapes these; the exposition format takes them verbatim
+		{"trésor 💰\ttab", "{wallet=\"trésor 💰\ttab\"}"},
+	}
+	for _, tt := range tests {
+		if got := formatLabels([]string{"wallet"}, []string{tt.value}, "", ""); got != tt.want {
+			t.Errorf("formatLabels(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+	if got, want := formatLabels(nil, nil, "le", "+Inf"), `{le="+Inf"}`; got != want {
+		t.Errorf("formatLabels(le) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,737 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+)
+
+// Mock chain defaults.
+const (
+	defaultMockStartSlot = 250_000_000
+	minMockStartSlot     = 1_000_000
+	defaultMockSlotTime  = 400 * time.Millisecond
+	// mockFinalizedDepth is how many slots a transaction needs to finalize.
+	mockFinalizedDepth = 32
+	mockFee            = 5000
+	// Rent-exempt minimums for token accounts and mints.
+	mockTokenAccountRent = 2_039_280
+	mockMintRent         = 1_461_600
+)
+
+// MockScenario describes the synthetic chain state served by the mock RPC
+// server: wallets with opening balances, the tokens they hold, generated
+// past history and recurring live activity.
+type MockScenario struct {
+	// Seed makes keys, signatures and generated history reproducible.
+	Seed      int64  `json:"seed,omitempty" yaml:"seed,omitempty" toml:"seed,omitempty"`
+	StartSlot uint64 `json:"start_slot,omitempty" yaml:"start_slot,omitempty" toml:"start_slot,omitempty"`
+	// SlotTime is a Go duration; slots advance at this pace.
+	SlotTime string         `json:"slot_time,omitempty" yaml:"slot_time,omitempty" toml:"slot_time,omitempty"`
+	Tokens   []MockToken    `json:"tokens,omitempty" yaml:"tokens,omitempty" toml:"tokens,omitempty"`
+	Wallets  []MockWallet   `json:"wallets" yaml:"wallets" toml:"wallets"`
+	Activity []MockActivity `json:"activity,omitempty" yaml:"activity,omitempty" toml:"activity,omitempty"`
+}
+
+// MockToken is an SPL mint. Without a mint address one is derived from the
+// seed and symbol.
+type MockToken struct {
+	Symbol   string `json:"symbol" yaml:"symbol" toml:"symbol"`
+	Mint     string `json:"mint,omitempty" yaml:"mint,omitempty" toml:"mint,omitempty"`
+	Decimals uint8  `json:"decimals" yaml:"decimals" toml:"decimals"`
+}
+
+// MockWallet is a funded wallet. Its key is derived from the seed and name
+// unless Address is set, in which case its transactions carry synthetic
+// signatures.
+type MockWallet struct {
+	Name    string `json:"name" yaml:"name" toml:"name"`
+	Address string `json:"address,omitempty" yaml:"address,omitempty" toml:"address,omitempty"`
+	// SOL and Tokens (symbol to amount) are opening balances, before History.
+	SOL    float64            `json:"sol,omitempty" yaml:"sol,omitempty" toml:"sol,omitempty"`
+	Tokens map[string]float64 `json:"tokens,omitempty" yaml:"tokens,omitempty" toml:"tokens,omitempty"`
+	// History is how many past transfers with the other wallets to generate.
+	History int `json:"history,omitempty" yaml:"history,omitempty" toml:"history,omitempty"`
+}
+
+// MockActivity is a transfer repeated while the server runs. From and To are
+// wallet names or addresses. It moves SOL, or Amount of Token when Token is
+// set. A transfer the sender cannot fund fails on chain, as does every one
+// with Fail set.
+type MockActivity struct {
+	From   string  `json:"from" yaml:"from" toml:"from"`
+	To     string  `json:"to" yaml:"to" toml:"to"`
+	SOL    float64 `json:"sol,omitempty" yaml:"sol,omitempty" toml:"sol,omitempty"`
+	Token  string  `json:"token,omitempty" yaml:"token,omitempty" toml:"token,omitempty"`
+	Amount float64 `json:"amount,omitempty" yaml:"amount,omitempty" toml:"amount,omitempty"`
+	Every  string  `json:"every" yaml:"every" toml:"every"`
+	// Count stops the activity after that many transfers; 0 repeats forever.
+	Count int  `json:"count,omitempty" yaml:"count,omitempty" toml:"count,omitempty"`
+	Fail  bool `json:"fail,omitempty" yaml:"fail,omitempty" toml:"fail,omitempty"`
+}
+
+// DefaultMockScenario is used when no scenario file is given.
+func DefaultMockScenario() *MockScenario {
+	return &MockScenario{
+		Seed:   1,
+		Tokens: []MockToken{{Symbol: "USDC", Decimals: 6}, {Symbol: "BONK", Decimals: 5}},
+		Wallets: []MockWallet{
+			{Name: "alice", SOL: 25, Tokens: map[string]float64{"USDC": 1500, "BONK": 2_000_000}, History: 15},
+			{Name: "bob", SOL: 4, Tokens: map[string]float64{"USDC": 80}, History: 5},
+			{Name: "carol", SOL: 0.5},
+		},
+		Activity: []MockActivity{
+			{From: "alice", To: "bob", SOL: 0.25, Every: "15s"},
+			{From: "bob", To: "alice", Token: "USDC", Amount: 12.5, Every: "40s"},
+			{From: "carol", To: "alice", SOL: 2, Every: "90s"},
+		},
+	}
+}
+
+// LoadMockScenario reads a scenario file (.yaml, .json or .toml).
+func LoadMockScenario(path string) (*MockScenario, error) {
+	var s MockScenario
+	if err := decodeConfigFile(path, &s); err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// mockAccount is one account's state. Token accounts also have a mint,
+// owner and raw token amount.
+type mockAccount struct {
+	lamports    uint64
+	owner       solana.PublicKey
+	mint        solana.PublicKey
+	tokenOwner  solana.PublicKey
+	tokenAmount uint64
+	isToken     bool
+}
+
+// mockTx is a transaction landed on the mock chain.
+type mockTx struct {
+	signature solana.Signature
+	slot      uint64
+	blockTime int64
+	tx        *solana.Transaction
+	meta      mockMeta
+	mentions  []solana.PublicKey
+}
+
+func (t *mockTx) failed() bool { return t.meta.Err != nil }
+
+// mockMeta mirrors getTransaction's meta object.
+type mockMeta struct {
+	Err                  any                `json:"err"`
+	Status               map[string]any     `json:"status"`
+	Fee                  uint64             `json:"fee"`
+	PreBalances          []uint64           `json:"preBalances"`
+	PostBalances         []uint64           `json:"postBalances"`
+	InnerInstructions    []any              `json:"innerInstructions"`
+	PreTokenBalances     []mockTokenBalance `json:"preTokenBalances"`
+	PostTokenBalances    []mockTokenBalance `json:"postTokenBalances"`
+	LogMessages          []string           `json:"logMessages"`
+	Rewards              []any              `json:"rewards"`
+	LoadedAddresses      map[string][]any   `json:"loadedAddresses"`
+	ComputeUnitsConsumed uint64             `json:"computeUnitsConsumed"`
+}
+
+type mockTokenBalance struct {
+	AccountIndex  int              `json:"accountIndex"`
+	Mint          solana.PublicKey `json:"mint"`
+	Owner         solana.PublicKey `json:"owner"`
+	ProgramID     solana.PublicKey `json:"programId"`
+	UITokenAmount mockTokenAmount  `json:"uiTokenAmount"`
+}
+
+type mockTokenAmount struct {
+	Amount         string  `json:"amount"`
+	Decimals       uint8   `json:"decimals"`
+	UIAmount       float64 `json:"uiAmount"`
+	UIAmountString string  `json:"uiAmountString"`
+}
+
+func newMockTokenAmount(raw uint64, decimals uint8) mockTokenAmount {
+	ui := float64(raw) / math.Pow10(int(decimals))
+	return mockTokenAmount{
+		Amount:         strconv.FormatUint(raw, 10),
+		Decimals:       decimals,
+		UIAmount:       ui,
+		UIAmountString: strconv.FormatFloat(ui, 'f', -1, 64),
+	}
+}
+
+// mockTransfer is one SOL or token transfer to land on the chain.
+type mockTransfer struct {
+	from, to solana.PublicKey
+	lamports uint64
+	mint     *MockToken
+	amount   uint64 // raw token units
+	fail     bool
+}
+
+type resolvedToken struct {
+	MockToken
+	key solana.PublicKey
+}
+
+// MockChain is an in-memory chain: accounts, landed transactions and a slot
+// clock. It is safe for concurrent use.
+type MockChain struct {
+	scenario *MockScenario
+	slotTime time.Duration
+	genesis  solana.Hash
+
+	mu       sync.Mutex
+	slot     uint64
+	start    time.Time
+	startAt  uint64
+	keys     map[solana.PublicKey]solana.PrivateKey
+	names    map[string]solana.PublicKey
+	wallets  []solana.PublicKey
+	tokens   map[string]*resolvedToken
+	accounts map[solana.PublicKey]*mockAccount
+	txs      map[solana.Signature]*mockTx
+	bySlot   map[uint64][]*mockTx
+	byAddr   map[solana.PublicKey][]*mockTx // oldest first
+	seq      uint64
+	rng      *rand.Rand
+
+	listeners []mockListener
+}
+
+// mockListener is told about every new transaction and slot.
+type mockListener interface {
+	onTransaction(tx *mockTx)
+	onSlot(slot uint64)
+}
+
+// NewMockChain validates s and builds its opening state and history.
+func NewMockChain(s *MockScenario) (*MockChain, error) {
+	c := &MockChain{
+		scenario: s,
+		slotTime: defaultMockSlotTime,
+		slot:     s.StartSlot,
+		start:    now(),
+		keys:     make(map[solana.PublicKey]solana.PrivateKey),
+		names:    make(map[string]solana.PublicKey),
+		tokens:   make(map[string]*resolvedToken),
+		accounts: make(map[solana.PublicKey]*mockAccount),
+		txs:      make(map[solana.Signature]*mockTx),
+		bySlot:   make(map[uint64][]*mockTx),
+		byAddr:   make(map[solana.PublicKey][]*mockTx),
+		rng:      rand.New(rand.NewSource(s.Seed)),
+	}
+	if c.slot == 0 {
+		c.slot = defaultMockStartSlot
+	}
+	c.startAt = c.slot
+	c.genesis = solana.Hash(sha256.Sum256([]byte(fmt.Sprintf("mock-genesis/%d", s.Seed))))
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	c.generateHistory()
+	return c, nil
+}
+
+func (c *MockChain) load() error {
+	s := c.scenario
+	var errs []error
+	if s.StartSlot != 0 && s.StartSlot < minMockStartSlot {
+		errs = append(errs, fmt.Errorf("start_slot: must be at least %d to leave room for generated history", minMockStartSlot))
+	}
+	if s.SlotTime != "" {
+		d, err := time.ParseDuration(s.SlotTime)
+		if err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("slot_time: invalid duration %q", s.SlotTime))
+		} else {
+			c.slotTime = d
+		}
+	}
+	for _, t := range s.Tokens {
+		if t.Symbol == "" {
+			errs = append(errs, errors.New("tokens: symbol is required"))
+			continue
+		}
+		rt := &resolvedToken{MockToken: t}
+		if t.Mint != "" {
+			pk, err := solana.PublicKeyFromBase58(t.Mint)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("token %s: invalid mint %q", t.Symbol, t.Mint))
+				continue
+			}
+			rt.key = pk
+		} else {
+			rt.key = c.deriveKey("mint/" + t.Symbol).PublicKey()
+		}
+		c.tokens[strings.ToUpper(t.Symbol)] = rt
+		c.accounts[rt.key] = &mockAccount{lamports: mockMintRent, owner: solana.TokenProgramID}
+	}
+	if len(s.Wallets) == 0 {
+		errs = append(errs, errors.New("no wallets defined"))
+	}
+	for i, w := range s.Wallets {
+		if w.Name == "" {
+			errs = append(errs, fmt.Errorf("wallets[%d]: name is required", i))
+			continue
+		}
+		var pk solana.PublicKey
+		if w.Address != "" {
+			var err error
+			if pk, err = solana.PublicKeyFromBase58(w.Address); err != nil {
+				errs = append(errs, fmt.Errorf("wallet %s: invalid address %q", w.Name, w.Address))
+				continue
+			}
+		} else {
+			key := c.deriveKey("wallet/" + w.Name)
+			pk = key.PublicKey()
+			c.keys[pk] = key
+		}
+		c.names[w.Name] = pk
+		c.wallets = append(c.wallets, pk)
+		c.account(pk).lamports += solToLamports(w.SOL)
+		for sym, amount := range w.Tokens {
+			t, ok := c.tokens[strings.ToUpper(sym)]
+			if !ok {
+				errs = append(errs, fmt.Errorf("wallet %s: unknown token %q", w.Name, sym))
+				continue
+			}
+			c.tokenAccount(pk, t).tokenAmount += toRawAmount(amount, t.Decimals)
+		}
+	}
+	for i, a := range s.Activity {
+		if _, err := c.activityTransfer(a); err != nil {
+			errs = append(errs, fmt.Errorf("activity[%d]: %w", i, err))
+		}
+		if d, err := time.ParseDuration(a.Every); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("activity[%d]: every: invalid duration %q", i, a.Every))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deriveKey returns a key that depends only on the seed and name.
+func (c *MockChain) deriveKey(name string) solana.PrivateKey {
+	seed := sha256.Sum256([]byte(fmt.Sprintf("%d/%s", c.scenario.Seed, name)))
+	return solana.PrivateKey(ed25519.NewKeyFromSeed(seed[:]))
+}
+
+func (c *MockChain) account(pk solana.PublicKey) *mockAccount {
+	a, ok := c.accounts[pk]
+	if !ok {
+		a = &mockAccount{owner: solana.SystemProgramID}
+		c.accounts[pk] = a
+	}
+	return a
+}
+
+// tokenAccount returns owner's associated token account for t, creating it.
+func (c *MockChain) tokenAccount(owner solana.PublicKey, t *resolvedToken) *mockAccount {
+	ata, _, _ := solana.FindAssociatedTokenAddress(owner, t.key)
+	a, ok := c.accounts[ata]
+	if !ok {
+		a = &mockAccount{lamports: mockTokenAccountRent, owner: solana.TokenProgramID, mint: t.key, tokenOwner: owner, isToken: true}
+		c.accounts[ata] = a
+	}
+	return a
+}
+
+func (c *MockChain) resolveParty(s string) (solana.PublicKey, error) {
+	if pk, ok := c.names[s]; ok {
+		return pk, nil
+	}
+	pk, err := solana.PublicKeyFromBase58(s)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("%q is neither a wallet name nor an address", s)
+	}
+	return pk, nil
+}
+
+func (c *MockChain) activityTransfer(a MockActivity) (mockTransfer, error) {
+	from, err := c.resolveParty(a.From)
+	if err != nil {
+		return mockTransfer{}, fmt.Errorf("from: %w", err)
+	}
+	to, err := c.resolveParty(a.To)
+	if err != nil {
+		return mockTransfer{}, fmt.Errorf("to: %w", err)
+	}
+	tr := mockTransfer{from: from, to: to, fail: a.Fail}
+	if a.Token == "" {
+		if a.SOL <= 0 {
+			return mockTransfer{}, errors.New("sol or token and amount is required")
+		}
+		tr.lamports = solToLamports(a.SOL)
+		return tr, nil
+	}
+	t, ok := c.tokens[strings.ToUpper(a.Token)]
+	if !ok {
+		return mockTransfer{}, fmt.Errorf("unknown token %q", a.Token)
+	}
+	if a.Amount <= 0 {
+		return mockTransfer{}, errors.New("amount must be positive")
+	}
+	tr.mint, tr.amount = &t.MockToken, toRawAmount(a.Amount, t.Decimals)
+	return tr, nil
+}
+
+// generateHistory lands each wallet's past transfers before the start slot,
+// roughly a minute apart, applying them to the opening balances.
+func (c *MockChain) generateHistory() {
+	var planned []mockTransfer
+	for _, w := range c.scenario.Wallets {
+		self := c.names[w.Name]
+		for i := 0; i < w.History; i++ {
+			planned = append(planned, c.randomTransfer(self))
+		}
+	}
+	c.rng.Shuffle(len(planned), func(i, j int) { planned[i], planned[j] = planned[j], planned[i] })
+
+	const gap = 150 // slots, about a minute
+	slot := c.slot - uint64(len(planned)+1)*gap
+	for _, tr := range planned {
+		c.landLocked(tr, slot)
+		slot += gap
+	}
+}
+
+// randomTransfer picks a counterparty, direction, asset and amount for
+// generated history. About one in ten fails.
+func (c *MockChain) randomTransfer(self solana.PublicKey) mockTransfer {
+	var peer solana.PublicKey
+	if len(c.wallets) > 1 {
+		for peer = self; peer == self; {
+			peer = c.wallets[c.rng.Intn(len(c.wallets))]
+		}
+	} else {
+		peer = c.deriveKey(fmt.Sprintf("peer/%d", c.rng.Intn(5))).PublicKey()
+	}
+	from, to := self, peer
+	if c.rng.Intn(2) == 0 {
+		from, to = peer, self
+	}
+	tr := mockTransfer{from: from, to: to, fail: c.rng.Intn(10) == 0}
+
+	// Move tokens the sender holds a third of the time
+	var held []*resolvedToken
+	for _, t := range c.sortedTokens() {
+		ata, _, _ := solana.FindAssociatedTokenAddress(from, t.key)
+		if a, ok := c.accounts[ata]; ok && a.tokenAmount > 0 {
+			held = append(held, t)
+		}
+	}
+	if len(held) > 0 && c.rng.Intn(3) == 0 {
+		t := held[c.rng.Intn(len(held))]
+		ata, _, _ := solana.FindAssociatedTokenAddress(from, t.key)
+		tr.mint = &t.MockToken
+		tr.amount = uint64(float64(c.accounts[ata].tokenAmount) * (0.01 + 0.2*c.rng.Float64()))
+		return tr
+	}
+	balance := c.account(from).lamports
+	tr.lamports = uint64(float64(balance) * (0.01 + 0.1*c.rng.Float64()))
+	if tr.lamports == 0 {
+		tr.lamports = solana.LAMPORTS_PER_SOL / 100
+	}
+	return tr
+}
+
+func (c *MockChain) sortedTokens() []*resolvedToken {
+	out := make([]*resolvedToken, 0, len(c.tokens))
+	for _, t := range c.tokens {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Symbol < out[j].Symbol })
+	return out
+}
+
+func (c *MockChain) tokenByMint(mint solana.PublicKey) *resolvedToken {
+	for _, t := range c.tokens {
+		if t.key == mint {
+			return t
+		}
+	}
+	return nil
+}
+
+// mockBlockhash is the blockhash of slot.
+func mockBlockhash(slot uint64) solana.Hash {
+	return solana.Hash(sha256.Sum256(binary.BigEndian.AppendUint64([]byte("blockhash"), slot)))
+}
+
+// landLocked builds, signs and applies tr at slot.
+func (c *MockChain) landLocked(tr mockTransfer, slot uint64) *mockTx {
+	c.seq++
+	blockhash := mockBlockhash(slot)
+
+	var ix solana.Instruction
+	var srcATA, dstATA solana.PublicKey
+	var t *resolvedToken
+	if tr.mint != nil {
+		t = c.tokens[strings.ToUpper(tr.mint.Symbol)]
+		srcATA, _, _ = solana.FindAssociatedTokenAddress(tr.from, t.key)
+		dstATA, _, _ = solana.FindAssociatedTokenAddress(tr.to, t.key)
+		c.tokenAccount(tr.from, t)
+		c.tokenAccount(tr.to, t)
+		ix = token.NewTransferCheckedInstruction(tr.amount, t.Decimals, srcATA, t.key, dstATA, tr.from, nil).Build()
+	} else {
+		c.account(tr.to)
+		ix = system.NewTransferInstruction(tr.lamports, tr.from, tr.to).Build()
+	}
+	tx, err := solana.NewTransaction([]solana.Instruction{ix}, blockhash, solana.TransactionPayer(tr.from))
+	if err != nil {
+		// The instructions are built above from valid keys; this cannot fail
+		panic(fmt.Sprintf("mock transaction: %v", err))
+	}
+	c.sign(tx)
+
+	keys := tx.Message.AccountKeys
+	meta := mockMeta{
+		Status:            map[string]any{"Ok": nil},
+		Fee:               mockFee,
+		InnerInstructions: []any{},
+		PreTokenBalances:  []mockTokenBalance{},
+		PostTokenBalances: []mockTokenBalance{},
+		Rewards:           []any{},
+		LoadedAddresses:   map[string][]any{"writable": {}, "readonly": {}},
+	}
+	for _, k := range keys {
+		meta.PreBalances = append(meta.PreBalances, c.account(k).lamports)
+	}
+	tokenIndexes := []int{}
+	if t != nil {
+		for i, k := range keys {
+			if k == srcATA || k == dstATA {
+				tokenIndexes = append(tokenIndexes, i)
+			}
+		}
+		meta.PreTokenBalances = c.tokenBalances(keys, tokenIndexes, t)
+	}
+
+	payer := c.account(tr.from)
+	payer.lamports -= min(payer.lamports, mockFee)
+	if t != nil {
+		src := c.accounts[srcATA]
+		meta.LogMessages = []string{
+			"Program " + solana.TokenProgramID.String() + " invoke [1]",
+			"Program log: Instruction: TransferChecked",
+		}
+		meta.ComputeUnitsConsumed = 6200
+		if tr.fail || src.tokenAmount < tr.amount {
+			meta.LogMessages = append(meta.LogMessages,
+				"Program log: Error: insufficient funds",
+				"Program "+solana.TokenProgramID.String()+" consumed 4100 of 200000 compute units",
+				"Program "+solana.TokenProgramID.String()+" failed: custom program error: 0x1")
+			meta.ComputeUnitsConsumed = 4100
+			meta.Err = map[string]any{"InstructionError": []any{0, map[string]any{"Custom": 1}}}
+		} else {
+			src.tokenAmount -= tr.amount
+			c.accounts[dstATA].tokenAmount += tr.amount
+			meta.LogMessages = append(meta.LogMessages,
+				"Program "+solana.TokenProgramID.String()+" consumed 6200 of 200000 compute units",
+				"Program "+solana.TokenProgramID.String()+" success")
+		}
+		meta.PostTokenBalances = c.tokenBalances(keys, tokenIndexes, t)
+	} else {
+		meta.LogMessages = []string{"Program " + solana.SystemProgramID.String() + " invoke [1]"}
+		meta.ComputeUnitsConsumed = 150
+		if tr.fail || payer.lamports < tr.lamports {
+			meta.LogMessages = append(meta.LogMessages,
+				fmt.Sprintf("Transfer: insufficient lamports %d, need %d", payer.lamports, tr.lamports),
+				"Program "+solana.SystemProgramID.String()+" failed: custom program error: 0x1")
+			meta.Err = map[string]any{"InstructionError": []any{0, map[string]any{"Custom": 1}}}
+		} else {
+			payer.lamports -= tr.lamports
+			c.account(tr.to).lamports += tr.lamports
+			meta.LogMessages = append(meta.LogMessages, "Program "+solana.SystemProgramID.String()+" success")
+		}
+	}
+	if meta.Err != nil {
+		meta.Status = map[string]any{"Err": meta.Err}
+	}
+	for _, k := range keys {
+		meta.PostBalances = append(meta.PostBalances, c.account(k).lamports)
+	}
+
+	mtx := &mockTx{
+		signature: tx.Signatures[0],
+		slot:      slot,
+		blockTime: c.blockTime(slot),
+		tx:        tx,
+		meta:      meta,
+		mentions:  keys,
+	}
+	if t != nil {
+		// Token transfers show up in the owners' histories too
+		mtx.mentions = append(append([]solana.PublicKey(nil), keys...), tr.to)
+	}
+	c.txs[mtx.signature] = mtx
+	c.bySlot[slot] = append(c.bySlot[slot], mtx)
+	seen := make(map[solana.PublicKey]bool)
+	for _, k := range mtx.mentions {
+		if !seen[k] {
+			seen[k] = true
+			c.byAddr[k] = append(c.byAddr[k], mtx)
+		}
+	}
+	return mtx
+}
+
+func (c *MockChain) tokenBalances(keys []solana.PublicKey, indexes []int, t *resolvedToken) []mockTokenBalance {
+	out := make([]mockTokenBalance, 0, len(indexes))
+	for _, i := range indexes {
+		a := c.accounts[keys[i]]
+		out = append(out, mockTokenBalance{
+			AccountIndex:  i,
+			Mint:          t.key,
+			Owner:         a.tokenOwner,
+			ProgramID:     solana.TokenProgramID,
+			UITokenAmount: newMockTokenAmount(a.tokenAmount, t.Decimals),
+		})
+	}
+	return out
+}
+
+// sign signs tx with the payer's derived key, or gives it a synthetic but
+// unique signature when the payer's key is unknown.
+func (c *MockChain) sign(tx *solana.Transaction) {
+	_, err := tx.Sign(func(pk solana.PublicKey) *solana.PrivateKey {
+		if key, ok := c.keys[pk]; ok {
+			return &key
+		}
+		return nil
+	})
+	if err == nil {
+		return
+	}
+	msg, _ := tx.Message.MarshalBinary()
+	sum := sha512.Sum512(binary.BigEndian.AppendUint64(msg, c.seq))
+	tx.Signatures = []solana.Signature{solana.Signature(sum)}
+}
+
+func (c *MockChain) blockTime(slot uint64) int64 {
+	offset := (int64(slot) - int64(c.startAt)) * int64(c.slotTime)
+	return c.start.Add(time.Duration(offset)).Unix()
+}
+
+// subscribe registers l for new transactions and slots.
+func (c *MockChain) subscribe(l mockListener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, l)
+}
+
+func (c *MockChain) unsubscribe(l mockListener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, x := range c.listeners {
+		if x == l {
+			c.listeners = append(c.listeners[:i], c.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// Run advances the slot clock and plays the scenario's activity until ctx
+// ends.
+func (c *MockChain) Run(ctx context.Context) {
+	for _, a := range c.scenario.Activity {
+		tr, _ := c.activityTransfer(a)
+		every, _ := time.ParseDuration(a.Every)
+		go c.play(ctx, tr, every, a.Count)
+	}
+	ticker := time.NewTicker(c.slotTime)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			c.slot++
+			slot := c.slot
+			listeners := append([]mockListener(nil), c.listeners...)
+			c.mu.Unlock()
+			for _, l := range listeners {
+				l.onSlot(slot)
+			}
+		}
+	}
+}
+
+func (c *MockChain) play(ctx context.Context, tr mockTransfer, every time.Duration, count int) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for n := 0; count == 0 || n < count; n++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Land(tr)
+		}
+	}
+}
+
+// Land lands a transfer at the current slot and notifies listeners.
+func (c *MockChain) Land(tr mockTransfer) *mockTx {
+	c.mu.Lock()
+	tx := c.landLocked(tr, c.slot)
+	listeners := append([]mockListener(nil), c.listeners...)
+	c.mu.Unlock()
+	for _, l := range listeners {
+		l.onTransaction(tx)
+	}
+	return tx
+}
+
+// statusLocked is tx's commitment at the current slot.
+func (c *MockChain) statusLocked(tx *mockTx) TxStatus {
+	switch {
+	case c.slot >= tx.slot+mockFinalizedDepth:
+		return StatusFinalized
+	case c.slot > tx.slot:
+		return StatusConfirmed
+	}
+	return StatusProcessed
+}
+
+// MockWalletInfo describes a scenario wallet for display.
+type MockWalletInfo struct {
+	Name     string           `json:"name"`
+	Address  solana.PublicKey `json:"address"`
+	Lamports uint64           `json:"lamports"`
+}
+
+// Wallets returns the scenario wallets with their current balances.
+func (c *MockChain) Wallets() []MockWalletInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]MockWalletInfo, 0, len(c.scenario.Wallets))
+	for _, w := range c.scenario.Wallets {
+		pk := c.names[w.Name]
+		out = append(out, MockWalletInfo{Name: w.Name, Address: pk, Lamports: c.account(pk).lamports})
+	}
+	return out
+}
+
+func solToLamports(sol float64) uint64 {
+	return uint64(math.Round(sol * float64(solana.LAMPORTS_PER_SOL)))
+}
+
+func toRawAmount(amount float64, decimals uint8) uint64 {
+	return uint64(math.Round(amount * math.Pow10(int(decimals))))
+}
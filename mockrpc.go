@@ -0,0 +1,774 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gorilla/websocket"
+
+	"go-solana-tx-explorer/render"
+)
+
+// defaultMockAddr is where `mock` listens: the local validator's RPC port.
+const defaultMockAddr = "127.0.0.1:8899"
+
+// jsonrpcMessage covers both sides of a JSON-RPC 2.0 exchange.
+type jsonrpcMessage struct {
+	JSONRPC string          `json:"jsonrpc,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// decodeJSONRPC reads a single message or a batch.
+func decodeJSONRPC(data []byte) ([]jsonrpcMessage, bool, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []jsonrpcMessage
+		err := json.Unmarshal(data, &batch)
+		return batch, true, err
+	}
+	var m jsonrpcMessage
+	err := json.Unmarshal(data, &m)
+	return []jsonrpcMessage{m}, false, err
+}
+
+// MockRPCServer serves a MockChain over Solana's JSON-RPC API: HTTP POST for
+// calls and WebSocket on the same address for subscriptions. It implements
+// what the explorer uses plus common account queries; other methods answer
+// "method not found".
+type MockRPCServer struct {
+	chain *MockChain
+}
+
+// NewMockRPCServer returns an http.Handler for chain. Run the chain
+// separately to advance slots and play activity.
+func NewMockRPCServer(chain *MockChain) *MockRPCServer {
+	return &MockRPCServer{chain: chain}
+}
+
+// rpcError is a JSON-RPC error object.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+func invalidParams(format string, args ...any) *rpcError {
+	return &rpcError{Code: -32602, Message: "Invalid params: " + fmt.Sprintf(format, args...)}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *MockRPCServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		s.serveWebSocket(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "use POST for JSON-RPC or a WebSocket upgrade for subscriptions", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reqs, batch, err := decodeJSONRPC(body)
+	if err != nil {
+		http.Error(w, "invalid JSON-RPC request", http.StatusBadRequest)
+		return
+	}
+	out := make([]jsonrpcMessage, len(reqs))
+	for i, q := range reqs {
+		result, err := s.call(q.Method, q.Params)
+		out[i] = s.respond(q, result, err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		json.NewEncoder(w).Encode(out)
+		return
+	}
+	json.NewEncoder(w).Encode(out[0])
+}
+
+func (s *MockRPCServer) respond(q jsonrpcMessage, result any, err error) jsonrpcMessage {
+	resp := jsonrpcMessage{JSONRPC: "2.0", ID: q.ID}
+	if err != nil {
+		var re *rpcError
+		if !errors.As(err, &re) {
+			re = &rpcError{Code: -32603, Message: err.Error()}
+		}
+		resp.Error, _ = json.Marshal(re)
+		return resp
+	}
+	resp.Result, err = json.Marshal(result)
+	if err != nil {
+		resp.Error, _ = json.Marshal(&rpcError{Code: -32603, Message: err.Error()})
+	}
+	return resp
+}
+
+// mockConfig is the options object most methods take last.
+type mockConfig struct {
+	Commitment string   `json:"commitment"`
+	Encoding   string   `json:"encoding"`
+	Limit      int      `json:"limit"`
+	Before     string   `json:"before"`
+	Until      string   `json:"until"`
+	ProgramID  string   `json:"programId"`
+	Mint       string   `json:"mint"`
+	Mentions   []string `json:"mentions"`
+}
+
+// params splits a positional params array.
+func params(raw json.RawMessage) ([]json.RawMessage, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var ps []json.RawMessage
+	if err := json.Unmarshal(raw, &ps); err != nil {
+		return nil, invalidParams("params must be an array")
+	}
+	return ps, nil
+}
+
+func paramString(ps []json.RawMessage, i int) (string, error) {
+	if i >= len(ps) {
+		return "", invalidParams("missing parameter %d", i)
+	}
+	var v string
+	if err := json.Unmarshal(ps[i], &v); err != nil {
+		return "", invalidParams("parameter %d must be a string", i)
+	}
+	return v, nil
+}
+
+func paramKey(ps []json.RawMessage, i int) (solana.PublicKey, error) {
+	v, err := paramString(ps, i)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	pk, err := solana.PublicKeyFromBase58(v)
+	if err != nil {
+		return solana.PublicKey{}, invalidParams("invalid pubkey %q", v)
+	}
+	return pk, nil
+}
+
+func paramConfig(ps []json.RawMessage, i int) mockConfig {
+	var c mockConfig
+	if i < len(ps) {
+		json.Unmarshal(ps[i], &c)
+	}
+	if c.Commitment == "" {
+		c.Commitment = string(rpc.CommitmentFinalized)
+	}
+	return c
+}
+
+// reaches reports whether status satisfies the requested commitment.
+func reaches(status TxStatus, commitment string) bool {
+	want := TxStatus(commitment)
+	if want == "" || want.Rank() == 0 {
+		want = StatusFinalized
+	}
+	return status.Rank() >= want.Rank()
+}
+
+func (s *MockRPCServer) call(method string, raw json.RawMessage) (any, error) {
+	ps, err := params(raw)
+	if err != nil {
+		return nil, err
+	}
+	c := s.chain
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch method {
+	case "getHealth":
+		return "ok", nil
+	case "getVersion":
+		return map[string]any{"solana-core": "mock", "feature-set": 0}, nil
+	case "getGenesisHash":
+		return c.genesis.String(), nil
+	case "getSlot", "getBlockHeight":
+		return c.slotAt(paramConfig(ps, 0).Commitment), nil
+	case "getLatestBlockhash":
+		slot := c.slotAt(paramConfig(ps, 0).Commitment)
+		return c.withContext(map[string]any{
+			"blockhash":            mockBlockhash(slot).String(),
+			"lastValidBlockHeight": slot + 150,
+		}), nil
+	case "getBalance":
+		pk, err := paramKey(ps, 0)
+		if err != nil {
+			return nil, err
+		}
+		return c.withContext(c.balanceOf(pk)), nil
+	case "getAccountInfo":
+		pk, err := paramKey(ps, 0)
+		if err != nil {
+			return nil, err
+		}
+		return c.withContext(c.accountJSON(pk)), nil
+	case "getMultipleAccounts":
+		var keys []string
+		if len(ps) == 0 || json.Unmarshal(ps[0], &keys) != nil {
+			return nil, invalidParams("parameter 0 must be an array of pubkeys")
+		}
+		out := make([]any, len(keys))
+		for i, k := range keys {
+			pk, err := solana.PublicKeyFromBase58(k)
+			if err != nil {
+				return nil, invalidParams("invalid pubkey %q", k)
+			}
+			out[i] = c.accountJSON(pk)
+		}
+		return c.withContext(out), nil
+	case "getTokenAccountsByOwner":
+		return c.tokenAccountsByOwner(ps)
+	case "getSignaturesForAddress":
+		return c.signaturesForAddress(ps)
+	case "getTransaction":
+		return c.transaction(ps)
+	case "getSignatureStatuses":
+		return c.signatureStatuses(ps)
+	}
+	return nil, &rpcError{Code: -32601, Message: "Method not found"}
+}
+
+// slotAt is the newest slot at commitment.
+func (c *MockChain) slotAt(commitment string) uint64 {
+	switch TxStatus(commitment) {
+	case StatusProcessed:
+		return c.slot
+	case StatusConfirmed:
+		return c.slot - 1
+	}
+	return c.slot - mockFinalizedDepth
+}
+
+func (c *MockChain) withContext(v any) map[string]any {
+	return map[string]any{"context": map[string]any{"slot": c.slot, "apiVersion": "mock"}, "value": v}
+}
+
+func (c *MockChain) balanceOf(pk solana.PublicKey) uint64 {
+	if a, ok := c.accounts[pk]; ok {
+		return a.lamports
+	}
+	return 0
+}
+
+// accountJSON renders an account in base64 encoding, or nil if it does not
+// exist. Token accounts carry the SPL token account layout.
+func (c *MockChain) accountJSON(pk solana.PublicKey) any {
+	a, ok := c.accounts[pk]
+	if !ok {
+		return nil
+	}
+	var data []byte
+	if a.isToken {
+		data = tokenAccountData(a)
+	}
+	return map[string]any{
+		"lamports":   a.lamports,
+		"owner":      a.owner.String(),
+		"data":       []string{base64.StdEncoding.EncodeToString(data), "base64"},
+		"executable": false,
+		"rentEpoch":  uint64(18446744073709551615),
+		"space":      len(data),
+	}
+}
+
+// tokenAccountData is the 165-byte SPL token account layout.
+func tokenAccountData(a *mockAccount) []byte {
+	data := make([]byte, 165)
+	copy(data[0:32], a.mint[:])
+	copy(data[32:64], a.tokenOwner[:])
+	binary.LittleEndian.PutUint64(data[64:72], a.tokenAmount)
+	data[108] = 1 // initialized
+	return data
+}
+
+func (c *MockChain) tokenAccountsByOwner(ps []json.RawMessage) (any, error) {
+	owner, err := paramKey(ps, 0)
+	if err != nil {
+		return nil, err
+	}
+	filter := paramConfig(ps, 1)
+	if filter.ProgramID == "" && filter.Mint == "" {
+		return nil, invalidParams("filter must name a programId or mint")
+	}
+	if filter.ProgramID != "" && filter.ProgramID != solana.TokenProgramID.String() {
+		return c.withContext([]any{}), nil
+	}
+	cfg := paramConfig(ps, 2)
+
+	var keys []solana.PublicKey
+	for pk, a := range c.accounts {
+		if a.isToken && a.tokenOwner == owner && (filter.Mint == "" || a.mint.String() == filter.Mint) {
+			keys = append(keys, pk)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	out := make([]any, 0, len(keys))
+	for _, pk := range keys {
+		a := c.accounts[pk]
+		account := c.accountJSON(pk).(map[string]any)
+		if cfg.Encoding == "jsonParsed" {
+			decimals := uint8(0)
+			if t := c.tokenByMint(a.mint); t != nil {
+				decimals = t.Decimals
+			}
+			account["data"] = map[string]any{
+				"program": "spl-token",
+				"space":   165,
+				"parsed": map[string]any{
+					"type": "account",
+					"info": map[string]any{
+						"mint":        a.mint.String(),
+						"owner":       a.tokenOwner.String(),
+						"state":       "initialized",
+						"isNative":    false,
+						"tokenAmount": newMockTokenAmount(a.tokenAmount, decimals),
+					},
+				},
+			}
+		}
+		out = append(out, map[string]any{"pubkey": pk.String(), "account": account})
+	}
+	return c.withContext(out), nil
+}
+
+func (c *MockChain) signaturesForAddress(ps []json.RawMessage) (any, error) {
+	addr, err := paramKey(ps, 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg := paramConfig(ps, 1)
+	if cfg.Limit <= 0 || cfg.Limit > 1000 {
+		cfg.Limit = 1000
+	}
+	history := c.byAddr[addr]
+	out := []map[string]any{}
+	started := cfg.Before == ""
+	for i := len(history) - 1; i >= 0 && len(out) < cfg.Limit; i-- {
+		tx := history[i]
+		sig := tx.signature.String()
+		if !started {
+			started = sig == cfg.Before
+			continue
+		}
+		if sig == cfg.Until {
+			break
+		}
+		status := c.statusLocked(tx)
+		if !reaches(status, cfg.Commitment) {
+			continue
+		}
+		out = append(out, map[string]any{
+			"signature":          sig,
+			"slot":               tx.slot,
+			"err":                tx.meta.Err,
+			"memo":               nil,
+			"blockTime":          tx.blockTime,
+			"confirmationStatus": status,
+		})
+	}
+	return out, nil
+}
+
+func (c *MockChain) lookup(ps []json.RawMessage, i int) (*mockTx, error) {
+	v, err := paramString(ps, i)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := solana.SignatureFromBase58(v)
+	if err != nil {
+		return nil, invalidParams("invalid signature %q", v)
+	}
+	return c.txs[sig], nil
+}
+
+func (c *MockChain) transaction(ps []json.RawMessage) (any, error) {
+	tx, err := c.lookup(ps, 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg := paramConfig(ps, 1)
+	if tx == nil || !reaches(c.statusLocked(tx), cfg.Commitment) {
+		return nil, nil
+	}
+	var encoded any
+	switch cfg.Encoding {
+	case "base64":
+		b, err := tx.tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		encoded = []string{base64.StdEncoding.EncodeToString(b), "base64"}
+	case "base58":
+		b, err := tx.tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		encoded = []string{solana.Base58(b).String(), "base58"}
+	case "", "json":
+		encoded = tx.tx
+	default:
+		return nil, invalidParams("unsupported encoding %q", cfg.Encoding)
+	}
+	return map[string]any{
+		"slot":        tx.slot,
+		"blockTime":   tx.blockTime,
+		"meta":        tx.meta,
+		"transaction": encoded,
+		"version":     "legacy",
+	}, nil
+}
+
+func (c *MockChain) signatureStatuses(ps []json.RawMessage) (any, error) {
+	var sigs []string
+	if len(ps) == 0 || json.Unmarshal(ps[0], &sigs) != nil {
+		return nil, invalidParams("parameter 0 must be an array of signatures")
+	}
+	out := make([]any, len(sigs))
+	for i, v := range sigs {
+		sig, err := solana.SignatureFromBase58(v)
+		if err != nil {
+			return nil, invalidParams("invalid signature %q", v)
+		}
+		tx, ok := c.txs[sig]
+		if !ok {
+			continue
+		}
+		status := c.statusLocked(tx)
+		var confirmations any
+		if status != StatusFinalized {
+			confirmations = c.slot - tx.slot
+		}
+		out[i] = map[string]any{
+			"slot":               tx.slot,
+			"confirmations":      confirmations,
+			"err":                tx.meta.Err,
+			"status":             tx.meta.Status,
+			"confirmationStatus": status,
+		}
+	}
+	return c.withContext(out), nil
+}
+
+var mockUpgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+// mockSubscription is one WebSocket subscription.
+type mockSubscription struct {
+	id         uint64
+	kind       string // logs, signature or slot
+	commitment string
+	mentions   *solana.PublicKey // logs: nil means all
+	signature  solana.Signature  // signature
+}
+
+// mockConn is a WebSocket client. It listens to the chain and forwards what
+// its subscriptions match.
+type mockConn struct {
+	server *MockRPCServer
+	conn   *websocket.Conn
+	out    chan any
+
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[uint64]*mockSubscription
+}
+
+func (s *MockRPCServer) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := mockUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	mc := &mockConn{server: s, conn: conn, out: make(chan any, 256), subs: make(map[uint64]*mockSubscription)}
+	s.chain.subscribe(mc)
+	defer s.chain.unsubscribe(mc)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range mc.out {
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		}
+	}()
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		var q jsonrpcMessage
+		if err := json.Unmarshal(data, &q); err != nil {
+			continue
+		}
+		result, err := mc.handle(q.Method, q.Params)
+		mc.send(s.respond(q, result, err))
+	}
+	mc.mu.Lock()
+	close(mc.out)
+	mc.out = nil
+	mc.mu.Unlock()
+	<-done
+	conn.Close()
+}
+
+// send queues msg, dropping it if the client is gone or too slow.
+func (mc *mockConn) send(msg any) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.out == nil {
+		return
+	}
+	select {
+	case mc.out <- msg:
+	default:
+	}
+}
+
+func (mc *mockConn) handle(method string, raw json.RawMessage) (any, error) {
+	ps, err := params(raw)
+	if err != nil {
+		return nil, err
+	}
+	sub := &mockSubscription{commitment: paramConfig(ps, 1).Commitment}
+	switch method {
+	case "logsSubscribe":
+		sub.kind = "logs"
+		var all string
+		if len(ps) > 0 && json.Unmarshal(ps[0], &all) != nil {
+			f := paramConfig(ps, 0)
+			if len(f.Mentions) != 1 {
+				return nil, invalidParams("mentions must name exactly one address")
+			}
+			pk, err := solana.PublicKeyFromBase58(f.Mentions[0])
+			if err != nil {
+				return nil, invalidParams("invalid pubkey %q", f.Mentions[0])
+			}
+			sub.mentions = &pk
+		}
+	case "signatureSubscribe":
+		sub.kind = "signature"
+		v, err := paramString(ps, 0)
+		if err != nil {
+			return nil, err
+		}
+		if sub.signature, err = solana.SignatureFromBase58(v); err != nil {
+			return nil, invalidParams("invalid signature %q", v)
+		}
+	case "slotSubscribe":
+		sub.kind = "slot"
+	case "logsUnsubscribe", "signatureUnsubscribe", "slotUnsubscribe":
+		var id uint64
+		if len(ps) == 0 || json.Unmarshal(ps[0], &id) != nil {
+			return nil, invalidParams("parameter 0 must be a subscription id")
+		}
+		mc.mu.Lock()
+		_, ok := mc.subs[id]
+		delete(mc.subs, id)
+		mc.mu.Unlock()
+		return ok, nil
+	default:
+		return nil, &rpcError{Code: -32601, Message: "Method not found"}
+	}
+
+	mc.mu.Lock()
+	mc.nextID++
+	sub.id = mc.nextID
+	mc.subs[sub.id] = sub
+	mc.mu.Unlock()
+
+	// A signature that already reached the commitment is reported at once
+	if sub.kind == "signature" {
+		c := mc.server.chain
+		c.mu.Lock()
+		tx, ok := c.txs[sub.signature]
+		reached := ok && reaches(c.statusLocked(tx), sub.commitment)
+		c.mu.Unlock()
+		if reached {
+			go mc.notifySignature(sub, tx)
+		}
+	}
+	return sub.id, nil
+}
+
+func (mc *mockConn) notify(method string, id uint64, result any) {
+	params, _ := json.Marshal(map[string]any{"result": result, "subscription": id})
+	mc.send(jsonrpcMessage{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (mc *mockConn) notifySignature(sub *mockSubscription, tx *mockTx) {
+	mc.mu.Lock()
+	_, live := mc.subs[sub.id]
+	delete(mc.subs, sub.id)
+	mc.mu.Unlock()
+	if live {
+		mc.notify("signatureNotification", sub.id, map[string]any{
+			"context": map[string]any{"slot": tx.slot},
+			"value":   map[string]any{"err": tx.meta.Err},
+		})
+	}
+}
+
+func (mc *mockConn) notifyLogs(sub *mockSubscription, tx *mockTx) {
+	if sub.mentions != nil {
+		found := false
+		for _, k := range tx.mentions {
+			if k == *sub.mentions {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return
+		}
+	}
+	mc.notify("logsNotification", sub.id, map[string]any{
+		"context": map[string]any{"slot": tx.slot},
+		"value":   map[string]any{"signature": tx.signature.String(), "err": tx.meta.Err, "logs": tx.meta.LogMessages},
+	})
+}
+
+func (mc *mockConn) snapshot() []*mockSubscription {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	out := make([]*mockSubscription, 0, len(mc.subs))
+	for _, s := range mc.subs {
+		out = append(out, s)
+	}
+	return out
+}
+
+// onTransaction reports a new transaction to processed subscriptions.
+func (mc *mockConn) onTransaction(tx *mockTx) {
+	for _, sub := range mc.snapshot() {
+		if sub.commitment != string(StatusProcessed) {
+			continue
+		}
+		switch {
+		case sub.kind == "logs":
+			mc.notifyLogs(sub, tx)
+		case sub.kind == "signature" && sub.signature == tx.signature:
+			mc.notifySignature(sub, tx)
+		}
+	}
+}
+
+// onSlot reports the new slot and the transactions that just became
+// confirmed or finalized.
+func (mc *mockConn) onSlot(slot uint64) {
+	c := mc.server.chain
+	c.mu.Lock()
+	confirmed := c.bySlot[slot-1]
+	finalized := c.bySlot[slot-mockFinalizedDepth]
+	c.mu.Unlock()
+
+	for _, sub := range mc.snapshot() {
+		var txs []*mockTx
+		switch TxStatus(sub.commitment) {
+		case StatusConfirmed:
+			txs = confirmed
+		case StatusFinalized, "":
+			txs = finalized
+		}
+		switch sub.kind {
+		case "slot":
+			mc.notify("slotNotification", sub.id, map[string]any{"parent": slot - 1, "root": slot - mockFinalizedDepth, "slot": slot})
+		case "logs":
+			for _, tx := range txs {
+				mc.notifyLogs(sub, tx)
+			}
+		case "signature":
+			for _, tx := range txs {
+				if tx.signature == sub.signature {
+					mc.notifySignature(sub, tx)
+				}
+			}
+		}
+	}
+}
+
+// runMock implements `mock [flags]`.
+func runMock(ctx context.Context, cfg *Config, args []string) error {
+	fs := flag.NewFlagSet("mock", flag.ContinueOnError)
+	addr := fs.String("addr", defaultMockAddr, "listen address for JSON-RPC (HTTP) and subscriptions (WebSocket)")
+	scenarioPath := fs.String("scenario", "", "scenario file (.yaml, .json or .toml); default: a built-in three-wallet scenario")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	scenario := DefaultMockScenario()
+	if *scenarioPath != "" {
+		var err error
+		if scenario, err = LoadMockScenario(*scenarioPath); err != nil {
+			return err
+		}
+	}
+	chain, err := NewMockChain(scenario)
+	if err != nil {
+		return fmt.Errorf("scenario: %w", err)
+	}
+	go chain.Run(ctx)
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: NewMockRPCServer(chain), ReadHeaderTimeout: 5 * time.Second}
+	host := ln.Addr().String()
+	slog.Info("Serving mock RPC", "rpc", "http://"+host, "ws", "ws://"+host)
+	formatter := newFormatter(cfg, false, nil)
+	if err := printMockWallets(formatter, chain.Wallets()); err != nil {
+		return err
+	}
+	formatter.FormatHint(fmt.Sprintf("\nTry: go run . -rpc http://%s -ws ws://%s watch <ADDRESS>", host, host))
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := shutdownContext(ctx, cfg)
+	defer cancel()
+	srv.Shutdown(shutdownCtx)
+	return ctx.Err()
+}
+
+// printMockWallets lists the scenario wallets to point the CLI at.
+func printMockWallets(formatter *cliFormatter, wallets []MockWalletInfo) error {
+	t := render.Table{Title: "Scenario Wallets", Header: []string{"Name", "Address", "SOL"}}
+	for _, w := range wallets {
+		t.Rows = append(t.Rows, []any{w.Name, render.AccountCell(nil, nil, w.Address, ""),
+			fmt.Sprintf("%.4f", float64(w.Lamports)/float64(solana.LAMPORTS_PER_SOL))})
+	}
+	doc := render.Document{render.Banner{Title: "🧪 MOCK WALLETS", Tone: render.ToneInfo}, t}
+	return formatter.Render(render.DocumentView{Name: "mock_wallets", Data: wallets, Doc: doc})
+}
@@ -0,0 +1,859 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"go-solana-tx-explorer/rpc/client"
+)
+
+const (
+	defaultSinkRetries   = 3
+	defaultSinkTimeout   = 10 * time.Second
+	defaultSinkQueueSize = 100
+	// recentDeliveries bounds the per-sink delivery history kept for status.
+	recentDeliveries = 20
+	// discordContentLimit is Discord's maximum message length.
+	discordContentLimit = 2000
+)
+
+// NotificationKind says what produced a notification.
+type NotificationKind string
+
+const (
+	NotifyTransaction NotificationKind = "transaction"
+	NotifyAlert       NotificationKind = "alert"
+	NotifyStatus      NotificationKind = "status"
+)
+
+// Notification is the data handed to sinks and their templates.
+type Notification struct {
+	Kind      NotificationKind `json:"kind"`
+	Severity  Severity         `json:"severity"`
+	Title     string           `json:"title"`
+	Wallet    string           `json:"wallet"`
+	Address   string           `json:"address"`
+	Signature string           `json:"signature"`
+	Slot      uint64           `json:"slot"`
+	Message   string           `json:"message"`
+	// Status is the commitment reached, set for status notifications
+	Status   TxStatus  `json:"status,omitempty"`
+	Explorer string    `json:"explorer,omitempty"`
+	Time     time.Time `json:"time"`
+	// Key is stable for the same event so receivers can drop duplicates; it is
+	// sent as the Idempotency-Key header by webhook sinks.
+	Key string `json:"key"`
+}
+
+// AlertNotification wraps a fired alert.
+func AlertNotification(a Alert, cluster *client.Cluster) Notification {
+	n := Notification{
+		Kind:      NotifyAlert,
+		Severity:  a.Severity,
+		Title:     a.Rule,
+		Wallet:    a.Wallet,
+		Address:   a.Address,
+		Signature: a.Signature,
+		Slot:      a.Slot,
+		Message:   a.Message,
+		Time:      a.Time,
+		Key:       "alert|" + a.DedupKey + "|" + a.Signature,
+	}
+	if cluster != nil {
+		n.Explorer = cluster.TxURL(a.Signature)
+	}
+	return n
+}
+
+// TransactionNotification describes a new transaction for a wallet.
+func TransactionNotification(ev TransactionEvent, cluster *client.Cluster) Notification {
+	c := ev.Classification
+	txType := string(c.Type)
+	if c.Direction != "" {
+		txType += " (" + c.Direction + ")"
+	}
+	status := "succeeded"
+	if ev.Tx.Meta != nil && ev.Tx.Meta.Err != nil {
+		status = "failed"
+	}
+	msg := fmt.Sprintf("%s %s, SOL change %+.9f", txType, status, float64(c.SOLChange)/1e9)
+	if ev.Tx.Meta != nil {
+		msg += fmt.Sprintf(", fee %d lamports", ev.Tx.Meta.Fee)
+	}
+
+	n := Notification{
+		Kind:      NotifyTransaction,
+		Severity:  SeverityInfo,
+		Title:     "New transaction",
+		Wallet:    ev.Wallet.Label,
+		Address:   ev.Wallet.Account.String(),
+		Signature: ev.Tx.Signature,
+		Slot:      ev.Tx.Slot,
+		Message:   msg,
+		Time:      time.Now(),
+		Key:       "tx|" + ev.Wallet.Account.String() + "|" + ev.Tx.Signature,
+	}
+	if cluster != nil {
+		n.Explorer = cluster.TxURL(ev.Tx.Signature)
+	}
+	return n
+}
+
+// StatusNotification describes a transaction reaching a new commitment
+// level, or its retraction when it was dropped.
+func StatusNotification(ch StatusChange, cluster *client.Cluster) Notification {
+	sig := ch.Signature.String()
+	n := Notification{
+		Kind:      NotifyStatus,
+		Severity:  SeverityInfo,
+		Title:     "Transaction " + string(ch.To),
+		Wallet:    ch.Wallet.Label,
+		Address:   ch.Wallet.Account.String(),
+		Signature: sig,
+		Slot:      ch.Slot,
+		Message:   statusMessage(ch),
+		Status:    ch.To,
+		Time:      ch.Time,
+		Key:       "status|" + ch.Wallet.Account.String() + "|" + sig + "|" + string(ch.To),
+	}
+	if ch.To == StatusDropped {
+		n.Severity = SeverityWarning
+		n.Title = "Transaction retracted"
+	}
+	if cluster != nil {
+		n.Explorer = cluster.TxURL(sig)
+	}
+	return n
+}
+
+func statusMessage(ch StatusChange) string {
+	msg := string(ch.To)
+	if ch.From != "" {
+		msg = string(ch.From) + " → " + msg
+	}
+	if ch.Failed {
+		msg += " (transaction failed)"
+	}
+	if ch.Reason != "" {
+		msg += ": " + ch.Reason
+	}
+	return msg
+}
+
+// defaultTextTemplate renders chat and email bodies.
+const defaultTextTemplate = `{{if eq .Kind "alert"}}[{{.Severity | upper}}] {{end}}{{.Title}} — {{.Wallet}}
+{{.Message}}
+Signature: {{.Signature}} (slot {{.Slot}}){{if .Explorer}}
+{{.Explorer}}{{end}}`
+
+const defaultSubjectTemplate = `{{if eq .Kind "alert"}}[{{.Severity | upper}}] {{end}}{{.Title}} — {{.Wallet}}`
+
+var templateFuncs = template.FuncMap{
+	"upper": func(v any) string { return strings.ToUpper(fmt.Sprint(v)) },
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// SinkConfig is one notification target. Fields that only apply to some sink
+// types are ignored by the others. String values are expanded with
+// environment variables, so secrets can stay out of the file.
+type SinkConfig struct {
+	Name        string            `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Type        string            `json:"type" yaml:"type" toml:"type"`
+	Events      []string          `json:"events,omitempty" yaml:"events,omitempty" toml:"events,omitempty"`
+	MinSeverity string            `json:"min_severity,omitempty" yaml:"min_severity,omitempty" toml:"min_severity,omitempty"`
+	Template    string            `json:"template,omitempty" yaml:"template,omitempty" toml:"template,omitempty"`
+	Retries     *int              `json:"retries,omitempty" yaml:"retries,omitempty" toml:"retries,omitempty"`
+	Timeout     string            `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+	URL         string            `json:"url,omitempty" yaml:"url,omitempty" toml:"url,omitempty"`
+	Secret      string            `json:"secret,omitempty" yaml:"secret,omitempty" toml:"secret,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" toml:"headers,omitempty"`
+
+	// smtp
+	Host            string   `json:"host,omitempty" yaml:"host,omitempty" toml:"host,omitempty"`
+	Port            int      `json:"port,omitempty" yaml:"port,omitempty" toml:"port,omitempty"`
+	Username        string   `json:"username,omitempty" yaml:"username,omitempty" toml:"username,omitempty"`
+	Password        string   `json:"password,omitempty" yaml:"password,omitempty" toml:"password,omitempty"`
+	From            string   `json:"from,omitempty" yaml:"from,omitempty" toml:"from,omitempty"`
+	To              []string `json:"to,omitempty" yaml:"to,omitempty" toml:"to,omitempty"`
+	SubjectTemplate string   `json:"subject_template,omitempty" yaml:"subject_template,omitempty" toml:"subject_template,omitempty"`
+}
+
+// NotifyFile is the on-disk sink definition.
+type NotifyFile struct {
+	Sinks []SinkConfig `json:"sinks" yaml:"sinks" toml:"sinks"`
+}
+
+// Sink delivers one rendered notification. Errors wrapped in permanentError
+// are not retried.
+type Sink interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// permanentError marks a delivery failure that retrying cannot fix, such as
+// a 4xx response.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// DeliveryStatus records the outcome of one notification on one sink.
+type DeliveryStatus struct {
+	Key      string    `json:"key"`
+	Kind     string    `json:"kind"`
+	Attempts int       `json:"attempts"`
+	Status   string    `json:"status"` // delivered, failed or dropped
+	Error    string    `json:"error,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// SinkStatus is a snapshot of a sink's delivery counters.
+type SinkStatus struct {
+	Name      string           `json:"name"`
+	Type      string           `json:"type"`
+	Delivered int              `json:"delivered"`
+	Failed    int              `json:"failed"`
+	Dropped   int              `json:"dropped"`
+	Retries   int              `json:"retries"`
+	LastError string           `json:"last_error,omitempty"`
+	Recent    []DeliveryStatus `json:"recent"`
+}
+
+type sinkWorker struct {
+	name        string
+	typ         string
+	events      map[NotificationKind]bool
+	minSeverity Severity
+	retries     int
+	sink        Sink
+	queue       chan delivery
+
+	mu     sync.Mutex
+	status SinkStatus
+}
+
+// Dispatcher fans notifications out to every interested sink. Each sink has
+// its own queue and worker so a slow or failing sink never blocks the
+// listener or the other sinks.
+type Dispatcher struct {
+	workers []*sinkWorker
+	wg      sync.WaitGroup
+	// closing cuts retry backoff short once Close starts, and ctx aborts
+	// sends when Close's own context ends.
+	closing chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// LoadNotifySinks reads a sink file (.yaml, .json or .toml).
+func LoadNotifySinks(path string) (*Dispatcher, error) {
+	var file NotifyFile
+	if err := decodeConfigFile(path, &file); err != nil {
+		return nil, fmt.Errorf("notify %s: %w", path, err)
+	}
+	d, err := NewDispatcher(file)
+	if err != nil {
+		return nil, fmt.Errorf("notify %s: %w", path, err)
+	}
+	return d, nil
+}
+
+// NewDispatcher validates the sink configs and starts one worker per sink.
+func NewDispatcher(file NotifyFile) (*Dispatcher, error) {
+	if len(file.Sinks) == 0 {
+		return nil, errors.New("no sinks defined")
+	}
+	d := &Dispatcher{closing: make(chan struct{})}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	names := make(map[string]bool)
+	for i, sc := range file.Sinks {
+		w, err := newSinkWorker(expandSinkConfig(sc))
+		if err != nil {
+			d.cancel()
+			return nil, fmt.Errorf("sink #%d: %w", i+1, err)
+		}
+		if names[w.name] {
+			d.cancel()
+			return nil, fmt.Errorf("sink #%d: duplicate name %q", i+1, w.name)
+		}
+		names[w.name] = true
+		d.workers = append(d.workers, w)
+	}
+	for _, w := range d.workers {
+		d.wg.Add(1)
+		go func(w *sinkWorker) {
+			defer d.wg.Done()
+			w.run(d.ctx, d.closing)
+		}(w)
+	}
+	return d, nil
+}
+
+func expandSinkConfig(sc SinkConfig) SinkConfig {
+	for _, s := range []*string{&sc.URL, &sc.Secret, &sc.Host, &sc.Username, &sc.Password, &sc.From} {
+		*s = os.ExpandEnv(*s)
+	}
+	for k, v := range sc.Headers {
+		sc.Headers[k] = os.ExpandEnv(v)
+	}
+	for i, to := range sc.To {
+		sc.To[i] = os.ExpandEnv(to)
+	}
+	return sc
+}
+
+func newSinkWorker(sc SinkConfig) (*sinkWorker, error) {
+	w := &sinkWorker{
+		name:    sc.Name,
+		typ:     sc.Type,
+		events:  map[NotificationKind]bool{},
+		retries: defaultSinkRetries,
+		queue:   make(chan delivery, defaultSinkQueueSize),
+	}
+	if w.name == "" {
+		w.name = sc.Type
+	}
+	w.status = SinkStatus{Name: w.name, Type: w.typ}
+
+	if len(sc.Events) == 0 {
+		w.events[NotifyAlert] = true
+	}
+	for _, e := range sc.Events {
+		switch k := NotificationKind(strings.ToLower(e)); k {
+		case NotifyAlert, NotifyTransaction, NotifyStatus:
+			w.events[k] = true
+		default:
+			return nil, fmt.Errorf("unknown event %q (want alert, transaction or status)", e)
+		}
+	}
+	if sc.MinSeverity != "" {
+		sev, err := parseSeverity(sc.MinSeverity)
+		if err != nil {
+			return nil, err
+		}
+		w.minSeverity = sev
+	}
+	if sc.Retries != nil {
+		if *sc.Retries < 0 {
+			return nil, errors.New("retries must not be negative")
+		}
+		w.retries = *sc.Retries
+	}
+	timeout := defaultSinkTimeout
+	if sc.Timeout != "" {
+		d, err := time.ParseDuration(sc.Timeout)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q", sc.Timeout)
+		}
+		timeout = d
+	}
+
+	var body *template.Template
+	if sc.Template != "" {
+		t, err := template.New("body").Funcs(templateFuncs).Parse(sc.Template)
+		if err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+		body = t
+	}
+	textBody := body
+	if textBody == nil {
+		textBody = template.Must(template.New("body").Funcs(templateFuncs).Parse(defaultTextTemplate))
+	}
+	client := &http.Client{Timeout: timeout}
+
+	switch sc.Type {
+	case "webhook":
+		if err := validateEndpoint(sc.URL, "http", "https"); err != nil {
+			return nil, fmt.Errorf("url: %w", err)
+		}
+		w.sink = &webhookSink{client: client, url: sc.URL, secret: sc.Secret, headers: sc.Headers, body: body}
+	case "slack", "discord":
+		if err := validateEndpoint(sc.URL, "http", "https"); err != nil {
+			return nil, fmt.Errorf("url: %w", err)
+		}
+		w.sink = &chatSink{client: client, url: sc.URL, discord: sc.Type == "discord", body: textBody}
+	case "smtp":
+		if sc.Host == "" || sc.From == "" || len(sc.To) == 0 {
+			return nil, errors.New("smtp needs host, from and to")
+		}
+		subject := defaultSubjectTemplate
+		if sc.SubjectTemplate != "" {
+			subject = sc.SubjectTemplate
+		}
+		st, err := template.New("subject").Funcs(templateFuncs).Parse(subject)
+		if err != nil {
+			return nil, fmt.Errorf("subject_template: %w", err)
+		}
+		port := sc.Port
+		if port == 0 {
+			port = 587
+		}
+		w.sink = &smtpSink{
+			addr: net.JoinHostPort(sc.Host, strconv.Itoa(port)), host: sc.Host,
+			username: sc.Username, password: sc.Password,
+			from: sc.From, to: sc.To, subject: st, body: textBody, timeout: timeout,
+		}
+	case "":
+		return nil, errors.New("missing type")
+	default:
+		return nil, fmt.Errorf("unknown type %q (want webhook, slack, discord or smtp)", sc.Type)
+	}
+	return w, nil
+}
+
+// Wants reports whether any sink subscribes to kind.
+func (d *Dispatcher) Wants(kind NotificationKind) bool {
+	if d == nil {
+		return false
+	}
+	for _, w := range d.workers {
+		if w.events[kind] {
+			return true
+		}
+	}
+	return false
+}
+
+// Dispatch queues n on every interested sink without blocking. When a sink's
+// queue is full the notification is recorded as dropped for that sink.
+func (d *Dispatcher) Dispatch(n Notification) {
+	if d == nil {
+		return
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+	for _, w := range d.workers {
+		if !w.events[n.Kind] || n.Severity.Rank() < w.minSeverity.Rank() {
+			continue
+		}
+		select {
+		case w.queue <- delivery{n: n}:
+		default:
+			w.record(DeliveryStatus{Key: n.Key, Kind: string(n.Kind), Status: "dropped", Error: "queue full", Time: time.Now()})
+			slog.Warn("Notification queue full, dropped", "sink", w.name, "key", n.Key)
+		}
+	}
+}
+
+// Deliver queues n on every interested sink, waiting for queue space, and
+// blocks until each sink has finished with it. It returns an error when a sink
+// gave up on a transient failure so the caller can retry the notification
+// later; permanent rejections are only recorded. Sinks deduplicate retried
+// notifications by their idempotency key.
+func (d *Dispatcher) Deliver(ctx context.Context, n Notification) error {
+	if d == nil {
+		return nil
+	}
+	var pending []chan error
+	d.mu.RLock()
+	if d.closed {
+		d.mu.RUnlock()
+		return errors.New("dispatcher closed")
+	}
+	for _, w := range d.workers {
+		if !w.events[n.Kind] || n.Severity.Rank() < w.minSeverity.Rank() {
+			continue
+		}
+		done := make(chan error, 1)
+		select {
+		case w.queue <- delivery{n: n, done: done}:
+			pending = append(pending, done)
+		case <-ctx.Done():
+			d.mu.RUnlock()
+			w.record(DeliveryStatus{Key: n.Key, Kind: string(n.Kind), Status: "dropped", Error: ctx.Err().Error(), Time: time.Now()})
+			return ctx.Err()
+		}
+	}
+	d.mu.RUnlock()
+
+	var errs []error
+	for _, done := range pending {
+		select {
+		case err := <-done:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops accepting notifications and waits for queued ones to be
+// delivered, or for ctx to end.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.closing)
+		for _, w := range d.workers {
+			close(w.queue)
+		}
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		// Abort sends and backoff; what is left in the queues is dropped
+		d.cancel()
+		<-done
+		return fmt.Errorf("notifications still pending: %w", ctx.Err())
+	}
+}
+
+// Status returns a snapshot of every sink's delivery counters.
+func (d *Dispatcher) Status() []SinkStatus {
+	if d == nil {
+		return nil
+	}
+	out := make([]SinkStatus, 0, len(d.workers))
+	for _, w := range d.workers {
+		w.mu.Lock()
+		s := w.status
+		s.Recent = append([]DeliveryStatus(nil), w.status.Recent...)
+		w.mu.Unlock()
+		out = append(out, s)
+	}
+	return out
+}
+
+// delivery is a queued notification. done, when set, receives the outcome:
+// nil once delivered or permanently rejected, the last error otherwise.
+type delivery struct {
+	n    Notification
+	done chan error
+}
+
+func (w *sinkWorker) run(ctx context.Context, closing <-chan struct{}) {
+	for d := range w.queue {
+		err := w.deliver(ctx, closing, d.n)
+		if d.done != nil {
+			var perm permanentError
+			if errors.As(err, &perm) {
+				err = nil
+			}
+			d.done <- err
+		}
+	}
+}
+
+// deliver sends n with exponential backoff between attempts. Once closing is
+// closed it makes one last attempt without waiting, and it gives up when
+// ctx ends.
+func (w *sinkWorker) deliver(ctx context.Context, closing <-chan struct{}, n Notification) error {
+	backoff := time.Second
+	ds := DeliveryStatus{Key: n.Key, Kind: string(n.Kind)}
+	if ctx.Err() != nil {
+		ds.Status, ds.Error, ds.Time = "dropped", ctx.Err().Error(), time.Now()
+		w.record(ds)
+		return fmt.Errorf("%s: %w", w.name, ctx.Err())
+	}
+	var err error
+	for attempt := 0; attempt <= w.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+			case <-closing:
+				attempt = w.retries
+			case <-time.After(backoff):
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			backoff *= 2
+		}
+		ds.Attempts++
+		if err = w.sink.Send(ctx, n); err == nil {
+			break
+		}
+		var perm permanentError
+		if errors.As(err, &perm) {
+			break
+		}
+	}
+
+	ds.Time = time.Now()
+	if err != nil {
+		ds.Status, ds.Error = "failed", err.Error()
+		slog.Error("Notification delivery failed", "sink", w.name, "key", n.Key, "attempts", ds.Attempts, "err", err)
+		err = fmt.Errorf("%s: %w", w.name, err)
+	} else {
+		ds.Status = "delivered"
+	}
+	w.record(ds)
+	return err
+}
+
+func (w *sinkWorker) record(ds DeliveryStatus) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch ds.Status {
+	case "delivered":
+		w.status.Delivered++
+	case "failed":
+		w.status.Failed++
+		w.status.LastError = ds.Error
+	case "dropped":
+		w.status.Dropped++
+	}
+	notificationsTotal.Inc(w.name, w.typ, ds.Status)
+	if ds.Attempts > 1 {
+		w.status.Retries += ds.Attempts - 1
+		notificationRetriesTotal.Add(float64(ds.Attempts-1), w.name)
+	}
+	w.status.Recent = append(w.status.Recent, ds)
+	if len(w.status.Recent) > recentDeliveries {
+		w.status.Recent = w.status.Recent[len(w.status.Recent)-recentDeliveries:]
+	}
+}
+
+func renderTemplate(t *template.Template, n Notification) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, n); err != nil {
+		return "", permanentError{fmt.Errorf("render template: %w", err)}
+	}
+	return buf.String(), nil
+}
+
+// postJSON posts body and classifies the response: 2xx is success, 429 and
+// 5xx are retried, any other status is permanent.
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("http status %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return err
+	}
+	return permanentError{err}
+}
+
+// webhookSink posts the notification as JSON (or the rendered template) and,
+// with a secret, signs it: X-Signature-256 is
+// "sha256=" + hex(HMAC-SHA256(secret, X-Timestamp + "." + body)).
+type webhookSink struct {
+	client  *http.Client
+	url     string
+	secret  string
+	headers map[string]string
+	body    *template.Template
+}
+
+func (s *webhookSink) Send(ctx context.Context, n Notification) error {
+	var body []byte
+	if s.body != nil {
+		rendered, err := renderTemplate(s.body, n)
+		if err != nil {
+			return err
+		}
+		body = []byte(rendered)
+	} else {
+		var err error
+		if body, err = json.Marshal(n); err != nil {
+			return permanentError{err}
+		}
+	}
+
+	headers := map[string]string{"Idempotency-Key": n.Key}
+	for k, v := range s.headers {
+		headers[k] = v
+	}
+	if s.secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write([]byte(ts + "."))
+		mac.Write(body)
+		headers["X-Timestamp"] = ts
+		headers["X-Signature-256"] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	return postJSON(ctx, s.client, s.url, body, headers)
+}
+
+// chatSink posts to Slack ({"text": ...}) or Discord ({"content": ...})
+// incoming webhooks.
+type chatSink struct {
+	client  *http.Client
+	url     string
+	discord bool
+	body    *template.Template
+}
+
+func (s *chatSink) Send(ctx context.Context, n Notification) error {
+	text, err := renderTemplate(s.body, n)
+	if err != nil {
+		return err
+	}
+	var payload any = map[string]string{"text": text}
+	if s.discord {
+		// The limit counts characters; cutting bytes could split one
+		if utf8.RuneCountInString(text) > discordContentLimit {
+			text = string([]rune(text)[:discordContentLimit-1]) + "…"
+		}
+		payload = map[string]string{"content": text}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return permanentError{err}
+	}
+	return postJSON(ctx, s.client, s.url, body, nil)
+}
+
+// smtpSink sends a plain-text email. STARTTLS is used when the server offers
+// it; authentication is only attempted with a username.
+type smtpSink struct {
+	addr, host         string
+	username, password string
+	from               string
+	to                 []string
+	subject, body      *template.Template
+	timeout            time.Duration
+}
+
+func (s *smtpSink) Send(ctx context.Context, n Notification) error {
+	subject, err := renderTemplate(s.subject, n)
+	if err != nil {
+		return err
+	}
+	body, err := renderTemplate(s.body, n)
+	if err != nil {
+		return err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.to, ", "))
+	// Templates and wallet labels may hold any UTF-8; headers must be ASCII
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.ReplaceAll(subject, "\n", " ")))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	keyHash := sha256.Sum256([]byte(n.Key))
+	fmt.Fprintf(&msg, "Message-ID: <%s@solana-tx-explorer>\r\n", hex.EncodeToString(keyHash[:16]))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	// net/smtp has no deadlines of its own; bound the whole exchange on the
+	// connection, and drop it early if ctx ends first
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("smtp %s: %w", s.addr, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return fmt.Errorf("smtp %s: %w", s.addr, err)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := s.send(conn, auth, msg.Bytes()); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("smtp %s: %w", s.addr, err)
+	}
+	return nil
+}
+
+// send runs the SMTP exchange smtp.SendMail would, over conn.
+func (s *smtpSink) send(conn net.Conn, auth smtp.Auth, msg []byte) error {
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if err := c.Hello("localhost"); err != nil {
+		return err
+	}
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("server doesn't support AUTH")
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.from); err != nil {
+		return err
+	}
+	for _, to := range s.to {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// testSink builds the sink sc configures.
+func testSink(t *testing.T, sc SinkConfig) Sink {
+	t.Helper()
+	w, err := newSinkWorker(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w.sink
+}
+
+// request is what a test endpoint received.
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// endpoint records the requests it receives.
+func endpoint(t *testing.T) (string, <-chan request) {
+	t.Helper()
+	got := make(chan request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- request{header: r.Header, body: body}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, got
+}
+
+func TestWebhookSinkSignsBody(t *testing.T) {
+	url, got := endpoint(t)
+	sink := testSink(t, SinkConfig{Type: "webhook", URL: url, Secret: "s3cret"})
+	if err := sink.Send(context.Background(), Notification{Kind: NotifyAlert, Title: "large transfer", Key: "k1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-got
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(req.header.Get("X-Timestamp") + "."))
+	mac.Write(req.body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); req.header.Get("X-Signature-256") != want {
+		t.Errorf("X-Signature-256 = %q, want %q", req.header.Get("X-Signature-256"), want)
+	}
+	if req.header.Get("Idempotency-Key") != "k1" {
+		t.Errorf("Idempotency-Key = %q, want k1", req.header.Get("Idempotency-Key"))
+	}
+}
+
+func TestDiscordSinkTruncatesByRune(t *testing.T) {
+	url, got := endpoint(t)
+	sink := testSink(t, SinkConfig{Type: "discord", URL: url, Template: "{{.Message}}"})
+	// Multi-byte runes, so a byte cut would land mid-character
+	message := strings.Repeat("é", discordContentLimit+10)
+	if err := sink.Send(context.Background(), Notification{Message: message}); err != nil {
+		t.Fatal(err)
+	}
+
+	var payload struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal((<-got).body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if !utf8.ValidString(payload.Content) {
+		t.Fatal("content is not valid UTF-8")
+	}
+	if n := utf8.RuneCountInString(payload.Content); n != discordContentLimit {
+		t.Errorf("content has %d characters, want %d", n, discordContentLimit)
+	}
+	if !strings.HasSuffix(payload.Content, "é…") {
+		t.Errorf("content does not end with an ellipsis: %q", payload.Content[len(payload.Content)-8:])
+	}
+}
+
+// smtpServer accepts one message the way a relay without STARTTLS or AUTH
+// would and returns its DATA.
+func smtpServer(t *testing.T) (host, port string, data <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	out := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 test ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb := strings.ToUpper(strings.Fields(line + " ")[0]); verb {
+			case "EHLO":
+				tp.PrintfLine("250 test")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				body, err := io.ReadAll(tp.DotReader())
+				if err != nil {
+					return
+				}
+				out <- string(body)
+				tp.PrintfLine("250 queued")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				return
+			default:
+				tp.PrintfLine("250 OK")
+			}
+		}
+	}()
+	host, port, _ = net.SplitHostPort(ln.Addr().String())
+	return host, port, out
+}
+
+func TestSMTPSinkEncodesSubject(t *testing.T) {
+	host, port, data := smtpServer(t)
+	sink := testSink(t, SinkConfig{
+		Type: "smtp", Host: host, Port: atoi(t, port), From: "alerts@example.com", To: []string{"ops@example.com"},
+		SubjectTemplate: "{{.Wallet}}: {{.Title}}",
+	})
+	if err := sink.Send(context.Background(), Notification{Wallet: "trésor 💰", Title: "large transfer", Key: "k1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := textproto.NewReader(bufio.NewReader(strings.NewReader(<-data))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := msg.Get("Subject")
+	for _, r := range raw {
+		if r > 127 {
+			t.Fatalf("Subject header is not ASCII: %q", raw)
+		}
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "trésor 💰: large transfer"; subject != want {
+		t.Errorf("Subject decodes to %q, want %q", subject, want)
+	}
+}
+
+func TestSMTPSinkTimesOutOnSilentServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	// Accept but never send the greeting
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			io.Copy(io.Discard, conn)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	sink := testSink(t, SinkConfig{
+		Type: "smtp", Host: host, Port: atoi(t, port), From: "alerts@example.com", To: []string{"ops@example.com"},
+		Timeout: "100ms",
+	})
+	start := time.Now()
+	if err := sink.Send(context.Background(), Notification{Title: "t"}); err == nil {
+		t.Fatal("Send succeeded against a silent server")
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("Send took %s with a 100ms timeout", d)
+	}
+}
+
+func atoi(t *testing.T, s string) int {
+	t.Helper()
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
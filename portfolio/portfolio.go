@@ -0,0 +1,127 @@
+// Package portfolio lists the SPL token holdings of a wallet, named from the
+// token registry.
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/registry"
+)
+
+// TokenHolding represents a single SPL token balance entry for a wallet.
+// It is intentionally simple and UI-friendly, using the RPC-provided UI string
+// amount to avoid precision issues and extra conversions.
+type TokenHolding struct {
+	Mint     string `json:"mint"`
+	UiAmount string `json:"ui_amount"`
+	Decimals int    `json:"decimals"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+}
+
+// Config configures a UserPortfolioService.
+type Config struct {
+	// Cluster names the cluster whose token registry names the holdings.
+	Cluster string
+	// Registry names holdings; nil leaves them unnamed (except wrapped SOL).
+	Registry *registry.Registry
+}
+
+// UserPortfolioService is responsible for fetching all SPL token holdings for
+// a given wallet. It uses the JSON-RPC method `getTokenAccountsByOwner` with
+// `jsonParsed` encoding to avoid manual binary decoding of token account data.
+type UserPortfolioService struct {
+	client *rpc.Client
+	cfg    Config
+}
+
+// NewUserPortfolioService creates a new portfolio service instance.
+func NewUserPortfolioService(client *rpc.Client, cfg Config) *UserPortfolioService {
+	return &UserPortfolioService{client: client, cfg: cfg}
+}
+
+// tokenProgramID is the well-known SPL Token Program ID (Tokenkeg...).
+// Keeping it as a constant improves readability and avoids magic strings.
+const tokenProgramID = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+
+// FetchUserTokens returns the non-zero SPL token holdings of `owner`, enriched
+// with registry names and ordered by amount descending.
+func (s *UserPortfolioService) FetchUserTokens(ctx context.Context, owner solana.PublicKey) ([]TokenHolding, error) {
+	// Raw JSON-RPC call (avoids mismatches in typed wrappers across versions).
+	// Going through the shared client keeps it under the same rate limit as
+	// every other request.
+	params := []interface{}{
+		owner.String(),
+		map[string]interface{}{"programId": tokenProgramID},
+		map[string]interface{}{"encoding": "jsonParsed", "commitment": "confirmed"},
+	}
+
+	var result struct {
+		Value []struct {
+			Account struct {
+				Data struct {
+					Parsed struct {
+						Info struct {
+							Mint        string `json:"mint"`
+							TokenAmount struct {
+								UiAmountString string `json:"uiAmountString"`
+								Decimals       int    `json:"decimals"`
+							} `json:"tokenAmount"`
+						} `json:"info"`
+					} `json:"parsed"`
+				} `json:"data"`
+			} `json:"account"`
+		} `json:"value"`
+	}
+	if err := s.client.RPCCallForInto(ctx, &result, "getTokenAccountsByOwner", params); err != nil {
+		return nil, fmt.Errorf("rpc getTokenAccountsByOwner: %w", err)
+	}
+
+	// Load token registry for name/symbol enrichment (best-effort)
+	tokens := map[string]registry.TokenInfo{}
+	if s.cfg.Registry != nil {
+		loaded, err := s.cfg.Registry.Load(ctx, s.cfg.Cluster)
+		if err != nil {
+			// Non-fatal; continue without enrichment
+			slog.DebugContext(ctx, "Token registry unavailable", "cluster", s.cfg.Cluster, "err", err)
+		} else {
+			tokens = loaded
+		}
+	}
+
+	// Collect holdings in a structured slice
+	holdings := make([]TokenHolding, 0)
+	for _, item := range result.Value {
+		mint := item.Account.Data.Parsed.Info.Mint
+		amt := item.Account.Data.Parsed.Info.TokenAmount.UiAmountString
+		decimals := item.Account.Data.Parsed.Info.TokenAmount.Decimals
+		if amt == "" || amt == "0" || amt == "0.0" || amt == "0.00" || amt == "0.000" {
+			continue
+		}
+		name := ""
+		symbol := ""
+		if info, ok := tokens[mint]; ok {
+			name = info.Name
+			symbol = info.Symbol
+		} else if mint == solana.WrappedSol.String() {
+			name = "Wrapped SOL"
+			symbol = "wSOL"
+		}
+		holdings = append(holdings, TokenHolding{Mint: mint, UiAmount: amt, Decimals: decimals, Name: name, Symbol: symbol})
+	}
+
+	// Order by amount descending for better readability
+	sort.Slice(holdings, func(i, j int) bool {
+		ai, _ := strconv.ParseFloat(holdings[i].UiAmount, 64)
+		aj, _ := strconv.ParseFloat(holdings[j].UiAmount, 64)
+		return ai > aj
+	})
+	return holdings, nil
+}
@@ -0,0 +1,85 @@
+package portfolio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/registry"
+	"go-solana-tx-explorer/rpc/fixture"
+)
+
+// The fixture in testdata was recorded from the mock chain's default
+// scenario. To record it again, run `go run . mock` and then
+// `go test ./portfolio -record http://127.0.0.1:8899`.
+var (
+	record = flag.String("record", "", "record testdata fixtures from this RPC endpoint instead of replaying them")
+	update = flag.Bool("update", false, "rewrite testdata golden files")
+)
+
+// alice is the first wallet of the mock scenario, holding its USDC and BONK.
+var alice = solana.MustPublicKeyFromBase58("6UeLw8eMAULzV1kvuQRvcWmdm2u7yvLnWuSXvRM4o9DS")
+
+// tokenList names the mock chain's USDC mint and leaves BONK unnamed.
+const tokenList = `[{"address":"3CzDVBfAFNvThgov9jDoTpY7RJpgrSsfFFaFauUK18vZ","symbol":"USDC","name":"USD Coin"}]`
+
+func TestFetchUserTokens(t *testing.T) {
+	srv, err := fixture.Serve(filepath.Join("testdata", "portfolio.json"), *record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := srv.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	list := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(tokenList))
+	}))
+	defer list.Close()
+
+	reg := registry.New(registry.Config{Sources: map[string][]registry.Source{
+		"localnet": {{Kind: registry.KindJupiter, URL: list.URL}},
+	}})
+	svc := NewUserPortfolioService(rpc.New(srv.URL), Config{Cluster: "localnet", Registry: reg})
+	holdings, err := svc.FetchUserTokens(context.Background(), alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holdings) != 2 {
+		t.Fatalf("got %d holdings, want alice's USDC and BONK", len(holdings))
+	}
+
+	out, err := json.MarshalIndent(holdings, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "portfolio.golden.json", append(out, '\n'))
+}
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update || *record != "" {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run go test -update to accept it):\n%s", path, got)
+	}
+}
@@ -0,0 +1,292 @@
+// Package registry resolves SPL token mints to names and symbols from the
+// public token lists. Lists are downloaded once per cluster and cached for
+// the life of the Registry; a cluster whose lists all fail to download is
+// retried after a minute.
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TokenInfo is a minimal entry from the Solana token list registry.
+type TokenInfo struct {
+	Address string `json:"address"`
+	Symbol  string `json:"symbol"`
+	Name    string `json:"name"`
+	ChainID int    `json:"chainId,omitempty"`
+}
+
+// tokenListResponse matches the root structure of the public token list.
+type tokenListResponse struct {
+	Tokens []TokenInfo `json:"tokens"`
+}
+
+// Kind tells Load how to parse a token list source.
+type Kind string
+
+const (
+	KindJupiter    Kind = "jupiter"     // flat array of tokens
+	KindSolanaLabs Kind = "solana-labs" // {"tokens": [...]} with chainId
+)
+
+// Source is one token list consulted for mint names.
+type Source struct {
+	Kind Kind
+	URL  string
+	// ChainID selects the cluster's entries from a solana-labs list, which
+	// mixes every cluster's mints.
+	ChainID int
+}
+
+const solanaLabsTokenListURL = "https://cdn.jsdelivr.net/gh/solana-labs/token-list@main/src/tokens/solana.tokenlist.json"
+
+// DefaultSources returns the token lists for each public cluster, by cluster
+// name, in order of precedence. Local validators have none: local mints are
+// never in public lists.
+func DefaultSources() map[string][]Source {
+	return map[string][]Source{
+		"mainnet": {
+			{Kind: KindJupiter, URL: "https://token.jup.ag/all"},
+			{Kind: KindJupiter, URL: "https://token.jup.ag/strict"},
+			{Kind: KindSolanaLabs, URL: solanaLabsTokenListURL, ChainID: 101},
+		},
+		"testnet": {{Kind: KindSolanaLabs, URL: solanaLabsTokenListURL, ChainID: 102}},
+		"devnet":  {{Kind: KindSolanaLabs, URL: solanaLabsTokenListURL, ChainID: 103}},
+	}
+}
+
+// Config configures New. The zero value downloads the default sources with
+// http.DefaultClient.
+type Config struct {
+	// Sources maps cluster names to their token lists; nil uses
+	// DefaultSources.
+	Sources map[string][]Source
+	// HTTPClient downloads the lists; nil uses http.DefaultClient.
+	HTTPClient *http.Client
+	// Offline skips downloads and yields empty registries, for reproducible
+	// output.
+	Offline bool
+}
+
+// Registry loads and caches token lists per cluster. It is safe for
+// concurrent use.
+type Registry struct {
+	cfg Config
+	now func() time.Time
+
+	mu       sync.Mutex
+	cache    map[string]map[string]TokenInfo
+	loadedAt map[string]time.Time
+	// failedAt holds clusters whose every source failed, so they are not
+	// downloaded again until retryAfter has passed.
+	failedAt map[string]time.Time
+	// loads holds the download in progress per cluster, which concurrent
+	// callers share.
+	loads map[string]*load
+}
+
+// load is one download of a cluster's sources.
+type load struct {
+	done   chan struct{}
+	tokens map[string]TokenInfo
+	err    error
+}
+
+const (
+	// downloadTimeout bounds one download of a cluster's sources. Downloads
+	// outlive the request that started them, since other callers share them.
+	downloadTimeout = 30 * time.Second
+	// retryAfter is how long a cluster whose sources all failed is served
+	// empty before they are downloaded again.
+	retryAfter = time.Minute
+)
+
+// New returns an empty registry; lists load on first use.
+func New(cfg Config) *Registry {
+	if cfg.Sources == nil {
+		cfg.Sources = DefaultSources()
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	return &Registry{
+		cfg:      cfg,
+		now:      time.Now,
+		cache:    make(map[string]map[string]TokenInfo),
+		loadedAt: make(map[string]time.Time),
+		failedAt: make(map[string]time.Time),
+		loads:    make(map[string]*load),
+	}
+}
+
+// Load merges the cluster's sources to maximize coverage, earlier sources
+// taking precedence, and returns the tokens by mint address. Results are
+// cached per cluster; a cluster without sources yields an empty registry. If
+// every source fails, Load returns an error and tries again after a minute.
+// The returned map must not be modified.
+func (r *Registry) Load(ctx context.Context, cluster string) (map[string]TokenInfo, error) {
+	if r.cfg.Offline {
+		return map[string]TokenInfo{}, nil
+	}
+	r.mu.Lock()
+	if cached, ok := r.cache[cluster]; ok {
+		r.mu.Unlock()
+		return cached, nil
+	}
+	// Keep an offline run from retrying per wallet
+	if failed, ok := r.failedAt[cluster]; ok && r.now().Sub(failed) < retryAfter {
+		r.mu.Unlock()
+		return map[string]TokenInfo{}, fmt.Errorf("no token registry sources available for %s", cluster)
+	}
+	l, ok := r.loads[cluster]
+	if !ok {
+		l = &load{done: make(chan struct{})}
+		r.loads[cluster] = l
+		go r.download(context.WithoutCancel(ctx), cluster, l)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-l.done:
+		return l.tokens, l.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// download merges cluster's sources into l and caches the result if any
+// source answered.
+func (r *Registry) download(ctx context.Context, cluster string, l *load) {
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	sources := r.cfg.Sources[cluster]
+	merged := make(map[string]TokenInfo)
+	for _, src := range sources {
+		var m map[string]TokenInfo
+		var err error
+		switch src.Kind {
+		case KindJupiter:
+			m, err = r.loadJupiterList(ctx, src.URL)
+		case KindSolanaLabs:
+			m, err = r.loadSolanaLabsList(ctx, src.URL, src.ChainID)
+		}
+		if err != nil {
+			continue
+		}
+		for k, v := range m {
+			if _, ok := merged[k]; !ok {
+				merged[k] = v
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.loads, cluster)
+	l.tokens = merged
+	if len(merged) == 0 && len(sources) > 0 {
+		l.err = fmt.Errorf("no token registry sources available for %s", cluster)
+		r.failedAt[cluster] = r.now()
+	} else {
+		delete(r.failedAt, cluster)
+		r.cache[cluster] = merged
+		r.loadedAt[cluster] = r.now()
+	}
+	close(l.done)
+}
+
+// Stat describes one cluster's loaded registry.
+type Stat struct {
+	Cluster  string
+	Tokens   int
+	LoadedAt time.Time
+}
+
+// Stats returns the loaded registries, ordered by cluster name.
+func (r *Registry) Stats() []Stat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := make([]Stat, 0, len(r.cache))
+	for cluster, m := range r.cache {
+		stats = append(stats, Stat{Cluster: cluster, Tokens: len(m), LoadedAt: r.loadedAt[cluster]})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Cluster < stats[j].Cluster })
+	return stats
+}
+
+func (r *Registry) loadJupiterList(ctx context.Context, url string) (map[string]TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build jupiter req: %w", err)
+	}
+	resp, err := r.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch jupiter: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jupiter http status: %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read jupiter: %w", err)
+	}
+
+	var items []struct {
+		Address string `json:"address"`
+		Symbol  string `json:"symbol"`
+		Name    string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, fmt.Errorf("decode jupiter: %w", err)
+	}
+	out := make(map[string]TokenInfo, len(items))
+	for _, it := range items {
+		if it.Address == "" {
+			continue
+		}
+		out[it.Address] = TokenInfo{Address: it.Address, Symbol: it.Symbol, Name: it.Name}
+	}
+	return out, nil
+}
+
+// loadSolanaLabsList reads the legacy solana-labs list, which mixes every
+// cluster's mints; only entries for chainID are kept.
+func (r *Registry) loadSolanaLabsList(ctx context.Context, tokenListURL string, chainID int) (map[string]TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenListURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build registry request: %w", err)
+	}
+	resp, err := r.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch registry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry http status: %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read registry: %w", err)
+	}
+	var data tokenListResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("decode registry: %w", err)
+	}
+	byMint := make(map[string]TokenInfo, len(data.Tokens))
+	for _, t := range data.Tokens {
+		if t.ChainID != chainID {
+			continue
+		}
+		byMint[t.Address] = t
+	}
+	return byMint, nil
+}
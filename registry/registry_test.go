@@ -0,0 +1,111 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const usdc = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+// listServer serves a one-token Jupiter list, failing while down is set.
+func listServer(t *testing.T, down *atomic.Bool, hits *atomic.Int32, release <-chan struct{}) Config {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if release != nil {
+			<-release
+		}
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[{"address":"` + usdc + `","symbol":"USDC","name":"USD Coin"}]`))
+	}))
+	t.Cleanup(srv.Close)
+	return Config{Sources: map[string][]Source{"mainnet": {{Kind: KindJupiter, URL: srv.URL}}}}
+}
+
+func TestLoadRetriesAfterEverySourceFailed(t *testing.T) {
+	ctx := context.Background()
+	var down atomic.Bool
+	var hits atomic.Int32
+	down.Store(true)
+	r := New(listServer(t, &down, &hits, nil))
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	if _, err := r.Load(ctx, "mainnet"); err == nil {
+		t.Fatal("Load succeeded with every source down")
+	}
+	// Within the retry window the failure is served without downloading
+	down.Store(false)
+	if _, err := r.Load(ctx, "mainnet"); err == nil {
+		t.Fatal("Load retried before the retry window passed")
+	}
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("downloads = %d, want 1", n)
+	}
+	if stats := r.Stats(); len(stats) != 0 {
+		t.Fatalf("failed load was cached: %+v", stats)
+	}
+
+	now = now.Add(retryAfter)
+	tokens, err := r.Load(ctx, "mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens[usdc].Symbol != "USDC" {
+		t.Fatalf("tokens = %v, want USDC", tokens)
+	}
+	if _, err := r.Load(ctx, "mainnet"); err != nil || hits.Load() != 2 {
+		t.Fatalf("successful load was not cached: err %v, downloads %d", err, hits.Load())
+	}
+}
+
+func TestLoadSharesDownloadAcrossCallers(t *testing.T) {
+	var down atomic.Bool
+	var hits atomic.Int32
+	release := make(chan struct{})
+	r := New(listServer(t, &down, &hits, release))
+
+	// The first caller gives up mid-download; the others still get the list
+	first, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := r.Load(first, "mainnet")
+		errs <- err
+	}()
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("cancelled caller got %v, want context.Canceled", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokens, err := r.Load(context.Background(), "mainnet")
+			if err != nil || tokens[usdc].Symbol != "USDC" {
+				t.Errorf("Load = %v, %v, want USDC", tokens, err)
+			}
+		}()
+	}
+	// Stats takes the lock, so it must not wait on the download
+	if stats := r.Stats(); len(stats) != 0 {
+		t.Errorf("Stats() = %+v before the download finished", stats)
+	}
+	close(release)
+	wg.Wait()
+	if n := hits.Load(); n != 1 {
+		t.Errorf("downloads = %d, want 1", n)
+	}
+}
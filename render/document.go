@@ -0,0 +1,126 @@
+package render
+
+import "encoding/json"
+
+// View is an intermediate view model: the data one formatter method shows,
+// independent of how it is drawn. Structured renderers encode the view
+// itself; text renderers draw its Document.
+type View interface {
+	// ViewName identifies the view in structured output.
+	ViewName() string
+	// Document lays the view out as headings, fields, tables and code.
+	Document() Document
+}
+
+// Document is a view laid out as a sequence of blocks.
+type Document []Block
+
+// Block is one element of a Document: a Banner, Section, Field, Table, Code
+// or Note.
+type Block interface {
+	block()
+}
+
+// Tone is what a piece of text means; themes map tones to colors.
+type Tone int
+
+const (
+	ToneNone Tone = iota
+	// ToneInfo marks identifiers and neutral highlights.
+	ToneInfo
+	// TonePositive marks success and balance increases.
+	TonePositive
+	// ToneNegative marks failures and balance decreases.
+	ToneNegative
+	// ToneWarning marks pending states and values worth a second look.
+	ToneWarning
+	// ToneAccent marks secondary highlights.
+	ToneAccent
+)
+
+// Banner opens a view.
+type Banner struct {
+	Title string
+	Tone  Tone
+}
+
+// Section heads one part of a view.
+type Section struct {
+	Title string
+	Tone  Tone
+}
+
+// Field is one "label: value" line.
+type Field struct {
+	Label string
+	Value Cell
+}
+
+// Table is a titled table. A nil Header makes it a two-column key/value
+// table; a nil row draws a separator.
+type Table struct {
+	Title  string
+	Header []string
+	Rows   [][]any
+	// Emphasized marks the main table of a view, drawn in the theme's
+	// highlight style.
+	Emphasized   bool
+	SeparateRows bool
+}
+
+// Code is verbatim text such as program logs or raw JSON.
+type Code struct {
+	Title string
+	// Lang is the Markdown fence language.
+	Lang  string
+	Lines []string
+	// Numbered draws the lines as a numbered list in the terminal.
+	Numbered bool
+	// More describes lines left out, e.g. "and 3 more logs".
+	More string
+}
+
+// Note is a standalone remark, e.g. that a view is empty.
+type Note struct {
+	Text string
+	Tone Tone
+}
+
+func (Banner) block()  {}
+func (Section) block() {}
+func (Field) block()   {}
+func (Table) block()   {}
+func (Code) block()    {}
+func (Note) block()    {}
+
+// Cell is a field or table value with presentation hints. Table rows may
+// also hold plain values, which are printed with fmt.
+type Cell struct {
+	Text string
+	// Full is the untruncated address or signature the cell stands for. CSV
+	// prints it instead of Text, and Markdown sets the cell as code.
+	Full string
+	// Link is an explorer URL Markdown links the cell to.
+	Link string
+	Tone Tone
+}
+
+func (c Cell) String() string {
+	return c.Text
+}
+
+// DocumentView is a View for a prepared document, encoding data in
+// structured output. It suits one-off views such as banners and alerts.
+type DocumentView struct {
+	Name string
+	Data any
+	Doc  Document
+}
+
+func (v DocumentView) ViewName() string   { return v.Name }
+func (v DocumentView) Document() Document { return v.Doc }
+
+// MarshalJSON encodes Data rather than the layout.
+func (v DocumentView) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Data)
+}
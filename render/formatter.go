@@ -0,0 +1,123 @@
+// Package render draws transactions, fee statistics and portfolios through
+// intermediate view models, as themed terminal tables, GitHub-flavored
+// Markdown, JSON or CSV.
+package render
+
+import (
+	"io"
+	"os"
+
+	"github.com/gagliardetto/solana-go"
+
+	"go-solana-tx-explorer/addressbook"
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+	"go-solana-tx-explorer/portfolio"
+	"go-solana-tx-explorer/rpc/client"
+)
+
+// Config configures a TransactionFormatter.
+type Config struct {
+	// ShowFullData lists every log line, account and instruction instead of
+	// the first few.
+	ShowFullData bool
+	// Cluster provides explorer links; nil for transactions that are not on
+	// chain (decoded or simulated)
+	Cluster *client.Cluster
+	// Labels names accounts wherever addresses are shown; nil shows bare
+	// addresses.
+	Labels *addressbook.Book
+	// Out receives the output; nil means stdout.
+	Out io.Writer
+	// Format picks the renderer; empty means Pretty.
+	Format Format
+	// Theme styles Pretty output; the zero value means DefaultTheme.
+	Theme Theme
+	// Renderer replaces the one Format and Theme would pick.
+	Renderer Renderer
+}
+
+// TransactionFormatter turns transactions and portfolios into views and
+// hands them to its renderer.
+type TransactionFormatter struct {
+	showFullData bool
+	cluster      *client.Cluster
+	labels       *addressbook.Book
+	out          io.Writer
+	format       Format
+	renderer     Renderer
+}
+
+// NewTransactionFormatter creates a new formatter instance
+func NewTransactionFormatter(cfg Config) *TransactionFormatter {
+	f := &TransactionFormatter{
+		showFullData: cfg.ShowFullData,
+		cluster:      cfg.Cluster,
+		labels:       cfg.Labels,
+		out:          cfg.Out,
+		format:       cfg.Format,
+		renderer:     cfg.Renderer,
+	}
+	if f.out == nil {
+		f.out = os.Stdout
+	}
+	if f.format == "" {
+		f.format = Pretty
+	}
+	if f.renderer == nil {
+		theme := cfg.Theme
+		if theme.Name == "" {
+			theme = DefaultTheme
+		}
+		f.renderer = NewRenderer(f.format, f.out, theme)
+	}
+	return f
+}
+
+// Format returns the output format the formatter was configured with.
+func (f *TransactionFormatter) Format() Format {
+	return f.format
+}
+
+// Labels returns the address book the formatter names accounts with.
+func (f *TransactionFormatter) Labels() *addressbook.Book {
+	return f.labels
+}
+
+// Render draws v to the formatter's output.
+func (f *TransactionFormatter) Render(v View) error {
+	return f.renderer.Render(f.out, v)
+}
+
+// FormatTransactionSummary displays a summary table of all transactions
+func (f *TransactionFormatter) FormatTransactionSummary(accountTxs *fetch.AccountTransactions) error {
+	return f.Render(NewSummaryView(accountTxs, f.cluster, f.labels))
+}
+
+// FormatSimulationHeader displays the outcome banner for a simulated transaction
+func (f *TransactionFormatter) FormatSimulationHeader(tx *decode.TransactionInfo) error {
+	return f.Render(NewSimulationView(tx))
+}
+
+// FormatTransactionDetails displays detailed information for a specific transaction
+func (f *TransactionFormatter) FormatTransactionDetails(tx decode.TransactionInfo, index int) error {
+	return f.Render(NewDetailsView(tx, index, f.cluster, f.labels, f.showFullData))
+}
+
+// FormatFeeStats displays aggregate fee and compute usage for a set of transactions
+func (f *TransactionFormatter) FormatFeeStats(stats decode.FeeStats) error {
+	if stats.Transactions == 0 {
+		return nil
+	}
+	return f.Render(FeeStatsView{stats})
+}
+
+// FormatSignatureChecks displays the result of local signature verification
+func (f *TransactionFormatter) FormatSignatureChecks(checks []decode.SignatureCheck) error {
+	return f.Render(NewSignatureChecksView(checks, f.cluster, f.labels))
+}
+
+// FormatUserPortfolio displays a table for a slice of token holdings.
+func (f *TransactionFormatter) FormatUserPortfolio(owner solana.PublicKey, tokens []portfolio.TokenHolding) error {
+	return f.Render(NewPortfolioView(owner, tokens, f.cluster, f.labels))
+}
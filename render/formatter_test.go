@@ -0,0 +1,108 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/addressbook"
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+	"go-solana-tx-explorer/rpc/client"
+	"go-solana-tx-explorer/rpc/fixture"
+)
+
+// The fixture in testdata was recorded from the mock chain's default
+// scenario. To record it again, run `go run . mock` and then
+// `go test ./render -record http://127.0.0.1:8899`.
+var (
+	record = flag.String("record", "", "record testdata fixtures from this RPC endpoint instead of replaying them")
+	update = flag.Bool("update", false, "rewrite testdata golden files")
+)
+
+// alice is the first wallet of the mock scenario; her history mixes SOL and
+// token transfers, failed and successful.
+var alice = solana.MustPublicKeyFromBase58("6UeLw8eMAULzV1kvuQRvcWmdm2u7yvLnWuSXvRM4o9DS")
+
+// aliceHistory fetches the history the golden files were rendered from.
+func aliceHistory(t *testing.T) *fetch.AccountTransactions {
+	t.Helper()
+	srv, err := fixture.Serve(filepath.Join("testdata", "history.json"), *record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := srv.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	recordedAt := srv.RecordedAt
+	svc, err := fetch.NewTransactionService(rpc.New(srv.URL), fetch.Config{Now: func() time.Time { return recordedAt }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := svc.FetchAccountTransactions(context.Background(), alice, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return history
+}
+
+func TestTransactionFormatterGolden(t *testing.T) {
+	// Block times render in local time
+	defer func(loc *time.Location) { time.Local = loc }(time.Local)
+	time.Local = time.UTC
+
+	history := aliceHistory(t)
+	for _, format := range []Format{Pretty, Markdown, JSON, CSV} {
+		t.Run(string(format), func(t *testing.T) {
+			labels := addressbook.New()
+			labels.AddWallet(alice, "alice")
+			var out bytes.Buffer
+			f := NewTransactionFormatter(Config{
+				Cluster: client.Mainnet,
+				Labels:  labels,
+				Out:     &out,
+				Format:  format,
+			})
+			if err := f.FormatTransactionSummary(history); err != nil {
+				t.Fatal(err)
+			}
+			if err := f.FormatFeeStats(decode.ComputeFeeStats(history.Transactions)); err != nil {
+				t.Fatal(err)
+			}
+			for i, tx := range history.Transactions {
+				labels.LabelTokenAccounts(tx)
+				if err := f.FormatTransactionDetails(tx, i); err != nil {
+					t.Fatal(err)
+				}
+			}
+			golden(t, "history."+string(format)+".golden", out.Bytes())
+		})
+	}
+}
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update || *record != "" {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run go test -update to accept it):\n%s", path, got)
+	}
+}
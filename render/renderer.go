@@ -0,0 +1,405 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// Renderer draws views to a writer.
+type Renderer interface {
+	Render(w io.Writer, v View) error
+}
+
+// Format names an output format.
+type Format string
+
+const (
+	// Pretty draws themed Unicode tables for a terminal.
+	Pretty Format = "pretty"
+	// Markdown writes GitHub-flavored Markdown for tickets and audit docs:
+	// plain tables, fenced logs and explorer links.
+	Markdown Format = "markdown"
+	// JSON writes newline-delimited JSON: one compact document per view.
+	JSON Format = "json"
+	// CSV writes one table: the main table of every view with the columns
+	// of the first.
+	CSV Format = "csv"
+)
+
+// ParseFormat validates an output format name; empty selects Pretty.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return Pretty, nil
+	case Pretty, Markdown, JSON, CSV:
+		return f, nil
+	case "md":
+		return Markdown, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (want pretty, markdown, json or csv)", s)
+	}
+}
+
+// NewRenderer returns the renderer for format writing to out. Pretty output
+// is colored only on a terminal without NO_COLOR, and fitted to its width.
+func NewRenderer(format Format, out io.Writer, theme Theme) Renderer {
+	switch format {
+	case Markdown:
+		return MarkdownRenderer{}
+	case JSON:
+		return JSONRenderer{}
+	case CSV:
+		return &CSVRenderer{}
+	default:
+		return PrettyRenderer{Theme: theme, Color: ColorEnabled(out), Width: TerminalWidth(out)}
+	}
+}
+
+// PrettyRenderer draws documents as terminal tables.
+type PrettyRenderer struct {
+	Theme Theme
+	// Color enables ANSI colors; without it tables fall back to the theme's
+	// plain table style.
+	Color bool
+	// Width wraps the widest columns of tables that would not fit; 0
+	// leaves them as they are.
+	Width int
+}
+
+// minColumnWidth is how narrow Width may squeeze a column.
+const minColumnWidth = 12
+
+// Render draws v's document in one write.
+func (r PrettyRenderer) Render(w io.Writer, v View) error {
+	var b strings.Builder
+	var prev Block
+	for _, blk := range v.Document() {
+		// Blank line between a banner's fields and what follows them
+		if _, ok := prev.(Field); ok {
+			if _, ok := blk.(Field); !ok {
+				b.WriteString("\n")
+			}
+		}
+		switch blk := blk.(type) {
+		case Banner:
+			if r.Color {
+				fmt.Fprintf(&b, "\n%s\n", r.Theme.banner(blk.Tone).Sprintf(" %s ", blk.Title))
+			} else {
+				fmt.Fprintf(&b, "\n=== %s ===\n", blk.Title)
+			}
+		case Section:
+			fmt.Fprintf(&b, "\n%s\n", r.paint(blk.Title, blk.Tone))
+		case Field:
+			fmt.Fprintf(&b, "%s: %s\n", blk.Label, r.paint(blk.Value.Text, blk.Value.Tone))
+		case Table:
+			b.WriteString(r.table(blk) + "\n")
+		case Code:
+			r.code(&b, blk)
+		case Note:
+			b.WriteString(r.paint(blk.Text, blk.Tone) + "\n")
+		}
+		prev = blk
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (r PrettyRenderer) paint(s string, tone Tone) string {
+	c, ok := r.Theme.Text[tone]
+	if !r.Color || !ok {
+		return s
+	}
+	return c.Sprint(s)
+}
+
+func (r PrettyRenderer) table(t Table) string {
+	tw := table.NewWriter()
+	tw.SetTitle(t.Title)
+	if t.Header != nil {
+		tw.AppendHeader(headerRow(t.Header))
+	}
+	for _, row := range t.Rows {
+		if row == nil {
+			tw.AppendSeparator()
+			continue
+		}
+		out := make(table.Row, len(row))
+		for i, v := range row {
+			if c, ok := v.(Cell); ok {
+				v = r.paint(c.Text, c.Tone)
+			}
+			out[i] = v
+		}
+		tw.AppendRow(out)
+	}
+
+	style := r.Theme.Table
+	if t.Emphasized && r.Color {
+		style = r.Theme.Highlight
+	}
+	if !r.Color {
+		style.Color = table.ColorOptions{}
+		style.Title.Colors = nil
+	}
+	tw.SetStyle(style)
+	tw.Style().Options.SeparateRows = t.SeparateRows
+	return r.fit(tw, t)
+}
+
+// fit renders tw, wrapping its widest columns until it fits r.Width.
+func (r PrettyRenderer) fit(tw table.Writer, t Table) string {
+	out := tw.Render()
+	if r.Width <= 0 || text.LongestLineLen(out) <= r.Width {
+		return out
+	}
+
+	var widths []int
+	measure := func(row []any) {
+		for i, v := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], text.StringWidthWithoutEscSequences(fmt.Sprint(v)))
+		}
+	}
+	if t.Header != nil {
+		measure(headerRow(t.Header))
+	}
+	for _, row := range t.Rows {
+		measure(row)
+	}
+
+	configs := make([]table.ColumnConfig, len(widths))
+	for i := range configs {
+		configs[i] = table.ColumnConfig{Number: i + 1}
+	}
+	for range widths {
+		over := text.LongestLineLen(out) - r.Width
+		if over <= 0 {
+			break
+		}
+		widest := 0
+		for i, wd := range widths {
+			if wd > widths[widest] {
+				widest = i
+			}
+		}
+		target := max(widths[widest]-over, minColumnWidth)
+		if target >= widths[widest] {
+			break
+		}
+		widths[widest] = target
+		configs[widest].WidthMax = target
+		configs[widest].WidthMaxEnforcer = text.WrapSoft
+		tw.SetColumnConfigs(configs)
+		out = tw.Render()
+	}
+	return out
+}
+
+func (r PrettyRenderer) code(b *strings.Builder, c Code) {
+	if c.Numbered {
+		t := Table{Title: c.Title, Header: []string{"#", "Message"}, SeparateRows: true}
+		for i, line := range c.Lines {
+			t.Rows = append(t.Rows, []any{i + 1, line})
+		}
+		if c.More != "" {
+			t.Rows = append(t.Rows, []any{"...", c.More})
+		}
+		b.WriteString(r.table(t) + "\n")
+		return
+	}
+	if c.Title != "" {
+		b.WriteString(r.paint(c.Title, ToneNone) + "\n")
+	}
+	for _, line := range c.Lines {
+		b.WriteString(line + "\n")
+	}
+	if c.More != "" {
+		b.WriteString("... " + c.More + "\n")
+	}
+}
+
+func headerRow(header []string) table.Row {
+	row := make(table.Row, len(header))
+	for i, h := range header {
+		row[i] = h
+	}
+	return row
+}
+
+// MarkdownRenderer writes documents as GitHub-flavored Markdown.
+type MarkdownRenderer struct{}
+
+// Render writes v's document in one write.
+func (MarkdownRenderer) Render(w io.Writer, v View) error {
+	var b strings.Builder
+	for _, blk := range v.Document() {
+		switch blk := blk.(type) {
+		case Banner:
+			fmt.Fprintf(&b, "\n## %s\n", blk.Title)
+		case Section:
+			fmt.Fprintf(&b, "\n### %s\n", blk.Title)
+		case Field:
+			fmt.Fprintf(&b, "- **%s:** %s\n", blk.Label, markdownCell(blk.Value))
+		case Table:
+			header := blk.Header
+			if header == nil {
+				// GitHub only renders tables with a header row
+				header = []string{"Field", "Value"}
+			}
+			tw := table.NewWriter()
+			tw.AppendHeader(headerRow(header))
+			for _, row := range blk.Rows {
+				if row == nil {
+					continue
+				}
+				out := make(table.Row, len(row))
+				for i, v := range row {
+					if c, ok := v.(Cell); ok {
+						v = markdownCell(c)
+					}
+					out[i] = v
+				}
+				tw.AppendRow(out)
+			}
+			if blk.Title != "" {
+				fmt.Fprintf(&b, "\n#### %s\n", blk.Title)
+			}
+			fmt.Fprintf(&b, "\n%s\n", tw.RenderMarkdown())
+		case Code:
+			if blk.Title != "" {
+				fmt.Fprintf(&b, "\n#### %s\n", blk.Title)
+			}
+			body := strings.Join(blk.Lines, "\n")
+			if blk.More != "" {
+				body += "\n... " + blk.More
+			}
+			fence := "```"
+			for strings.Contains(body, fence) {
+				fence += "`"
+			}
+			fmt.Fprintf(&b, "\n%s%s\n%s\n%s\n", fence, blk.Lang, body, fence)
+		case Note:
+			fmt.Fprintf(&b, "\n_%s_\n", blk.Text)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell sets addresses and signatures as code, linked to the
+// explorer when the cell has a link.
+func markdownCell(c Cell) string {
+	s := c.Text
+	if c.Full != "" || c.Link != "" {
+		s = "`" + s + "`"
+	}
+	if c.Link != "" {
+		s = "[" + s + "](" + c.Link + ")"
+	}
+	return s
+}
+
+// CSVRenderer writes a single CSV table to a stream: the main table of the
+// first view that has one (its emphasized table, else its first), then the
+// rows of later views whose main table has the same columns. Other tables,
+// headings, fields and code are left out, so one stream never mixes
+// headers. Cells hold full addresses and signatures. Use one renderer per
+// stream; it is safe for concurrent use.
+type CSVRenderer struct {
+	mu     sync.Mutex
+	header []string
+}
+
+// Render writes the rows of v's main table in one write.
+func (r *CSVRenderer) Render(w io.Writer, v View) error {
+	t, ok := mainTable(v.Document())
+	if !ok {
+		return nil
+	}
+	header := t.Header
+	if header == nil {
+		header = []string{"field", "value"}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	switch {
+	case r.header == nil:
+		r.header = header
+		cw.Write(header)
+	case !slices.Equal(r.header, header):
+		return nil
+	}
+	for _, row := range t.Rows {
+		if row == nil {
+			continue
+		}
+		record := make([]string, len(row))
+		for i, v := range row {
+			if c, ok := v.(Cell); ok && c.Full != "" {
+				record[i] = c.Full
+			} else {
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// mainTable returns doc's emphasized table, or else its first one.
+func mainTable(doc Document) (Table, bool) {
+	var first *Table
+	for _, blk := range doc {
+		t, ok := blk.(Table)
+		if !ok {
+			continue
+		}
+		if t.Emphasized {
+			return t, true
+		}
+		if first == nil {
+			first = &t
+		}
+	}
+	if first == nil {
+		return Table{}, false
+	}
+	return *first, true
+}
+
+// JSONRenderer writes newline-delimited JSON: each view as one compact line
+// of the form {"view": name, "data": view}, so a command writing several
+// views stays a valid NDJSON stream.
+type JSONRenderer struct{}
+
+// Render encodes v in one write.
+func (JSONRenderer) Render(w io.Writer, v View) error {
+	b, err := json.Marshal(struct {
+		View string `json:"view"`
+		Data View   `json:"data"`
+	}{v.ViewName(), v})
+	if err != nil {
+		return fmt.Errorf("encode %s view: %w", v.ViewName(), err)
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
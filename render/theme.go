@@ -0,0 +1,123 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// Theme is the look of the pretty renderer.
+type Theme struct {
+	Name string
+	// Highlight styles the main table of a view, Table every other one.
+	Highlight table.Style
+	Table     table.Style
+	// Banners colors view headings and Text everything else, by tone.
+	Banners map[Tone]text.Colors
+	Text    map[Tone]text.Colors
+}
+
+var (
+	// DefaultTheme is bright tables with colored banners.
+	DefaultTheme = Theme{
+		Name:      "default",
+		Highlight: table.StyleColoredBright,
+		Table:     table.StyleLight,
+		Banners: map[Tone]text.Colors{
+			ToneNone:     {text.BgBlue, text.FgWhite},
+			ToneInfo:     {text.BgBlue, text.FgWhite},
+			TonePositive: {text.BgGreen, text.FgWhite},
+			ToneNegative: {text.BgRed, text.FgWhite},
+			ToneWarning:  {text.BgYellow, text.FgBlack},
+			ToneAccent:   {text.BgMagenta, text.FgWhite},
+		},
+		Text: map[Tone]text.Colors{
+			ToneInfo:     {text.FgCyan},
+			TonePositive: {text.FgGreen},
+			ToneNegative: {text.FgRed},
+			ToneWarning:  {text.FgYellow},
+			ToneAccent:   {text.FgMagenta},
+		},
+	}
+
+	// DarkTheme suits dark terminals: muted tables and high-intensity text.
+	DarkTheme = Theme{
+		Name:      "dark",
+		Highlight: table.StyleColoredDark,
+		Table:     table.StyleRounded,
+		Banners: map[Tone]text.Colors{
+			ToneNone:     {text.BgHiBlack, text.FgHiWhite},
+			ToneInfo:     {text.BgHiBlue, text.FgBlack},
+			TonePositive: {text.BgHiGreen, text.FgBlack},
+			ToneNegative: {text.BgHiRed, text.FgBlack},
+			ToneWarning:  {text.BgHiYellow, text.FgBlack},
+			ToneAccent:   {text.BgHiMagenta, text.FgBlack},
+		},
+		Text: map[Tone]text.Colors{
+			ToneInfo:     {text.FgHiCyan},
+			TonePositive: {text.FgHiGreen},
+			ToneNegative: {text.FgHiRed},
+			ToneWarning:  {text.FgHiYellow},
+			ToneAccent:   {text.FgHiMagenta},
+		},
+	}
+
+	// MinimalTheme draws thin borders and colors only gains and losses.
+	MinimalTheme = Theme{
+		Name:      "minimal",
+		Highlight: table.StyleLight,
+		Table:     table.StyleLight,
+		Banners: map[Tone]text.Colors{
+			ToneNone: {text.Bold, text.Underline},
+		},
+		Text: map[Tone]text.Colors{
+			TonePositive: {text.FgGreen},
+			ToneNegative: {text.FgRed},
+		},
+	}
+
+	// ASCIITheme avoids box-drawing characters, for fonts and logs that
+	// cannot show them.
+	ASCIITheme = Theme{
+		Name:      "ascii",
+		Highlight: table.StyleDefault,
+		Table:     table.StyleDefault,
+		Banners: map[Tone]text.Colors{
+			ToneNone: {text.Bold},
+		},
+		Text: map[Tone]text.Colors{
+			TonePositive: {text.FgGreen},
+			ToneNegative: {text.FgRed},
+		},
+	}
+
+	// Themes lists the built-in themes.
+	Themes = []Theme{DefaultTheme, DarkTheme, MinimalTheme, ASCIITheme}
+)
+
+// LookupTheme returns the built-in theme called name; empty selects the
+// default theme.
+func LookupTheme(name string) (Theme, error) {
+	if name == "" {
+		return DefaultTheme, nil
+	}
+	names := make([]string, len(Themes))
+	for i, t := range Themes {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+		names[i] = t.Name
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// banner returns the colors of a view heading, falling back to the
+// theme's neutral banner.
+func (t Theme) banner(tone Tone) text.Colors {
+	if c, ok := t.Banners[tone]; ok {
+		return c
+	}
+	return t.Banners[ToneNone]
+}
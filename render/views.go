@@ -0,0 +1,875 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"go-solana-tx-explorer/addressbook"
+	"go-solana-tx-explorer/classify"
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+	"go-solana-tx-explorer/portfolio"
+	"go-solana-tx-explorer/rpc/client"
+)
+
+// SummaryView lists an account's transactions, one row each.
+type SummaryView struct {
+	Account      solana.PublicKey `json:"account"`
+	LastFetched  time.Time        `json:"last_fetched"`
+	Transactions []SummaryRow     `json:"transactions"`
+
+	cluster *client.Cluster
+	labels  *addressbook.Book
+}
+
+// SummaryRow is one transaction of a SummaryView.
+type SummaryRow struct {
+	Signature string     `json:"signature"`
+	Status    string     `json:"status"` // success or failed
+	Type      string     `json:"type"`
+	Direction string     `json:"direction,omitempty"`
+	Slot      uint64     `json:"slot"`
+	BlockTime *time.Time `json:"block_time,omitempty"`
+	Fee       uint64     `json:"fee"`
+	// BalanceChange is the fee payer's SOL change in lamports.
+	BalanceChange int64  `json:"balance_change"`
+	Explorer      string `json:"explorer,omitempty"`
+}
+
+// NewSummaryView classifies accountTxs relative to their account.
+func NewSummaryView(accountTxs *fetch.AccountTransactions, cluster *client.Cluster, labels *addressbook.Book) SummaryView {
+	v := SummaryView{
+		Account:      accountTxs.Account,
+		LastFetched:  accountTxs.LastFetched,
+		Transactions: make([]SummaryRow, 0, len(accountTxs.Transactions)),
+		cluster:      cluster,
+		labels:       labels,
+	}
+	for _, tx := range accountTxs.Transactions {
+		c := classify.ClassifyTransaction(tx, accountTxs.Account)
+		row := SummaryRow{
+			Signature: tx.Signature,
+			Status:    "success",
+			Type:      string(c.Type),
+			Direction: c.Direction,
+			Slot:      tx.Slot,
+			BlockTime: blockTime(tx),
+		}
+		if tx.Meta != nil {
+			if tx.Meta.Err != nil {
+				row.Status = "failed"
+			}
+			row.Fee = tx.Meta.Fee
+			if len(tx.Meta.PreBalances) > 0 && len(tx.Meta.PostBalances) > 0 {
+				row.BalanceChange = int64(tx.Meta.PostBalances[0]) - int64(tx.Meta.PreBalances[0])
+			}
+		}
+		if cluster != nil {
+			row.Explorer = cluster.TxURL(tx.Signature)
+		}
+		v.Transactions = append(v.Transactions, row)
+	}
+	return v
+}
+
+func (v SummaryView) ViewName() string { return "transaction_summary" }
+
+func (v SummaryView) Document() Document {
+	account := AccountCell(v.cluster, v.labels, v.Account, "")
+	account.Tone = ToneInfo
+	doc := Document{
+		Banner{Title: "SOLANA TRANSACTION EXPLORER", Tone: ToneInfo},
+		Field{Label: "Account", Value: account},
+		Field{Label: "Total Transactions", Value: Cell{Text: strconv.Itoa(len(v.Transactions)), Tone: TonePositive}},
+		Field{Label: "Last Fetched", Value: Cell{Text: v.LastFetched.Format(time.RFC3339), Tone: ToneWarning}},
+	}
+
+	t := Table{
+		Title:        "Transaction Summary",
+		Header:       []string{"#", "Signature (Short)", "Status", "Type", "Slot", "Time", "Fee (SOL)", "Balance Change"},
+		Emphasized:   true,
+		SeparateRows: true,
+	}
+	for i, row := range v.Transactions {
+		status := "✅ SUCCESS"
+		if row.Status == "failed" {
+			status = "❌ FAILED"
+		}
+		timeStr := "N/A"
+		if row.BlockTime != nil {
+			timeStr = row.BlockTime.Local().Format("01-02 15:04")
+		}
+		txType := row.Type
+		if row.Direction != "" {
+			txType += " (" + row.Direction + ")"
+		}
+		change := "0"
+		if row.BalanceChange != 0 {
+			change = fmt.Sprintf("%+.6f", float64(row.BalanceChange)/1e9)
+		}
+		t.Rows = append(t.Rows, []any{
+			i + 1,
+			TxCell(v.cluster, row.Signature, ShortAddress(row.Signature)),
+			status,
+			txType,
+			row.Slot,
+			timeStr,
+			fmt.Sprintf("%.6f", float64(row.Fee)/1e9),
+			change,
+		})
+	}
+	return append(doc, t)
+}
+
+// FeeStatsView is aggregate fee and compute usage.
+type FeeStatsView struct {
+	decode.FeeStats
+}
+
+func (v FeeStatsView) ViewName() string { return "fee_stats" }
+
+func (v FeeStatsView) Document() Document {
+	s := v.FeeStats
+	return Document{
+		Banner{Title: "FEE & COMPUTE STATS", Tone: ToneWarning},
+		Table{
+			Title: fmt.Sprintf("Across %d Transactions", s.Transactions),
+			Rows: [][]any{
+				{"Total Fees (SOL)", fmt.Sprintf("%.9f", float64(s.TotalFee)/1e9)},
+				{"Base Fees (SOL)", fmt.Sprintf("%.9f", float64(s.TotalBaseFee)/1e9)},
+				{"Priority Fees (SOL)", fmt.Sprintf("%.9f", float64(s.TotalPriorityFee)/1e9)},
+				{"Txs With Priority Fee", fmt.Sprintf("%d / %d", s.WithPriorityFee, s.Transactions)},
+				nil,
+				{"Priority Fee p50 (lamports)", s.PriorityFeeP50},
+				{"Priority Fee p75 (lamports)", s.PriorityFeeP75},
+				{"Priority Fee p90 (lamports)", s.PriorityFeeP90},
+				{"Priority Fee p99 (lamports)", s.PriorityFeeP99},
+				{"Priority Fee max (lamports)", s.PriorityFeeMax},
+				{"CU Price p50 / p90 (µlamports)", fmt.Sprintf("%d / %d", s.UnitPriceP50, s.UnitPriceP90)},
+				nil,
+				{"CU Requested", s.TotalRequestedCU},
+				{"CU Consumed", s.TotalConsumedCU},
+				{"CU Unused", s.TotalWastedCU},
+				{"Avg CU Utilization", fmt.Sprintf("%.1f%%", s.AvgUtilizationPct)},
+				{"Txs Without CU Limit", s.WithoutUnitLimit},
+			},
+		},
+	}
+}
+
+// DetailsView is everything known about one transaction.
+type DetailsView struct {
+	Index     int                  `json:"index"`
+	Signature string               `json:"signature,omitempty"`
+	Slot      uint64               `json:"slot,omitempty"`
+	BlockTime *time.Time           `json:"block_time,omitempty"`
+	Explorer  string               `json:"explorer,omitempty"`
+	Meta      *MetaView            `json:"meta,omitempty"`
+	Fee       *decode.FeeBreakdown `json:"fee,omitempty"`
+	Message   *MessageView         `json:"message,omitempty"`
+	// Labels names the labeled accounts of the transaction, by address.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	full    bool
+	cluster *client.Cluster
+	labels  *addressbook.Book
+}
+
+// MetaView is the execution result of a transaction.
+type MetaView struct {
+	Fee            uint64               `json:"fee"`
+	Status         string               `json:"status"` // success or failed
+	Error          any                  `json:"error,omitempty"`
+	ComputeUnits   *uint64              `json:"compute_units,omitempty"`
+	BalanceChanges []BalanceChange      `json:"balance_changes,omitempty"`
+	TokenBalances  []TokenBalanceChange `json:"token_balances,omitempty"`
+	Logs           []string             `json:"logs,omitempty"`
+}
+
+// MessageView is the signed message of a transaction.
+type MessageView struct {
+	Version             string             `json:"version"` // legacy or v0
+	RecentBlockhash     solana.Hash        `json:"recent_blockhash"`
+	RequiredSignatures  uint8              `json:"required_signatures"`
+	ReadonlySigned      uint8              `json:"readonly_signed"`
+	ReadonlyUnsigned    uint8              `json:"readonly_unsigned"`
+	Signers             []SignerView       `json:"signers,omitempty"`
+	AccountKeys         []solana.PublicKey `json:"account_keys"`
+	AddressTableLookups []LookupView       `json:"address_table_lookups,omitempty"`
+	Instructions        []InstructionView  `json:"instructions"`
+}
+
+// SignerView is a required signer and its signature, empty when missing.
+type SignerView struct {
+	PublicKey solana.PublicKey `json:"public_key"`
+	Role      string           `json:"role"`
+	Signature string           `json:"signature,omitempty"`
+}
+
+// LookupView is an address lookup table a v0 message loads accounts from.
+type LookupView struct {
+	Table           solana.PublicKey `json:"table"`
+	WritableIndexes []int            `json:"writable_indexes"`
+	ReadonlyIndexes []int            `json:"readonly_indexes"`
+}
+
+// InstructionView is a top-level instruction. The decoded fields are empty
+// when ProgramIndex is not one of the message's account keys.
+type InstructionView struct {
+	ProgramIndex uint16 `json:"program_index"`
+	decode.DecodedInstruction
+	Accounts []uint16 `json:"accounts"`
+	DataSize int      `json:"data_size"`
+}
+
+// NewDetailsView collects tx for display as the index-th transaction of a
+// list. Without full the document shows the first few logs, accounts and
+// instructions. Token accounts of the wallets in labels are labeled first.
+func NewDetailsView(tx decode.TransactionInfo, index int, cluster *client.Cluster, labels *addressbook.Book, full bool) DetailsView {
+	labels.LabelTokenAccounts(tx)
+	v := DetailsView{
+		Index:     index,
+		Signature: tx.Signature,
+		Slot:      tx.Slot,
+		BlockTime: blockTime(tx),
+		Labels:    accountLabels(tx, labels),
+		full:      full,
+		cluster:   cluster,
+		labels:    labels,
+	}
+	if cluster != nil {
+		v.Explorer = cluster.TxURL(tx.Signature)
+	}
+	if meta := tx.Meta; meta != nil {
+		v.Meta = &MetaView{
+			Fee:            meta.Fee,
+			Status:         "success",
+			ComputeUnits:   meta.ComputeUnitsConsumed,
+			BalanceChanges: SOLBalanceChanges(tx),
+			TokenBalances:  TokenBalanceChanges(tx),
+			Logs:           meta.LogMessages,
+		}
+		if meta.Err != nil {
+			v.Meta.Status, v.Meta.Error = "failed", meta.Err
+		}
+		v.Fee = decode.AnalyzeFee(tx)
+	}
+	if tx.Transaction != nil {
+		v.Message = newMessageView(tx.Transaction)
+	}
+	return v
+}
+
+// accountLabels collects the labels of tx's accounts, mints and token
+// account owners.
+func accountLabels(tx decode.TransactionInfo, labels *addressbook.Book) map[string]string {
+	addresses := classify.TransactionAccountKeys(tx)
+	if tx.Meta != nil {
+		for _, tb := range tx.Meta.PostTokenBalances {
+			addresses = append(addresses, tb.Mint)
+			if tb.Owner != nil {
+				addresses = append(addresses, *tb.Owner)
+			}
+		}
+	}
+	var out map[string]string
+	for _, a := range addresses {
+		if name := labels.Name(a); name != "" {
+			if out == nil {
+				out = make(map[string]string)
+			}
+			out[a.String()] = name
+		}
+	}
+	return out
+}
+
+func newMessageView(tx *solana.Transaction) *MessageView {
+	msg := tx.Message
+	m := &MessageView{
+		Version:            "legacy",
+		RecentBlockhash:    msg.RecentBlockhash,
+		RequiredSignatures: msg.Header.NumRequiredSignatures,
+		ReadonlySigned:     msg.Header.NumReadonlySignedAccounts,
+		ReadonlyUnsigned:   msg.Header.NumReadonlyUnsignedAccounts,
+		AccountKeys:        msg.AccountKeys,
+	}
+	if msg.IsVersioned() {
+		m.Version = "v0"
+	}
+
+	for i, signer := range msg.Signers() {
+		s := SignerView{PublicKey: signer, Role: "signer"}
+		if i == 0 {
+			s.Role = "fee payer"
+		}
+		if writable, err := tx.IsWritable(signer); err == nil && writable {
+			s.Role += ", writable"
+		}
+		if i < len(tx.Signatures) && !tx.Signatures[i].IsZero() {
+			s.Signature = tx.Signatures[i].String()
+		}
+		m.Signers = append(m.Signers, s)
+	}
+
+	for _, l := range msg.AddressTableLookups {
+		lv := LookupView{Table: l.AccountKey}
+		for _, idx := range l.WritableIndexes {
+			lv.WritableIndexes = append(lv.WritableIndexes, int(idx))
+		}
+		for _, idx := range l.ReadonlyIndexes {
+			lv.ReadonlyIndexes = append(lv.ReadonlyIndexes, int(idx))
+		}
+		m.AddressTableLookups = append(m.AddressTableLookups, lv)
+	}
+
+	for _, instr := range msg.Instructions {
+		iv := InstructionView{
+			ProgramIndex: instr.ProgramIDIndex,
+			Accounts:     instr.Accounts,
+			DataSize:     len(instr.Data),
+		}
+		if int(instr.ProgramIDIndex) < len(msg.AccountKeys) {
+			iv.DecodedInstruction = decode.DecodeInstruction(msg.AccountKeys[instr.ProgramIDIndex], instr.Data)
+		}
+		m.Instructions = append(m.Instructions, iv)
+	}
+	return m
+}
+
+func (v DetailsView) ViewName() string { return "transaction_details" }
+
+func (v DetailsView) Document() Document {
+	basic := Table{
+		Title:      "Basic Information",
+		Rows:       [][]any{{"Signature", TxCell(v.cluster, v.Signature, "")}},
+		Emphasized: true,
+	}
+	if v.Slot > 0 {
+		basic.Rows = append(basic.Rows, []any{"Slot", v.Slot})
+	}
+	if v.BlockTime != nil {
+		basic.Rows = append(basic.Rows, []any{"Block Time", v.BlockTime.Local().Format(time.RFC3339)})
+	}
+	if v.Explorer != "" {
+		basic.Rows = append(basic.Rows, []any{"Explorer", v.Explorer})
+	}
+	doc := Document{
+		Banner{Title: fmt.Sprintf("TRANSACTION #%d DETAILS", v.Index+1), Tone: TonePositive},
+		basic,
+	}
+
+	if v.Meta != nil {
+		doc = append(doc, v.metaBlocks()...)
+	}
+	if v.Fee != nil {
+		doc = append(doc, v.computeBudgetBlocks()...)
+	}
+	if v.Message != nil {
+		doc = append(doc, v.messageBlocks()...)
+	}
+	return doc
+}
+
+func (v DetailsView) metaBlocks() []Block {
+	m := v.Meta
+	status := "SUCCESS ✅"
+	if m.Error != nil {
+		status = fmt.Sprintf("FAILED ❌ - %v", m.Error)
+	}
+	meta := Table{
+		Title: "Meta Information",
+		Rows: [][]any{
+			{"Fee (lamports)", fmt.Sprintf("%d", m.Fee)},
+			{"Fee (SOL)", fmt.Sprintf("%.9f", float64(m.Fee)/1e9)},
+			{"Status", status},
+		},
+	}
+	if m.ComputeUnits != nil {
+		meta.Rows = append(meta.Rows, []any{"Compute Units", fmt.Sprintf("%d", *m.ComputeUnits)})
+	}
+	blocks := []Block{Section{Title: "💰 TRANSACTION META", Tone: ToneWarning}, meta}
+
+	if len(m.BalanceChanges) > 0 {
+		t := Table{Title: "Account Balance Changes", Header: []string{"Index", "Account", "Pre (SOL)", "Post (SOL)", "Change (SOL)"}}
+		for _, c := range m.BalanceChanges {
+			tone := TonePositive
+			if c.Delta() < 0 {
+				tone = ToneNegative
+			}
+			t.Rows = append(t.Rows, []any{
+				c.Index,
+				AccountCell(v.cluster, v.labels, c.Account, ShortAddress(c.Account.String())),
+				fmt.Sprintf("%.6f", float64(c.Pre)/1e9),
+				fmt.Sprintf("%.6f", float64(c.Post)/1e9),
+				Cell{Text: fmt.Sprintf("%+.6f", float64(c.Delta())/1e9), Tone: tone},
+			})
+		}
+		blocks = append(blocks, Section{Title: "📊 SOL BALANCE CHANGES", Tone: ToneInfo}, t)
+	}
+
+	if len(m.TokenBalances) > 0 {
+		t := Table{Title: "Token Information", Header: []string{"Account", "Owner", "Mint", "Amount", "Decimals"}}
+		for _, tb := range m.TokenBalances {
+			owner := any("—")
+			if tb.Owner != nil {
+				owner = AccountCell(v.cluster, v.labels, *tb.Owner, ShortAddress(tb.Owner.String()))
+			}
+			t.Rows = append(t.Rows, []any{
+				AccountCell(v.cluster, v.labels, tb.Account, ShortAddress(tb.Account.String())),
+				owner,
+				AccountCell(v.cluster, v.labels, tb.Mint, ShortAddress(tb.Mint.String())),
+				tb.Post,
+				tb.Decimals,
+			})
+		}
+		blocks = append(blocks, Section{Title: "🪙 TOKEN BALANCES", Tone: ToneAccent}, t)
+	}
+
+	if len(m.Logs) > 0 {
+		maxLogs := 5
+		if v.full {
+			maxLogs = len(m.Logs)
+		}
+		logs := Code{Title: "Program Execution Logs", Lang: "text", Numbered: true}
+		for i, logMsg := range m.Logs {
+			if i >= maxLogs {
+				break
+			}
+			// Truncate very long log messages
+			if len(logMsg) > 80 && !v.full {
+				logMsg = logMsg[:77] + "..."
+			}
+			logs.Lines = append(logs.Lines, logMsg)
+		}
+		if len(m.Logs) > maxLogs {
+			logs.More = fmt.Sprintf("and %d more logs", len(m.Logs)-maxLogs)
+		}
+		blocks = append(blocks, Section{Title: "📝 PROGRAM LOGS", Tone: ToneWarning}, logs)
+	}
+	return blocks
+}
+
+func (v DetailsView) computeBudgetBlocks() []Block {
+	fb := v.Fee
+	t := Table{
+		Title: "Fee Breakdown",
+		Rows: [][]any{
+			{"Signatures", fb.Signatures},
+			{"Base Fee (lamports)", fb.BaseFee},
+			{"Priority Fee (lamports)", fb.PriorityFee},
+		},
+	}
+	if fb.Budget.UnitPrice != nil {
+		t.Rows = append(t.Rows, []any{"CU Price (µlamports)", *fb.Budget.UnitPrice})
+	}
+	limit := "default"
+	if fb.Budget.UnitLimit != nil {
+		limit = fmt.Sprintf("%d", *fb.Budget.UnitLimit)
+	}
+	t.Rows = append(t.Rows, []any{"CU Limit (instruction)", limit}, []any{"CU Requested", fb.RequestedCU})
+	if fb.ConsumedCU != nil {
+		t.Rows = append(t.Rows, []any{"CU Consumed", *fb.ConsumedCU})
+		if fb.RequestedCU > 0 {
+			t.Rows = append(t.Rows,
+				[]any{"CU Utilization", fmt.Sprintf("%.1f%%", fb.UtilizationPct)},
+				[]any{"CU Unused", fb.WastedCU})
+		}
+	}
+	if fb.Budget.HeapFrameBytes != nil {
+		t.Rows = append(t.Rows, []any{"Heap Frame (bytes)", *fb.Budget.HeapFrameBytes})
+	}
+	if fb.Budget.LoadedAccountsDataMax != nil {
+		t.Rows = append(t.Rows, []any{"Loaded Data Limit (bytes)", *fb.Budget.LoadedAccountsDataMax})
+	}
+	return []Block{Section{Title: "⛽ COMPUTE BUDGET", Tone: ToneWarning}, t}
+}
+
+func (v DetailsView) messageBlocks() []Block {
+	m := v.Message
+	info := Table{
+		Title: "Message Information",
+		Rows: [][]any{
+			{"Version", m.Version},
+			{"Recent Blockhash", m.RecentBlockhash.String()},
+			{"Required Signatures", m.RequiredSignatures},
+			{"Readonly Signed", m.ReadonlySigned},
+			{"Readonly Unsigned", m.ReadonlyUnsigned},
+			{"Total Accounts", len(m.AccountKeys)},
+			{"Total Instructions", len(m.Instructions)},
+		},
+	}
+	if m.Version == "v0" {
+		info.Rows = append(info.Rows, []any{"Address Table Lookups", len(m.AddressTableLookups)})
+	}
+	blocks := []Block{Section{Title: "📄 TRANSACTION MESSAGE", Tone: ToneInfo}, info}
+
+	if len(m.Signers) > 0 {
+		t := Table{Title: "Required Signers", Header: []string{"#", "Public Key", "Role", "Signature"}}
+		for i, s := range m.Signers {
+			sig := Cell{Text: "missing"}
+			if s.Signature != "" {
+				sig = Cell{Text: s.Signature, Full: s.Signature}
+				if !v.full {
+					sig.Text = ShortAddress(s.Signature)
+				}
+			}
+			t.Rows = append(t.Rows, []any{i, AccountCell(v.cluster, v.labels, s.PublicKey, ""), s.Role, sig})
+		}
+		blocks = append(blocks, Section{Title: "✍️ SIGNERS", Tone: TonePositive}, t)
+	}
+
+	if len(m.AccountKeys) > 0 {
+		maxAccounts := 5
+		if v.full {
+			maxAccounts = len(m.AccountKeys)
+		}
+		t := Table{Title: "Transaction Account Keys", Header: []string{"Index", "Public Key"}}
+		for i, account := range m.AccountKeys {
+			if i >= maxAccounts {
+				break
+			}
+			t.Rows = append(t.Rows, []any{i, AccountCell(v.cluster, v.labels, account, "")})
+		}
+		if len(m.AccountKeys) > maxAccounts {
+			t.Rows = append(t.Rows, []any{"...", fmt.Sprintf("and %d more accounts", len(m.AccountKeys)-maxAccounts)})
+		}
+		blocks = append(blocks, Section{Title: "🔑 ACCOUNT KEYS", Tone: TonePositive}, t)
+	}
+
+	if len(m.AddressTableLookups) > 0 {
+		t := Table{Title: "Address Table Lookups", Header: []string{"Table", "Writable Indexes", "Readonly Indexes"}}
+		for _, l := range m.AddressTableLookups {
+			t.Rows = append(t.Rows, []any{
+				AccountCell(v.cluster, v.labels, l.Table, ""),
+				fmt.Sprintf("%v", l.WritableIndexes),
+				fmt.Sprintf("%v", l.ReadonlyIndexes),
+			})
+		}
+		blocks = append(blocks, Section{Title: "📚 ADDRESS LOOKUP TABLES", Tone: ToneInfo}, t)
+	}
+
+	if len(m.Instructions) > 0 {
+		blocks = append(blocks, Section{Title: "⚙️ INSTRUCTIONS", Tone: ToneNegative}, v.instructionTable())
+	}
+	return blocks
+}
+
+func (v DetailsView) instructionTable() Table {
+	m := v.Message
+	t := Table{Title: "Transaction Instructions", Header: []string{"#", "Program", "Instruction", "Accounts", "Data Size"}}
+
+	maxInstr := 3
+	if v.full {
+		maxInstr = len(m.Instructions)
+	}
+	for i, instr := range m.Instructions {
+		if i >= maxInstr {
+			break
+		}
+
+		var program any = "Unknown"
+		instrName := ""
+		if int(instr.ProgramIndex) < len(m.AccountKeys) {
+			program = instr.Program
+			if instr.Program == "" {
+				program = AccountCell(v.cluster, v.labels, instr.ProgramID, instr.ProgramID.String()[:8]+"...")
+			}
+			instrName = instr.Name
+			if instr.Details != "" {
+				instrName += " (" + instr.Details + ")"
+			}
+			if len(instrName) > 48 && !v.full {
+				instrName = instrName[:45] + "..."
+			}
+		}
+
+		accounts := fmt.Sprintf("%v", instr.Accounts)
+		if len(accounts) > 20 {
+			accounts = accounts[:17] + "..."
+		}
+		t.Rows = append(t.Rows, []any{i + 1, program, instrName, accounts, fmt.Sprintf("%d bytes", instr.DataSize)})
+	}
+	if len(m.Instructions) > maxInstr {
+		t.Rows = append(t.Rows, []any{"...", fmt.Sprintf("and %d more instructions", len(m.Instructions)-maxInstr), "", "", ""})
+	}
+	return t
+}
+
+// BalanceChange is one account's SOL balance before and after a
+// transaction, in lamports. Index is the account's position in the
+// transaction's account keys.
+type BalanceChange struct {
+	Index   int              `json:"index"`
+	Account solana.PublicKey `json:"account"`
+	Pre     uint64           `json:"pre"`
+	Post    uint64           `json:"post"`
+}
+
+// Delta is the change in lamports.
+func (c BalanceChange) Delta() int64 {
+	return int64(c.Post) - int64(c.Pre)
+}
+
+// SOLBalanceChanges lists the accounts whose SOL balance tx changed, in
+// account key order.
+func SOLBalanceChanges(tx decode.TransactionInfo) []BalanceChange {
+	if tx.Meta == nil {
+		return nil
+	}
+	keys := classify.TransactionAccountKeys(tx)
+	var out []BalanceChange
+	for i, pre := range tx.Meta.PreBalances {
+		if i >= len(tx.Meta.PostBalances) || i >= len(keys) {
+			break
+		}
+		if post := tx.Meta.PostBalances[i]; post != pre {
+			out = append(out, BalanceChange{Index: i, Account: keys[i], Pre: pre, Post: post})
+		}
+	}
+	return out
+}
+
+// TokenBalanceChange is one token account's balance before and after a
+// transaction, as UI amount strings.
+type TokenBalanceChange struct {
+	Account  solana.PublicKey  `json:"account"`
+	Mint     solana.PublicKey  `json:"mint"`
+	Owner    *solana.PublicKey `json:"owner,omitempty"`
+	Pre      string            `json:"pre"`
+	Post     string            `json:"post"`
+	Decimals uint8             `json:"decimals"`
+}
+
+// TokenBalanceChanges lists every token account balance tx reports after
+// execution, with its balance before ("0" for accounts it created).
+func TokenBalanceChanges(tx decode.TransactionInfo) []TokenBalanceChange {
+	if tx.Meta == nil {
+		return nil
+	}
+	pre := make(map[uint16]string, len(tx.Meta.PreTokenBalances))
+	for _, tb := range tx.Meta.PreTokenBalances {
+		if tb.UiTokenAmount != nil {
+			pre[tb.AccountIndex] = tb.UiTokenAmount.UiAmountString
+		}
+	}
+	keys := classify.TransactionAccountKeys(tx)
+	var out []TokenBalanceChange
+	for _, tb := range tx.Meta.PostTokenBalances {
+		if tb.UiTokenAmount == nil || int(tb.AccountIndex) >= len(keys) {
+			continue
+		}
+		before, ok := pre[tb.AccountIndex]
+		if !ok {
+			before = "0"
+		}
+		out = append(out, TokenBalanceChange{
+			Account:  keys[tb.AccountIndex],
+			Mint:     tb.Mint,
+			Owner:    tb.Owner,
+			Pre:      before,
+			Post:     tb.UiTokenAmount.UiAmountString,
+			Decimals: tb.UiTokenAmount.Decimals,
+		})
+	}
+	return out
+}
+
+// SimulationView is the outcome of a simulated transaction.
+type SimulationView struct {
+	Slot   uint64  `json:"slot"`
+	Status string  `json:"status"` // success or failed
+	Error  any     `json:"error,omitempty"`
+	Fee    *uint64 `json:"fee,omitempty"`
+}
+
+// NewSimulationView summarizes a simulation result.
+func NewSimulationView(tx *decode.TransactionInfo) SimulationView {
+	v := SimulationView{Slot: tx.Slot, Status: "success"}
+	if tx.Meta != nil {
+		if tx.Meta.Err != nil {
+			v.Status, v.Error = "failed", tx.Meta.Err
+		}
+		fee := tx.Meta.Fee
+		v.Fee = &fee
+	}
+	return v
+}
+
+func (v SimulationView) ViewName() string { return "simulation" }
+
+func (v SimulationView) Document() Document {
+	outcome := Cell{Text: "would succeed ✅", Tone: TonePositive}
+	if v.Error != nil {
+		outcome = Cell{Text: fmt.Sprintf("would fail ❌ - %v", v.Error), Tone: ToneNegative}
+	}
+	doc := Document{
+		Banner{Title: "TRANSACTION SIMULATION", Tone: ToneAccent},
+		Field{Label: "Simulated at slot", Value: Cell{Text: strconv.FormatUint(v.Slot, 10), Tone: ToneInfo}},
+		Field{Label: "Outcome", Value: outcome},
+	}
+	if v.Fee != nil {
+		doc = append(doc, Field{Label: "Estimated Fee", Value: Cell{Text: fmt.Sprintf("%.9f SOL", float64(*v.Fee)/1e9), Tone: ToneWarning}})
+	}
+	return doc
+}
+
+// SignatureChecksView is the result of local signature verification.
+type SignatureChecksView struct {
+	Checks []decode.SignatureCheck `json:"checks"`
+
+	cluster *client.Cluster
+	labels  *addressbook.Book
+}
+
+// NewSignatureChecksView wraps the results of decode.VerifyTransactionSignatures.
+func NewSignatureChecksView(checks []decode.SignatureCheck, cluster *client.Cluster, labels *addressbook.Book) SignatureChecksView {
+	return SignatureChecksView{Checks: checks, cluster: cluster, labels: labels}
+}
+
+func (v SignatureChecksView) ViewName() string { return "signature_checks" }
+
+func (v SignatureChecksView) Document() Document {
+	t := Table{Title: "Local Signature Checks", Header: []string{"#", "Signer", "Status"}}
+	for i, c := range v.Checks {
+		status := Cell{Text: c.Status}
+		switch c.Status {
+		case "valid":
+			status = Cell{Text: "✅ valid", Tone: TonePositive}
+		case "invalid":
+			status = Cell{Text: "❌ invalid", Tone: ToneNegative}
+		case "missing":
+			status = Cell{Text: "⏳ missing", Tone: ToneWarning}
+		}
+		t.Rows = append(t.Rows, []any{i, AccountCell(v.cluster, v.labels, c.Signer, ""), status})
+	}
+	return Document{Section{Title: "🔏 SIGNATURE VERIFICATION", Tone: TonePositive}, t}
+}
+
+// PortfolioView is a wallet's SPL token holdings.
+type PortfolioView struct {
+	Owner    solana.PublicKey         `json:"owner"`
+	Explorer string                   `json:"explorer,omitempty"`
+	Holdings []portfolio.TokenHolding `json:"holdings"`
+
+	cluster *client.Cluster
+	labels  *addressbook.Book
+}
+
+// NewPortfolioView pairs owner with its holdings.
+func NewPortfolioView(owner solana.PublicKey, tokens []portfolio.TokenHolding, cluster *client.Cluster, labels *addressbook.Book) PortfolioView {
+	v := PortfolioView{Owner: owner, Holdings: tokens, cluster: cluster, labels: labels}
+	if cluster != nil {
+		v.Explorer = cluster.AccountURL(owner.String())
+	}
+	return v
+}
+
+func (v PortfolioView) ViewName() string { return "portfolio" }
+
+func (v PortfolioView) Document() Document {
+	owner := AccountCell(v.cluster, v.labels, v.Owner, "")
+	owner.Tone = ToneInfo
+	doc := Document{
+		Banner{Title: "USER TOKEN PORTFOLIO", Tone: ToneInfo},
+		Field{Label: "Owner", Value: owner},
+	}
+	if v.Explorer != "" {
+		doc = append(doc, Field{Label: "Explorer", Value: Cell{Text: v.Explorer}})
+	}
+
+	t := Table{Title: "SPL Token Holdings", Header: []string{"#", "Name", "Symbol", "Mint", "Amount (UI)", "Decimals"}}
+	for i, h := range v.Holdings {
+		if !HasBalance(h) {
+			continue
+		}
+		t.Rows = append(t.Rows, []any{i + 1, orDash(h.Name), orDash(h.Symbol), MintCell(v.cluster, v.labels, h.Mint), h.UiAmount, h.Decimals})
+	}
+	if len(t.Rows) == 0 {
+		return append(doc, Note{Text: "No non-zero token balances found.", Tone: ToneWarning})
+	}
+	return append(doc, t)
+}
+
+// HasBalance reports whether h holds a non-zero amount.
+func HasBalance(h portfolio.TokenHolding) bool {
+	switch h.UiAmount {
+	case "", "0", "0.0", "0.00":
+		return false
+	}
+	return true
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
+
+// TxCell shows a signature as text (the signature itself when empty),
+// linked to the cluster's explorer.
+func TxCell(cluster *client.Cluster, signature, text string) Cell {
+	c := Cell{Text: text, Full: signature}
+	if text == "" {
+		c.Text = signature
+	}
+	if cluster != nil && signature != "" {
+		c.Link = cluster.TxURL(signature)
+	}
+	return c
+}
+
+// AccountCell shows an address as text (the address itself when empty),
+// linked to the cluster's explorer. A label from labels replaces an
+// abbreviated text and follows the full address.
+func AccountCell(cluster *client.Cluster, labels *addressbook.Book, address solana.PublicKey, text string) Cell {
+	full := address.String()
+	c := Cell{Text: text, Full: full}
+	name := labels.Name(address)
+	switch {
+	case text == "" && name != "":
+		c.Text = full + " (" + name + ")"
+	case text == "":
+		c.Text = full
+	case name != "":
+		c.Text = name
+	}
+	if cluster != nil {
+		c.Link = cluster.AccountURL(full)
+	}
+	return c
+}
+
+// MintCell is an AccountCell for a mint given as a string, as portfolio
+// holdings carry them, abbreviated unless labeled.
+func MintCell(cluster *client.Cluster, labels *addressbook.Book, mint string) Cell {
+	key, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		c := Cell{Text: ShortAddress(mint), Full: mint}
+		if cluster != nil {
+			c.Link = cluster.AccountURL(mint)
+		}
+		return c
+	}
+	return AccountCell(cluster, labels, key, ShortAddress(mint))
+}
+
+func blockTime(tx decode.TransactionInfo) *time.Time {
+	if tx.BlockTime == nil {
+		return nil
+	}
+	t := time.Unix(*tx.BlockTime, 0).UTC()
+	return &t
+}
+
+// ShortAddress abbreviates a base58 string the same way the summary tables do.
+func ShortAddress(s string) string {
+	if len(s) > 16 {
+		return s[:8] + "..." + s[len(s)-8:]
+	}
+	return s
+}
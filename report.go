@@ -0,0 +1,446 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/addressbook"
+	"go-solana-tx-explorer/classify"
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+	"go-solana-tx-explorer/portfolio"
+	"go-solana-tx-explorer/render"
+)
+
+// defaultReportLimit is how many recent transactions a report covers.
+const defaultReportLimit = 100
+
+// runReport implements `report [flags] [address]`: write a self-contained
+// HTML report of one wallet (portfolio, classified history, balance and fee
+// charts, per-transaction details) for readers without the CLI.
+func runReport(ctx context.Context, cfg *Config, args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	out := fs.String("out", "", "output file (default report-<address>.html)")
+	limit := fs.Int("limit", defaultReportLimit, "number of recent transactions to include (at most 1000)")
+	filterExpr := fs.String("filter", "", "filter expression, e.g. 'status:failed type:swap sol>=1 time>=7d'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *limit <= 0 || *limit > signaturesPageSize {
+		return fmt.Errorf("-limit must be between 1 and %d, got %d", signaturesPageSize, *limit)
+	}
+	account, err := walletArg(cfg, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	filter, err := classify.ParseFilter(*filterExpr, now(), cfg.Labels)
+	if err != nil {
+		return err
+	}
+	path := *out
+	if path == "" {
+		path = "report-" + account.String() + ".html"
+	}
+
+	rpcClient, err := cfg.NewClient()
+	if err != nil {
+		return err
+	}
+	cfg.ResolveCluster(ctx, rpcClient)
+	service, err := fetch.NewTransactionService(rpcClient, fetch.Config{Now: now})
+	if err != nil {
+		return err
+	}
+	history, err := service.FetchAccountTransactions(ctx, account, *limit)
+	if err != nil {
+		return fmt.Errorf("fetch history: %w", err)
+	}
+	balance, err := rpcClient.GetBalance(ctx, account, rpc.CommitmentConfirmed)
+	if err != nil {
+		return fmt.Errorf("fetch SOL balance: %w", err)
+	}
+	holdings, err := portfolio.NewUserPortfolioService(rpcClient, portfolio.Config{Cluster: cfg.Cluster.Name, Registry: tokenRegistry}).
+		FetchUserTokens(ctx, account)
+	if err != nil {
+		return fmt.Errorf("fetch portfolio: %w", err)
+	}
+
+	data := newReportData(cfg, history, filter, *filterExpr, balance.Value, holdings)
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("render report: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	slog.Info("Wrote report", "path", path, "transactions", len(data.Transactions), "holdings", len(data.Holdings))
+	return nil
+}
+
+// reportData is everything the report template shows.
+type reportData struct {
+	Account     string
+	Cluster     string
+	Explorer    string
+	GeneratedAt time.Time
+	Filter      string
+	Scanned     int
+	SOLBalance  uint64
+	Holdings    []portfolio.TokenHolding
+	Stats       decode.FeeStats
+	Failed      int
+
+	BalanceChart template.HTML
+	FeeChart     template.HTML
+
+	Transactions []reportTransaction
+
+	labels *addressbook.Book
+}
+
+// Addr shows an address followed by its address book label, if it has one.
+func (d reportData) Addr(address string) template.HTML {
+	s := template.HTMLEscapeString(address)
+	if key, err := solana.PublicKeyFromBase58(address); err == nil {
+		if name := d.labels.Name(key); name != "" {
+			s += ` <span class="muted">(` + template.HTMLEscapeString(name) + `)</span>`
+		}
+	}
+	return template.HTML(s)
+}
+
+// reportTransaction is one history entry with the data the CLI's details view
+// prints.
+type reportTransaction struct {
+	TransactionView
+	Type            string
+	SOLChange       int64
+	Version         string
+	RecentBlockhash string
+	BalanceChanges  []render.BalanceChange
+	TokenBalances   []render.TokenBalanceChange
+}
+
+func newReportData(cfg *Config, history *fetch.AccountTransactions, filter *classify.TransactionFilter, filterExpr string, balance uint64, holdings []portfolio.TokenHolding) reportData {
+	account := history.Account
+	d := reportData{
+		Account:     account.String(),
+		Cluster:     cfg.Cluster.Name,
+		Explorer:    cfg.Cluster.AccountURL(account.String()),
+		GeneratedAt: now().UTC(),
+		Filter:      filterExpr,
+		Scanned:     len(history.Transactions),
+		SOLBalance:  balance,
+		labels:      cfg.Labels,
+	}
+	for _, h := range holdings {
+		if h.UiAmount != "" && strings.Trim(h.UiAmount, "0.") != "" {
+			d.Holdings = append(d.Holdings, h)
+		}
+	}
+
+	matched := filter.Apply(history).Transactions
+	d.Stats = decode.ComputeFeeStats(matched)
+	cfg.Labels.AddWallet(account, "")
+	for _, tx := range matched {
+		cfg.Labels.LabelTokenAccounts(tx)
+		v := NewTransactionView(tx, &account, cfg.Cluster)
+		rt := reportTransaction{
+			TransactionView: v,
+			Type:            string(v.Classification.Type),
+			SOLChange:       v.Classification.SOLChange,
+			BalanceChanges:  render.SOLBalanceChanges(tx),
+			TokenBalances:   render.TokenBalanceChanges(tx),
+		}
+		if v.Classification.Direction != "" {
+			rt.Type += " (" + v.Classification.Direction + ")"
+		}
+		if tx.Transaction != nil {
+			rt.Version = "legacy"
+			if tx.Transaction.Message.IsVersioned() {
+				rt.Version = "v0"
+			}
+			rt.RecentBlockhash = tx.Transaction.Message.RecentBlockhash.String()
+		}
+		if v.Status == "failed" {
+			d.Failed++
+		}
+		d.Transactions = append(d.Transactions, rt)
+	}
+
+	balances, fees := reportSeries(account, history.Transactions)
+	d.BalanceChart = svgLineChart(balances, "SOL")
+	d.FeeChart = svgLineChart(fees, "SOL")
+	return d
+}
+
+// chartPoint is one sample of a time series.
+type chartPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// reportSeries derives the wallet's SOL balance after each transaction and
+// its cumulative fee spend (fees it paid as fee payer), oldest first. The
+// charts use the whole fetched history, not just the filtered part.
+func reportSeries(account solana.PublicKey, txs []decode.TransactionInfo) (balances, fees []chartPoint) {
+	ordered := make([]decode.TransactionInfo, 0, len(txs))
+	for _, tx := range txs {
+		if tx.BlockTime != nil && tx.Meta != nil {
+			ordered = append(ordered, tx)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return *ordered[i].BlockTime < *ordered[j].BlockTime })
+
+	var spent uint64
+	for _, tx := range ordered {
+		t := time.Unix(*tx.BlockTime, 0).UTC()
+		keys := classify.TransactionAccountKeys(tx)
+		for i, k := range keys {
+			if k == account && i < len(tx.Meta.PostBalances) {
+				balances = append(balances, chartPoint{Time: t, Value: float64(tx.Meta.PostBalances[i]) / 1e9})
+				break
+			}
+		}
+		if len(keys) > 0 && keys[0] == account {
+			spent += tx.Meta.Fee
+		}
+		fees = append(fees, chartPoint{Time: t, Value: float64(spent) / 1e9})
+	}
+	return balances, fees
+}
+
+// Chart geometry in SVG user units.
+const (
+	chartWidth   = 640
+	chartHeight  = 200
+	chartPadLeft = 90
+	chartPadX    = 12
+	chartPadY    = 24
+)
+
+// svgLineChart draws points as an inline SVG line chart labelled with the
+// value range and the first and last times.
+func svgLineChart(points []chartPoint, unit string) template.HTML {
+	if len(points) == 0 {
+		return `<p class="muted">No data in the fetched history.</p>`
+	}
+	minV, maxV := points[0].Value, points[0].Value
+	for _, p := range points {
+		minV, maxV = min(minV, p.Value), max(maxV, p.Value)
+	}
+	if maxV == minV {
+		maxV = minV + 1
+	}
+	start, end := points[0].Time, points[len(points)-1].Time
+	span := end.Sub(start).Seconds()
+
+	plotW := float64(chartWidth - chartPadLeft - chartPadX)
+	plotH := float64(chartHeight - 2*chartPadY)
+	x := func(t time.Time) float64 {
+		if span == 0 {
+			return chartPadLeft + plotW/2
+		}
+		return chartPadLeft + t.Sub(start).Seconds()/span*plotW
+	}
+	y := func(v float64) float64 { return chartPadY + (maxV-v)/(maxV-minV)*plotH }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" class="chart" role="img">`, chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<line class="axis" x1="%d" y1="%d" x2="%d" y2="%d"/>`, chartPadLeft, chartPadY, chartPadLeft, chartHeight-chartPadY)
+	fmt.Fprintf(&b, `<line class="axis" x1="%d" y1="%d" x2="%d" y2="%d"/>`, chartPadLeft, chartHeight-chartPadY, chartWidth-chartPadX, chartHeight-chartPadY)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%.4f %s</text>`, chartPadLeft-6, chartPadY+4, maxV, unit)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%.4f %s</text>`, chartPadLeft-6, chartHeight-chartPadY, minV, unit)
+	fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`, chartPadLeft, chartHeight-6, start.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, chartWidth-chartPadX, chartHeight-6, end.Format("2006-01-02 15:04"))
+	b.WriteString(`<polyline points="`)
+	for _, p := range points {
+		fmt.Fprintf(&b, "%.1f,%.1f ", x(p.Time), y(p.Value))
+	}
+	b.WriteString(`"/>`)
+	for _, p := range points {
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="2.5"><title>%s: %.9f %s</title></circle>`, x(p.Time), y(p.Value), p.Time.Format(time.RFC3339), p.Value, unit)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+var reportFuncs = template.FuncMap{
+	"sol": func(lamports uint64) string { return fmt.Sprintf("%.9f", float64(lamports)/1e9) },
+	"solDelta": func(lamports int64) string {
+		return fmt.Sprintf("%+.9f", float64(lamports)/1e9)
+	},
+	"short": render.ShortAddress,
+	"time":  func(t time.Time) string { return t.Format("2006-01-02 15:04:05 MST") },
+	"sign": func(v int64) string {
+		switch {
+		case v > 0:
+			return "pos"
+		case v < 0:
+			return "neg"
+		}
+		return ""
+	},
+	"add": func(a, b int) int { return a + b },
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Wallet report {{short .Account}}</title>
+<style>
+  :root { --fg: #1d2330; --muted: #6b7385; --line: #e3e6ec; --accent: #5b3cc4; --pos: #157f3b; --neg: #b42318; }
+  * { box-sizing: border-box; }
+  body { font: 14px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; color: var(--fg); margin: 0 auto; max-width: 1100px; padding: 24px; }
+  h1 { font-size: 22px; margin: 0 0 4px; }
+  h2 { font-size: 17px; margin: 32px 0 12px; padding-bottom: 6px; border-bottom: 2px solid var(--line); }
+  h3 { font-size: 14px; margin: 16px 0 6px; }
+  a { color: var(--accent); }
+  code, .mono { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: 12px; word-break: break-all; }
+  .muted { color: var(--muted); }
+  .cards { display: grid; grid-template-columns: repeat(auto-fit, minmax(180px, 1fr)); gap: 12px; }
+  .card { border: 1px solid var(--line); border-radius: 8px; padding: 12px; }
+  .card .label { color: var(--muted); font-size: 12px; text-transform: uppercase; letter-spacing: .04em; }
+  .card .value { font-size: 20px; font-weight: 600; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid var(--line); vertical-align: top; }
+  th { font-size: 12px; color: var(--muted); text-transform: uppercase; letter-spacing: .04em; }
+  td.num, th.num { text-align: right; font-variant-numeric: tabular-nums; }
+  .pos { color: var(--pos); }
+  .neg { color: var(--neg); }
+  .charts { display: grid; grid-template-columns: repeat(auto-fit, minmax(420px, 1fr)); gap: 16px; }
+  .chart { width: 100%; height: auto; border: 1px solid var(--line); border-radius: 8px; }
+  .chart polyline { fill: none; stroke: var(--accent); stroke-width: 2; }
+  .chart circle { fill: var(--accent); }
+  .chart .axis { stroke: var(--line); }
+  .chart text { font-size: 11px; fill: var(--muted); }
+  details.tx { border: 1px solid var(--line); border-radius: 8px; margin-bottom: 8px; }
+  details.tx[open] { border-color: var(--accent); }
+  details.tx > summary { cursor: pointer; display: grid; grid-template-columns: 40px 1.6fr 1.2fr 1.4fr 90px 1fr; gap: 8px; padding: 8px 12px; list-style: none; }
+  details.tx > summary::-webkit-details-marker { display: none; }
+  details.tx .body { padding: 0 12px 12px; }
+  .badge { display: inline-block; border-radius: 10px; padding: 0 8px; font-size: 12px; font-weight: 600; }
+  .badge.success { background: #e3f4e8; color: var(--pos); }
+  .badge.failed { background: #fde8e6; color: var(--neg); }
+  pre.logs { background: #f6f7f9; border-radius: 6px; padding: 8px; overflow-x: auto; font-size: 12px; margin: 0; }
+  footer { margin-top: 40px; color: var(--muted); font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>Wallet report</h1>
+<div class="mono">{{$.Addr .Account}}</div>
+<div class="muted">{{.Cluster}} · generated {{time .GeneratedAt}} · <a href="{{.Explorer}}">view in explorer</a></div>
+
+<h2>Overview</h2>
+<div class="cards">
+  <div class="card"><div class="label">SOL balance</div><div class="value">{{sol .SOLBalance}}</div></div>
+  <div class="card"><div class="label">Token holdings</div><div class="value">{{len .Holdings}}</div></div>
+  <div class="card"><div class="label">Transactions</div><div class="value">{{len .Transactions}}</div>{{if .Filter}}<div class="muted">of {{.Scanned}} matching <code>{{.Filter}}</code></div>{{else}}<div class="muted">most recent</div>{{end}}</div>
+  <div class="card"><div class="label">Failed</div><div class="value">{{.Failed}}</div></div>
+  <div class="card"><div class="label">Fees paid</div><div class="value">{{sol .Stats.TotalFee}}</div><div class="muted">{{sol .Stats.TotalPriorityFee}} priority</div></div>
+</div>
+
+<h2>Charts</h2>
+<div class="charts">
+  <div><h3>SOL balance over time</h3>{{.BalanceChart}}</div>
+  <div><h3>Cumulative fee spend</h3>{{.FeeChart}}</div>
+</div>
+
+<h2>Portfolio</h2>
+{{if .Holdings}}
+<table>
+  <thead><tr><th>Name</th><th>Symbol</th><th>Mint</th><th class="num">Amount</th><th class="num">Decimals</th></tr></thead>
+  <tbody>
+  {{range .Holdings}}<tr><td>{{or .Name "—"}}</td><td>{{or .Symbol "—"}}</td><td class="mono">{{$.Addr .Mint}}</td><td class="num">{{.UiAmount}}</td><td class="num">{{.Decimals}}</td></tr>
+  {{end}}
+  </tbody>
+</table>
+{{else}}<p class="muted">No non-zero token balances.</p>{{end}}
+
+<h2>Transaction history</h2>
+{{if .Transactions}}
+<p class="muted">Newest first. Select a transaction to see its details.</p>
+{{range $i, $tx := .Transactions}}
+<details class="tx">
+  <summary>
+    <span class="muted">{{add $i 1}}</span>
+    <span class="mono">{{short .Signature}}</span>
+    <span>{{if .BlockTime}}{{time .BlockTime.UTC}}{{else}}—{{end}}</span>
+    <span>{{.Type}}</span>
+    <span><span class="badge {{.Status}}">{{.Status}}</span></span>
+    <span class="num {{sign .SOLChange}}">{{solDelta .SOLChange}} SOL</span>
+  </summary>
+  <div class="body">
+    <h3>Basic information</h3>
+    <table>
+      <tr><th>Signature</th><td class="mono">{{if .Explorer}}<a href="{{.Explorer}}">{{.Signature}}</a>{{else}}{{.Signature}}{{end}}</td></tr>
+      <tr><th>Slot</th><td>{{.Slot}}</td></tr>
+      {{if .Error}}<tr><th>Error</th><td class="neg mono">{{printf "%v" .Error}}</td></tr>{{end}}
+      {{if .Version}}<tr><th>Version</th><td>{{.Version}}</td></tr>
+      <tr><th>Recent blockhash</th><td class="mono">{{.RecentBlockhash}}</td></tr>{{end}}
+    </table>
+    {{with .Fee}}
+    <h3>Fees and compute</h3>
+    <table>
+      <tr><th>Fee</th><td>{{sol .TotalFee}} SOL ({{.BaseFee}} base + {{.PriorityFee}} priority lamports)</td></tr>
+      <tr><th>Signatures</th><td>{{.Signatures}}</td></tr>
+      {{with .Budget.UnitPrice}}<tr><th>CU price</th><td>{{.}} µlamports</td></tr>{{end}}
+      <tr><th>CU requested</th><td>{{.RequestedCU}}</td></tr>
+      {{with .ConsumedCU}}<tr><th>CU consumed</th><td>{{.}}</td></tr>{{end}}
+    </table>
+    {{end}}
+    {{if .BalanceChanges}}
+    <h3>SOL balance changes</h3>
+    <table>
+      <thead><tr><th>Account</th><th class="num">Pre (SOL)</th><th class="num">Post (SOL)</th><th class="num">Change (SOL)</th></tr></thead>
+      {{range .BalanceChanges}}<tr><td class="mono">{{$.Addr (print .Account)}}</td><td class="num">{{sol .Pre}}</td><td class="num">{{sol .Post}}</td><td class="num {{sign .Delta}}">{{solDelta .Delta}}</td></tr>
+      {{end}}
+    </table>
+    {{end}}
+    {{if .TokenBalances}}
+    <h3>Token balances</h3>
+    <table>
+      <thead><tr><th>Account</th><th>Mint</th><th>Owner</th><th class="num">Before</th><th class="num">After</th></tr></thead>
+      {{range .TokenBalances}}<tr><td class="mono">{{$.Addr (print .Account)}}</td><td class="mono">{{$.Addr (print .Mint)}}</td><td class="mono">{{with .Owner}}{{$.Addr (print .)}}{{else}}—{{end}}</td><td class="num">{{.Pre}}</td><td class="num">{{.Post}}</td></tr>
+      {{end}}
+    </table>
+    {{end}}
+    {{if .Instructions}}
+    <h3>Instructions</h3>
+    <table>
+      <thead><tr><th>#</th><th>Program</th><th>Instruction</th><th>Accounts</th></tr></thead>
+      {{range $j, $in := .Instructions}}<tr><td>{{add $j 1}}</td><td>{{or .Program ($.Addr (print .ProgramID))}}</td><td>{{.Name}}{{if .Details}} <span class="muted">({{.Details}})</span>{{end}}</td><td class="mono">{{range .Accounts}}{{$.Addr (print .)}}<br>{{end}}</td></tr>
+      {{end}}
+    </table>
+    {{end}}
+    {{if .Signers}}
+    <h3>Signers</h3>
+    <div class="mono">{{range .Signers}}{{$.Addr (print .)}}<br>{{end}}</div>
+    {{end}}
+    {{if .Logs}}
+    <h3>Program logs</h3>
+    <pre class="logs">{{range .Logs}}{{.}}
+{{end}}</pre>
+    {{end}}
+  </div>
+</details>
+{{end}}
+{{else}}<p class="muted">No transactions{{if .Filter}} match the filter{{end}}.</p>{{end}}
+
+<footer>Generated by go-solana-tx-explorer. Amounts come from the {{.Cluster}} RPC node at the time shown above.</footer>
+</body>
+</html>
+`))
@@ -0,0 +1,172 @@
+// Package client builds Solana JSON-RPC clients for the explorer: every call
+// goes through an optional shared rate limit and is reported once it
+// finishes, so callers can export metrics without wrapping each method. It
+// also describes the public clusters and detects which one an endpoint serves.
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"golang.org/x/time/rate"
+)
+
+// Call results reported in Call.Result.
+const (
+	ResultOK          = "ok"
+	ResultError       = "error"
+	ResultRateLimited = "rate_limited"
+)
+
+// Call describes one finished JSON-RPC call. Batches report the method
+// "batch".
+type Call struct {
+	Method string
+	// Endpoint is the node's host; paths and queries, which may hold API
+	// keys, are left out.
+	Endpoint string
+	// Duration excludes time spent waiting on the rate limiter.
+	Duration time.Duration
+	Result   string
+	Err      error
+}
+
+// Config configures New. The zero value gives an unlimited client using the
+// RPC library's own HTTP transport.
+type Config struct {
+	// RequestsPerSecond is shared by every goroutine using the client. A
+	// non-positive rate disables limiting.
+	RequestsPerSecond float64
+	// Transport, when set, carries the client's HTTP traffic.
+	Transport http.RoundTripper
+	// OnCall, when set, is called after every call, from the calling goroutine.
+	OnCall func(ctx context.Context, call Call)
+}
+
+// httpTimeout matches the RPC library's own HTTP client timeout.
+const httpTimeout = 5 * time.Minute
+
+// New returns an RPC client for rpcURL configured by cfg. Every call is also
+// logged at debug level, and rate-limited responses at warn level.
+func New(rpcURL string, cfg Config) *rpc.Client {
+	next := rpc.New(rpcURL)
+	if cfg.Transport != nil {
+		next = rpc.NewWithCustomRPCClient(jsonrpc.NewClientWithOpts(rpcURL, &jsonrpc.RPCClientOpts{
+			HTTPClient: &http.Client{Transport: cfg.Transport, Timeout: httpTimeout},
+		}))
+	}
+	c := &instrumentedRPC{next: next, endpoint: EndpointHost(rpcURL), onCall: cfg.OnCall}
+	if cfg.RequestsPerSecond > 0 {
+		burst := int(cfg.RequestsPerSecond)
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), burst)
+	}
+	return rpc.NewWithCustomRPCClient(c)
+}
+
+// instrumentedRPC sends JSON-RPC calls through next, waiting on the limiter
+// first when one is set, and reports each call when it finishes.
+type instrumentedRPC struct {
+	next     *rpc.Client
+	limiter  *rate.Limiter
+	endpoint string
+	onCall   func(ctx context.Context, call Call)
+}
+
+func (c *instrumentedRPC) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+	start := time.Now()
+	err := c.next.RPCCallForInto(ctx, out, method, params)
+	c.observe(ctx, method, start, err)
+	return err
+}
+
+func (c *instrumentedRPC) CallWithCallback(ctx context.Context, method string, params []interface{}, callback func(*http.Request, *http.Response) error) error {
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+	start := time.Now()
+	err := c.next.RPCCallWithCallback(ctx, method, params, callback)
+	c.observe(ctx, method, start, err)
+	return err
+}
+
+func (c *instrumentedRPC) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	res, err := c.next.RPCCallBatch(ctx, requests)
+	c.observe(ctx, "batch", start, err)
+	return res, err
+}
+
+// Close closes the underlying client.
+func (c *instrumentedRPC) Close() error {
+	return c.next.Close()
+}
+
+func (c *instrumentedRPC) wait(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+	return c.limiter.Wait(ctx)
+}
+
+func (c *instrumentedRPC) observe(ctx context.Context, method string, start time.Time, err error) {
+	call := Call{Method: method, Endpoint: c.endpoint, Duration: time.Since(start), Result: ResultOK, Err: err}
+	switch {
+	case IsRateLimited(err):
+		call.Result = ResultRateLimited
+		slog.WarnContext(ctx, "RPC rate limited", "method", method, "endpoint", c.endpoint)
+	case err != nil:
+		call.Result = ResultError
+	}
+	args := []any{"method", method, "endpoint", c.endpoint, "duration", call.Duration, "result", call.Result}
+	if err != nil {
+		args = append(args, "err", err)
+	}
+	slog.DebugContext(ctx, "RPC call", args...)
+	if c.onCall != nil {
+		c.onCall(ctx, call)
+	}
+}
+
+// IsRateLimited recognises HTTP 429 responses, whether or not the provider
+// wrapped them in a JSON-RPC error.
+func IsRateLimited(err error) bool {
+	if err == nil {
+		return false
+	}
+	var httpErr *jsonrpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code == http.StatusTooManyRequests {
+		return true
+	}
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == http.StatusTooManyRequests {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "429") || strings.Contains(msg, "too many requests")
+}
+
+// EndpointHost reduces an RPC URL to its host, so API keys in the path or
+// query never end up in logs or metric labels.
+func EndpointHost(rpcURL string) string {
+	u, err := url.Parse(rpcURL)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Cluster describes a Solana cluster: its public endpoints, how to recognise
+// it and how to link to it.
+type Cluster struct {
+	Name   string
+	RPCURL string
+	WSURL  string
+	// GenesisHash identifies the cluster; empty for localnet, where every
+	// validator has its own genesis.
+	GenesisHash string
+	// ExplorerQuery is appended to explorer.solana.com links.
+	ExplorerQuery string
+}
+
+// The public clusters, and the default local validator.
+var (
+	Mainnet = &Cluster{
+		Name:        "mainnet",
+		RPCURL:      rpc.MainNetBeta_RPC,
+		WSURL:       rpc.MainNetBeta_WS,
+		GenesisHash: "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdpKuc147dw2N9d",
+	}
+	Testnet = &Cluster{
+		Name:          "testnet",
+		RPCURL:        rpc.TestNet_RPC,
+		WSURL:         rpc.TestNet_WS,
+		GenesisHash:   "4uhcVJyU9pJkvQyS88uRDiswHXSCkY3zQawwpjk2NsNY",
+		ExplorerQuery: "cluster=testnet",
+	}
+	Devnet = &Cluster{
+		Name:          "devnet",
+		RPCURL:        rpc.DevNet_RPC,
+		WSURL:         rpc.DevNet_WS,
+		GenesisHash:   "EtWTRABZaYq6iMfeYKouRu166VU2xqa1wcaWoxPkrZBG",
+		ExplorerQuery: "cluster=devnet",
+	}
+	Localnet = &Cluster{
+		Name:          "localnet",
+		RPCURL:        rpc.LocalNet_RPC,
+		WSURL:         rpc.LocalNet_WS,
+		ExplorerQuery: "cluster=custom&customUrl=" + url.QueryEscape(rpc.LocalNet_RPC),
+	}
+)
+
+// clusters maps every accepted name (including aliases) to its cluster.
+var clusters = map[string]*Cluster{
+	"mainnet":      Mainnet,
+	"mainnet-beta": Mainnet,
+	"testnet":      Testnet,
+	"devnet":       Devnet,
+	"localnet":     Localnet,
+	"localhost":    Localnet,
+}
+
+// LookupCluster returns the cluster with the given name or alias.
+func LookupCluster(name string) (*Cluster, error) {
+	if c, ok := clusters[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return c, nil
+	}
+	names := make([]string, 0, len(clusters))
+	for n := range clusters {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown cluster %q (want one of %s)", name, strings.Join(names, ", "))
+}
+
+// ClusterByGenesisHash returns the public cluster with this genesis hash, or
+// nil for anything else (usually a local validator).
+func ClusterByGenesisHash(hash string) *Cluster {
+	for _, c := range []*Cluster{Mainnet, Testnet, Devnet} {
+		if c.GenesisHash == hash {
+			return c
+		}
+	}
+	return nil
+}
+
+// TxURL returns the explorer link for a transaction signature.
+func (c *Cluster) TxURL(signature string) string {
+	return c.explorerURL("tx/" + signature)
+}
+
+// AccountURL returns the explorer link for an account address.
+func (c *Cluster) AccountURL(address string) string {
+	return c.explorerURL("address/" + address)
+}
+
+func (c *Cluster) explorerURL(path string) string {
+	u := "https://explorer.solana.com/" + path
+	if c.ExplorerQuery != "" {
+		u += "?" + c.ExplorerQuery
+	}
+	return u
+}
+
+// DetectCluster asks the endpoint for its genesis hash. The returned cluster
+// is nil when the hash belongs to no public cluster.
+func DetectCluster(ctx context.Context, client *rpc.Client) (*Cluster, string, error) {
+	hash, err := client.GetGenesisHash(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("getGenesisHash: %w", err)
+	}
+	return ClusterByGenesisHash(hash.String()), hash.String(), nil
+}
+
+// CustomCluster describes a local or private validator at rpcURL, with
+// explorer links pointing at that endpoint.
+func CustomCluster(rpcURL string) *Cluster {
+	c := *Localnet
+	c.ExplorerQuery = "cluster=custom&customUrl=" + url.QueryEscape(rpcURL)
+	return &c
+}
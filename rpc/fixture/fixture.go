@@ -0,0 +1,326 @@
+// Package fixture records JSON-RPC traffic and serves it back from a local
+// server, so commands and tests run offline and deterministically against a
+// known chain state. Record once against a live node (or the mock chain),
+// then replay the recording and compare the output with a golden file.
+package fixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// File is a recording on disk.
+type File struct {
+	// RecordedAt pins the clock during replay, so relative filters and
+	// "last fetched" times match the recording.
+	RecordedAt time.Time `json:"recorded_at"`
+	// Endpoint is the recorded node's host; paths and queries, which may hold
+	// API keys, are not stored.
+	Endpoint     string        `json:"endpoint"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one JSON-RPC request and the node's answer to it.
+type Interaction struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// Load reads a recording written by Recorder.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse fixtures %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// message covers both sides of a JSON-RPC 2.0 exchange.
+type message struct {
+	JSONRPC string          `json:"jsonrpc,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// decode reads a single message or a batch.
+func decode(data []byte) ([]message, bool, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []message
+		err := json.Unmarshal(data, &batch)
+		return batch, true, err
+	}
+	var m message
+	err := json.Unmarshal(data, &m)
+	return []message{m}, false, err
+}
+
+// Recorder is an http.RoundTripper that passes JSON-RPC calls through to the
+// node and keeps every request/response pair for Save.
+type Recorder struct {
+	next     http.RoundTripper
+	endpoint string
+	started  time.Time
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder records calls sent through next, or through
+// http.DefaultTransport when next is nil.
+func NewRecorder(next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{next: next, started: time.Now()}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		r.record(req.URL.Host, reqBody, respBody)
+	}
+	return resp, nil
+}
+
+func (r *Recorder) record(host string, reqBody, respBody []byte) {
+	reqs, _, err := decode(reqBody)
+	if err != nil {
+		return
+	}
+	resps, _, err := decode(respBody)
+	if err != nil {
+		return
+	}
+	byID := make(map[string]message, len(resps))
+	for _, m := range resps {
+		byID[string(m.ID)] = m
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoint = host
+	for _, q := range reqs {
+		a, ok := byID[string(q.ID)]
+		if !ok {
+			continue
+		}
+		r.interactions = append(r.interactions, Interaction{
+			Method: q.Method,
+			Params: canonicalJSON(q.Params),
+			Result: a.Result,
+			Error:  a.Error,
+		})
+	}
+}
+
+// Len returns how many interactions have been recorded.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.interactions)
+}
+
+// File returns the recording so far.
+func (r *Recorder) File() *File {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &File{
+		RecordedAt:   r.started.UTC(),
+		Endpoint:     r.endpoint,
+		Interactions: append([]Interaction(nil), r.interactions...),
+	}
+}
+
+// Save writes the recording to path, indented so fixtures diff well.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.File(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// canonicalJSON re-encodes v with sorted object keys, so equal parameters
+// compare equal however the client ordered them.
+func canonicalJSON(v json.RawMessage) json.RawMessage {
+	if len(v) == 0 {
+		return nil
+	}
+	var x any
+	if err := json.Unmarshal(v, &x); err != nil {
+		return v
+	}
+	out, err := json.Marshal(x)
+	if err != nil {
+		return v
+	}
+	return out
+}
+
+// ReplayHandler answers JSON-RPC calls from a recording. Calls match on method
+// and parameters. A call recorded several times gets the answers in recorded
+// order, then keeps getting the last one, so polling replays as it happened.
+// Unrecorded calls fail with a JSON-RPC error naming the call.
+type ReplayHandler struct {
+	mu      sync.Mutex
+	answers map[string][]Interaction
+}
+
+// NewReplayHandler indexes f's interactions.
+func NewReplayHandler(f *File) *ReplayHandler {
+	h := &ReplayHandler{answers: make(map[string][]Interaction)}
+	for _, in := range f.Interactions {
+		key := callKey(in.Method, in.Params)
+		h.answers[key] = append(h.answers[key], in)
+	}
+	return h
+}
+
+// NewReplayServer starts a local HTTP server replaying f. Close it when done.
+func NewReplayServer(f *File) *httptest.Server {
+	return httptest.NewServer(NewReplayHandler(f))
+}
+
+func callKey(method string, params json.RawMessage) string {
+	return method + " " + string(canonicalJSON(params))
+}
+
+// ServeHTTP implements http.Handler.
+func (h *ReplayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reqs, batch, err := decode(body)
+	if err != nil {
+		http.Error(w, "invalid JSON-RPC request", http.StatusBadRequest)
+		return
+	}
+	out := make([]message, len(reqs))
+	for i, q := range reqs {
+		out[i] = h.answer(q)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		json.NewEncoder(w).Encode(out)
+		return
+	}
+	json.NewEncoder(w).Encode(out[0])
+}
+
+func (h *ReplayHandler) answer(q message) message {
+	resp := message{JSONRPC: "2.0", ID: q.ID}
+	key := callKey(q.Method, q.Params)
+
+	h.mu.Lock()
+	queue := h.answers[key]
+	var in Interaction
+	found := len(queue) > 0
+	if found {
+		in = queue[0]
+		if len(queue) > 1 {
+			h.answers[key] = queue[1:]
+		}
+	}
+	h.mu.Unlock()
+
+	if !found {
+		slog.Warn("No fixture for RPC call", "method", q.Method, "params", string(canonicalJSON(q.Params)))
+		msg, _ := json.Marshal(map[string]any{"code": -32601, "message": "no recorded answer for " + key})
+		resp.Error = msg
+		return resp
+	}
+	resp.Result, resp.Error = in.Result, in.Error
+	if resp.Result == nil && resp.Error == nil {
+		resp.Result = json.RawMessage("null")
+	}
+	return resp
+}
+
+// Server is a local JSON-RPC endpoint that either replays a recording or
+// records the traffic it forwards to an upstream node.
+type Server struct {
+	// URL is the endpoint to point RPC clients at.
+	URL string
+	// RecordedAt is when the recording was made: the time to pin clocks to.
+	RecordedAt time.Time
+
+	srv  *httptest.Server
+	rec  *Recorder
+	path string
+}
+
+// Serve starts a Server for the recording at path. With upstream empty it
+// replays path; otherwise it forwards every call to upstream, and Close saves
+// what it recorded to path. Tests use this to re-record their fixtures from
+// a live node or the mock chain.
+func Serve(path, upstream string) (*Server, error) {
+	if upstream == "" {
+		f, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		srv := NewReplayServer(f)
+		return &Server{URL: srv.URL, RecordedAt: f.RecordedAt, srv: srv}, nil
+	}
+
+	target, err := url.Parse(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("upstream %q: %w", upstream, err)
+	}
+	rec := NewRecorder(nil)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = rec
+	srv := httptest.NewServer(proxy)
+	return &Server{URL: srv.URL, RecordedAt: rec.started.UTC(), srv: srv, rec: rec, path: path}, nil
+}
+
+// Close stops the server and, when recording, saves the recording.
+func (s *Server) Close() error {
+	s.srv.Close()
+	if s.rec == nil {
+		return nil
+	}
+	return s.rec.Save(s.path)
+}
@@ -0,0 +1,110 @@
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// slotNode is a node whose slot advances on every getSlot call.
+func slotNode(t *testing.T) *httptest.Server {
+	t.Helper()
+	var slot atomic.Uint64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var q message
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := message{JSONRPC: "2.0", ID: q.ID}
+		switch q.Method {
+		case "getSlot":
+			resp.Result, _ = json.Marshal(slot.Add(1))
+		default:
+			resp.Error = json.RawMessage(`{"code":-32601,"message":"method not found"}`)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "slots.json")
+
+	rec, err := Serve(path, slotNode(t).URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.New(rec.URL)
+	for want := uint64(1); want <= 2; want++ {
+		got, err := client.GetSlot(ctx, "")
+		if err != nil || got != want {
+			t.Fatalf("recording: GetSlot = %d, %v; want %d", got, err, want)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Interactions) != 2 || f.Endpoint == "" || f.RecordedAt.IsZero() {
+		t.Fatalf("recording = %d interactions from %q at %v; want 2 from the node", len(f.Interactions), f.Endpoint, f.RecordedAt)
+	}
+
+	replay, err := Serve(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replay.Close()
+	if !replay.RecordedAt.Equal(f.RecordedAt) {
+		t.Errorf("RecordedAt = %v, want %v", replay.RecordedAt, f.RecordedAt)
+	}
+	client = rpc.New(replay.URL)
+	// Answers come back in recorded order, then the last one repeats
+	for _, want := range []uint64{1, 2, 2} {
+		got, err := client.GetSlot(ctx, "")
+		if err != nil || got != want {
+			t.Fatalf("replay: GetSlot = %d, %v; want %d", got, err, want)
+		}
+	}
+}
+
+func TestReplayUnrecordedCall(t *testing.T) {
+	srv := NewReplayServer(&File{Interactions: []Interaction{{Method: "getSlot", Result: json.RawMessage("7")}}})
+	defer srv.Close()
+
+	_, err := rpc.New(srv.URL).GetBlockHeight(context.Background(), rpc.CommitmentFinalized)
+	var rpcErr *jsonrpc.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Message != `no recorded answer for getBlockHeight [{"commitment":"finalized"}]` {
+		t.Fatalf("GetBlockHeight error = %v; want one naming the unrecorded call", err)
+	}
+}
+
+func TestReplayMatchesParamsInAnyKeyOrder(t *testing.T) {
+	srv := NewReplayServer(&File{Interactions: []Interaction{{
+		Method: "getBlockHeight",
+		Params: json.RawMessage(`[{"minContextSlot":5,"commitment":"confirmed"}]`),
+		Result: json.RawMessage("42"),
+	}}})
+	defer srv.Close()
+
+	var got uint64
+	err := rpc.New(srv.URL).RPCCallForInto(context.Background(), &got, "getBlockHeight",
+		[]any{map[string]any{"commitment": "confirmed", "minContextSlot": 5}})
+	if err != nil || got != 42 {
+		t.Fatalf("getBlockHeight = %d, %v; want 42", got, err)
+	}
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/classify"
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+	"go-solana-tx-explorer/portfolio"
+	"go-solana-tx-explorer/rpc/client"
+)
+
+// Server defaults for `serve`.
+const (
+	defaultServeAddr    = ":8080"
+	defaultServeTimeout = 15 * time.Second
+	defaultCacheTTL     = 30 * time.Second
+	defaultPageLimit    = 20
+	defaultMaxPageLimit = 100
+)
+
+// Server exposes the explorer's services as a JSON HTTP API.
+type Server struct {
+	cfg       *Config
+	client    *rpc.Client
+	portfolio *portfolio.UserPortfolioService
+	cache     *ttlCache
+	timeout   time.Duration
+	maxLimit  int
+	hub       *StreamHub
+	mux       *http.ServeMux
+}
+
+// ServerOptions tunes a Server. Zero values select the defaults.
+type ServerOptions struct {
+	Timeout  time.Duration
+	CacheTTL time.Duration
+	MaxLimit int
+	// Processed makes live streams report transactions at processed
+	// commitment, subscribing over WSURL.
+	Processed bool
+	WSURL     string
+}
+
+// NewServer creates the API server on top of client. Live stream feeds run
+// until ctx ends.
+func NewServer(ctx context.Context, cfg *Config, client *rpc.Client, opts ServerOptions) *Server {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultServeTimeout
+	}
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = defaultMaxPageLimit
+	}
+	s := &Server{
+		cfg:       cfg,
+		client:    client,
+		portfolio: portfolio.NewUserPortfolioService(client, portfolio.Config{Cluster: cfg.Cluster.Name, Registry: tokenRegistry}),
+		cache:     newTTLCache(opts.CacheTTL, defaultCacheEntries),
+		timeout:   opts.Timeout,
+		maxLimit:  opts.MaxLimit,
+		hub:       NewStreamHub(ctx, client, cfg.Cluster, opts.WSURL, opts.Processed),
+		mux:       http.NewServeMux(),
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /accounts/{addr}/transactions", s.cached(s.handleAccountTransactions))
+	s.mux.HandleFunc("GET /accounts/{addr}/portfolio", s.cached(s.handlePortfolio))
+	s.mux.HandleFunc("GET /tx/{sig}", s.cached(s.handleTransaction))
+	s.mux.HandleFunc("GET /tokens/{mint}", s.cached(s.handleToken))
+	s.mux.HandleFunc("GET /stream", s.handleSSE)
+	s.mux.HandleFunc("GET /ws", s.handleWebSocket)
+	registerOpsRoutes(s.mux, rpcReadiness(s.client))
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// apiHandler returns the response body for a request, or an error that
+// writeError maps to a status code.
+type apiHandler func(ctx context.Context, r *http.Request) (any, error)
+
+// cached applies the request timeout and serves repeated requests for the
+// same path and query from the cache while it is fresh.
+func (s *Server) cached(h apiHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path + "?" + r.URL.Query().Encode()
+		if v, ok := s.cache.Get(key); ok {
+			w.Header().Set("X-Cache", "hit")
+			writeJSON(w, http.StatusOK, v)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+		defer cancel()
+		v, err := h(ctx, r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		s.cache.Set(key, v)
+		w.Header().Set("X-Cache", "miss")
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+// apiError carries an HTTP status for a client-side problem.
+type apiError struct {
+	status int
+	msg    string
+}
+
+func (e apiError) Error() string { return e.msg }
+
+func badRequest(format string, args ...any) error {
+	return apiError{status: http.StatusBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...any) error {
+	return apiError{status: http.StatusNotFound, msg: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusBadGateway
+	var ae apiError
+	switch {
+	case errors.As(err, &ae):
+		status = ae.status
+	case errors.Is(err, fetch.ErrTransactionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("Writing response failed", "err", err)
+	}
+}
+
+// AccountTransactionsPage is one page of an account's history. NextBefore,
+// when set, is the `before` value for the following page.
+type AccountTransactionsPage struct {
+	Account      solana.PublicKey  `json:"account"`
+	Transactions []TransactionView `json:"transactions"`
+	// Scanned is how many transactions the page covered before filtering
+	Scanned    int    `json:"scanned"`
+	NextBefore string `json:"next_before,omitempty"`
+}
+
+// handleAccountTransactions serves GET /accounts/{addr}/transactions with the
+// query parameters limit, before, filter (history -filter syntax) and
+// commitment.
+func (s *Server) handleAccountTransactions(ctx context.Context, r *http.Request) (any, error) {
+	account, err := pathAccount(r)
+	if err != nil {
+		return nil, err
+	}
+	q := r.URL.Query()
+	limit := defaultPageLimit
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			return nil, badRequest("invalid limit %q", v)
+		}
+		limit = min(limit, s.maxLimit)
+	}
+	var before solana.Signature
+	if v := q.Get("before"); v != "" {
+		if before, err = solana.SignatureFromBase58(v); err != nil {
+			return nil, badRequest("invalid before signature %q", v)
+		}
+	}
+	filter, err := classify.ParseFilter(q.Get("filter"), now(), s.cfg.Labels)
+	if err != nil {
+		return nil, badRequest("filter: %v", err)
+	}
+	service, err := fetch.NewTransactionService(s.client, fetch.Config{Commitment: rpc.CommitmentType(q.Get("commitment")), Now: now})
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+
+	history, err := service.FetchAccountTransactionsBefore(ctx, account, limit, before)
+	if err != nil {
+		return nil, err
+	}
+	page := AccountTransactionsPage{Account: account, Scanned: len(history.Transactions), Transactions: []TransactionView{}}
+	if !history.NextBefore.IsZero() {
+		page.NextBefore = history.NextBefore.String()
+	}
+	for _, tx := range filter.Apply(history).Transactions {
+		page.Transactions = append(page.Transactions, NewTransactionView(tx, &account, s.cfg.Cluster))
+	}
+	return page, nil
+}
+
+// PortfolioResponse lists an owner's non-zero token holdings.
+type PortfolioResponse struct {
+	Owner    solana.PublicKey         `json:"owner"`
+	Holdings []portfolio.TokenHolding `json:"holdings"`
+	Explorer string                   `json:"explorer,omitempty"`
+}
+
+// handlePortfolio serves GET /accounts/{addr}/portfolio.
+func (s *Server) handlePortfolio(ctx context.Context, r *http.Request) (any, error) {
+	owner, err := pathAccount(r)
+	if err != nil {
+		return nil, err
+	}
+	holdings, err := s.portfolio.FetchUserTokens(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if holdings == nil {
+		holdings = []portfolio.TokenHolding{}
+	}
+	return PortfolioResponse{Owner: owner, Holdings: holdings, Explorer: s.cfg.Cluster.AccountURL(owner.String())}, nil
+}
+
+// handleTransaction serves GET /tx/{sig}. The optional wallet parameter adds
+// a classification from that wallet's point of view.
+func (s *Server) handleTransaction(ctx context.Context, r *http.Request) (any, error) {
+	sig, err := solana.SignatureFromBase58(r.PathValue("sig"))
+	if err != nil {
+		return nil, badRequest("invalid signature %q", r.PathValue("sig"))
+	}
+	q := r.URL.Query()
+	commitment := rpc.CommitmentConfirmed
+	if v := q.Get("commitment"); v != "" {
+		commitment = rpc.CommitmentType(v)
+		if commitment != rpc.CommitmentConfirmed && commitment != rpc.CommitmentFinalized {
+			return nil, badRequest("unsupported commitment %q; use confirmed or finalized", v)
+		}
+	}
+	var wallet *solana.PublicKey
+	if v := q.Get("wallet"); v != "" {
+		pk, err := solana.PublicKeyFromBase58(v)
+		if err != nil {
+			return nil, badRequest("invalid wallet address %q", v)
+		}
+		wallet = &pk
+	}
+
+	service, err := fetch.NewTransactionService(s.client, fetch.Config{Now: now})
+	if err != nil {
+		return nil, err
+	}
+	tx, err := service.FetchTransaction(ctx, sig, commitment)
+	if err != nil {
+		return nil, err
+	}
+	return NewTransactionView(*tx, wallet, s.cfg.Cluster), nil
+}
+
+// handleToken serves GET /tokens/{mint} from the cluster's token registry.
+func (s *Server) handleToken(ctx context.Context, r *http.Request) (any, error) {
+	mint, err := solana.PublicKeyFromBase58(r.PathValue("mint"))
+	if err != nil {
+		return nil, badRequest("invalid mint address %q", r.PathValue("mint"))
+	}
+	tokens, err := tokenRegistry.Load(ctx, s.cfg.Cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := tokens[mint.String()]
+	if !ok {
+		return nil, notFound("token %s is not in the %s registry", mint, s.cfg.Cluster.Name)
+	}
+	return info, nil
+}
+
+func pathAccount(r *http.Request) (solana.PublicKey, error) {
+	account, err := solana.PublicKeyFromBase58(r.PathValue("addr"))
+	if err != nil {
+		return solana.PublicKey{}, badRequest("invalid address %q", r.PathValue("addr"))
+	}
+	return account, nil
+}
+
+// TransactionView is the decoded JSON form of a transaction served by the API.
+type TransactionView struct {
+	Signature      string                   `json:"signature"`
+	Slot           uint64                   `json:"slot"`
+	BlockTime      *time.Time               `json:"block_time,omitempty"`
+	Status         string                   `json:"status"` // success or failed
+	Error          any                      `json:"error,omitempty"`
+	Fee            *decode.FeeBreakdown     `json:"fee,omitempty"`
+	Signers        []solana.PublicKey       `json:"signers,omitempty"`
+	AccountKeys    []solana.PublicKey       `json:"account_keys,omitempty"`
+	Instructions   []InstructionView        `json:"instructions,omitempty"`
+	Logs           []string                 `json:"logs,omitempty"`
+	Classification *classify.Classification `json:"classification,omitempty"`
+	Explorer       string                   `json:"explorer,omitempty"`
+}
+
+// InstructionView is a top-level instruction with its accounts resolved.
+type InstructionView struct {
+	decode.DecodedInstruction
+	Accounts []solana.PublicKey `json:"accounts"`
+	DataSize int                `json:"data_size"`
+}
+
+// NewTransactionView decodes tx for the API. With a wallet the view includes
+// its classification from that wallet's point of view.
+func NewTransactionView(tx decode.TransactionInfo, wallet *solana.PublicKey, cluster *client.Cluster) TransactionView {
+	v := TransactionView{
+		Signature: tx.Signature,
+		Slot:      tx.Slot,
+		Status:    "success",
+		Fee:       decode.AnalyzeFee(tx),
+	}
+	if tx.BlockTime != nil {
+		t := time.Unix(*tx.BlockTime, 0).UTC()
+		v.BlockTime = &t
+	}
+	if tx.Meta != nil {
+		if tx.Meta.Err != nil {
+			v.Status, v.Error = "failed", tx.Meta.Err
+		}
+		v.Logs = tx.Meta.LogMessages
+	}
+	if tx.Transaction != nil {
+		keys := classify.TransactionAccountKeys(tx)
+		v.Signers = tx.Transaction.Message.Signers()
+		v.AccountKeys = keys
+		for _, instr := range tx.Transaction.Message.Instructions {
+			if int(instr.ProgramIDIndex) >= len(keys) {
+				continue
+			}
+			iv := InstructionView{
+				DecodedInstruction: decode.DecodeInstruction(keys[instr.ProgramIDIndex], instr.Data),
+				Accounts:           make([]solana.PublicKey, 0, len(instr.Accounts)),
+				DataSize:           len(instr.Data),
+			}
+			for _, idx := range instr.Accounts {
+				if int(idx) < len(keys) {
+					iv.Accounts = append(iv.Accounts, keys[idx])
+				}
+			}
+			v.Instructions = append(v.Instructions, iv)
+		}
+	}
+	if wallet != nil {
+		c := classify.ClassifyTransaction(tx, *wallet)
+		v.Classification = &c
+	}
+	if cluster != nil {
+		v.Explorer = cluster.TxURL(tx.Signature)
+	}
+	return v
+}
+
+// runServe implements `serve [flags]`: run the HTTP API, including the live
+// streams, until shutdown.
+func runServe(ctx context.Context, cfg *Config, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", defaultServeAddr, "listen address")
+	timeout := fs.Duration("timeout", defaultServeTimeout, "per-request timeout, including RPC calls")
+	cacheTTL := fs.Duration("cache-ttl", defaultCacheTTL, "how long responses are cached (0 disables the cache)")
+	maxLimit := fs.Int("max-limit", defaultMaxPageLimit, "largest page size accepted by the transactions endpoint")
+	commitment := fs.String("commitment", string(rpc.CommitmentConfirmed), "earliest commitment live streams report transactions at: processed (WebSocket) or confirmed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts := ServerOptions{Timeout: *timeout, CacheTTL: *cacheTTL, MaxLimit: *maxLimit}
+	switch rpc.CommitmentType(*commitment) {
+	case rpc.CommitmentProcessed:
+		wsURL, err := cfg.RequireWSURL()
+		if err != nil {
+			return err
+		}
+		opts.Processed, opts.WSURL = true, wsURL
+	case rpc.CommitmentConfirmed:
+	default:
+		return fmt.Errorf("unsupported -commitment %q; use processed or confirmed", *commitment)
+	}
+
+	client, err := cfg.NewClient()
+	if err != nil {
+		return err
+	}
+	cfg.ResolveCluster(ctx, client)
+	api := NewServer(ctx, cfg, client, opts)
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           logRequests(api),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	slog.Info("Serving the API", "cluster", cfg.Cluster.Name, "url", "http://"+ln.Addr().String())
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Requests do not derive from ctx, so in-flight ones get the shutdown
+	// timeout to finish instead of being cancelled by the signal
+	shutdownCtx, cancel := shutdownContext(ctx, cfg)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Server shutdown incomplete", "err", err)
+	}
+	return ctx.Err()
+}
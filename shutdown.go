@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownTimeout is how long a signalled shutdown may take when
+// shutdown_timeout is not configured.
+const defaultShutdownTimeout = 10 * time.Second
+
+// signalContext returns a context that is cancelled on SIGINT or SIGTERM.
+// Commands treat that as the start of a graceful shutdown: stop taking new
+// work, finish or abandon what is in flight and flush state. If they have not
+// returned within timeout the process exits with status 1. After the first
+// signal the default handling is restored, so a second one stops immediately.
+func signalContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(sigs)
+		select {
+		case sig := <-sigs:
+			slog.Info("Shutting down; signal again to force", "signal", sig.String(), "timeout", timeout)
+			cancel()
+			time.AfterFunc(timeout, func() {
+				slog.Error("Shutdown did not finish in time, exiting", "timeout", timeout)
+				os.Exit(1)
+			})
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// shutdownContext returns a context for cleanup work once ctx has ended,
+// such as flushing notifications. It is bounded by the shutdown timeout.
+func shutdownContext(ctx context.Context, cfg *Config) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), cfg.ShutdownTimeout)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+)
+
+// runSimulate implements `simulate [flags] [tx]`.
+func runSimulate(ctx context.Context, cfg *Config, args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	file := fs.String("file", "", "read the transaction from a file instead of an argument or stdin")
+	encoding := fs.String("encoding", "auto", "input encoding: auto, base64 or base58")
+	rpcURL := fs.String("rpc", "", "RPC endpoint to simulate against (default: the configured endpoint)")
+	sigVerify := fs.Bool("sig-verify", false, "verify signatures during simulation")
+	replaceBlockhash := fs.Bool("replace-blockhash", false, "replace the recent blockhash with the latest one")
+	commitment := fs.String("commitment", string(rpc.CommitmentConfirmed), "commitment level: processed, confirmed or finalized")
+	full := fs.Bool("full", false, "show all logs, accounts and instructions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	raw, err := readTransactionInput(fs.Args(), *file)
+	if err != nil {
+		return err
+	}
+	tx, err := decode.DecodeRawTransaction(raw, *encoding)
+	if err != nil {
+		return err
+	}
+
+	if *rpcURL == "" {
+		if *rpcURL, err = cfg.RequireRPCURL(); err != nil {
+			return err
+		}
+	}
+	service, err := fetch.NewTransactionService(rpc.New(*rpcURL), fetch.Config{})
+	if err != nil {
+		return err
+	}
+	info, err := service.SimulateTransaction(ctx, tx, fetch.SimulateOptions{
+		SigVerify:              *sigVerify,
+		ReplaceRecentBlockhash: *replaceBlockhash,
+		Commitment:             rpc.CommitmentType(*commitment),
+	})
+	if err != nil {
+		return err
+	}
+
+	formatter := newFormatter(cfg, *full, nil)
+	if err := formatter.FormatSimulationHeader(info); err != nil {
+		return err
+	}
+	return formatter.FormatTransactionDetails(*info, 0)
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Checkpoint is the newest transaction fully processed for a wallet.
+type Checkpoint struct {
+	Signature string    `json:"signature"`
+	Slot      uint64    `json:"slot"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CheckpointStore persists one Checkpoint per wallet in a JSON file. Every
+// update rewrites the file atomically, so a crash loses at most the update in
+// flight and the listener resumes from the last one written.
+type CheckpointStore struct {
+	path string
+
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// OpenCheckpointStore loads path, starting empty when it does not exist yet.
+func OpenCheckpointStore(path string) (*CheckpointStore, error) {
+	s := &CheckpointStore{path: path, checkpoints: make(map[string]Checkpoint)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoints: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.checkpoints); err != nil {
+		return nil, fmt.Errorf("decode checkpoints %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Get returns the wallet's checkpoint, if any. A nil store has none.
+func (s *CheckpointStore) Get(wallet solana.PublicKey) (Checkpoint, bool) {
+	if s == nil {
+		return Checkpoint{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp, ok := s.checkpoints[wallet.String()]
+	return cp, ok
+}
+
+// Set records and persists the wallet's checkpoint. A nil store ignores it.
+func (s *CheckpointStore) Set(wallet solana.PublicKey, signature string, slot uint64) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[wallet.String()] = Checkpoint{Signature: signature, Slot: slot, UpdatedAt: time.Now().UTC()}
+	return s.flushLocked()
+}
+
+// Flush writes the current checkpoints to disk.
+func (s *CheckpointStore) Flush() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flushLocked()
+}
+
+func (s *CheckpointStore) flushLocked() error {
+	data, err := json.MarshalIndent(s.checkpoints, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode checkpoints: %w", err)
+	}
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create checkpoint dir: %w", err)
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write checkpoints: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replace checkpoints: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package store persists explorer state on disk: saved account histories and
+// the per-wallet checkpoints a listener resumes from. Every write goes to a
+// temporary sibling and is renamed into place, so a crash never leaves half a
+// file.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+)
+
+// storedTransaction is the on-disk form of a TransactionInfo. The transaction
+// itself is kept in its wire format (base64) so it round-trips exactly,
+// including v0 messages.
+type storedTransaction struct {
+	Signature   string               `json:"signature"`
+	Slot        uint64               `json:"slot"`
+	BlockTime   *int64               `json:"blockTime,omitempty"`
+	Meta        *rpc.TransactionMeta `json:"meta,omitempty"`
+	Transaction string               `json:"transaction,omitempty"`
+}
+
+type storedHistory struct {
+	Account      solana.PublicKey    `json:"account"`
+	LastFetched  time.Time           `json:"last_fetched"`
+	Transactions []storedTransaction `json:"transactions"`
+}
+
+// SaveHistory writes accountTxs to path as JSON, creating its directory.
+func SaveHistory(path string, accountTxs *fetch.AccountTransactions) error {
+	out := storedHistory{
+		Account:      accountTxs.Account,
+		LastFetched:  accountTxs.LastFetched,
+		Transactions: make([]storedTransaction, 0, len(accountTxs.Transactions)),
+	}
+	for _, tx := range accountTxs.Transactions {
+		st := storedTransaction{
+			Signature: tx.Signature,
+			Slot:      tx.Slot,
+			BlockTime: tx.BlockTime,
+			Meta:      tx.Meta,
+		}
+		if tx.Transaction != nil {
+			b64, err := tx.Transaction.ToBase64()
+			if err != nil {
+				return fmt.Errorf("encode transaction %s: %w", tx.Signature, err)
+			}
+			st.Transaction = b64
+		}
+		out.Transactions = append(out.Transactions, st)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode history: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create history dir: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace history: %w", err)
+	}
+	return nil
+}
+
+// LoadHistory reads a file written by SaveHistory.
+func LoadHistory(path string) (*fetch.AccountTransactions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	var in storedHistory
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, fmt.Errorf("decode history %s: %w", path, err)
+	}
+
+	accountTxs := &fetch.AccountTransactions{
+		Account:      in.Account,
+		LastFetched:  in.LastFetched,
+		Transactions: make([]decode.TransactionInfo, 0, len(in.Transactions)),
+	}
+	for _, st := range in.Transactions {
+		info := decode.TransactionInfo{
+			Signature: st.Signature,
+			Slot:      st.Slot,
+			BlockTime: st.BlockTime,
+			Meta:      st.Meta,
+		}
+		if st.Transaction != "" {
+			tx, err := solana.TransactionFromBase64(st.Transaction)
+			if err != nil {
+				return nil, fmt.Errorf("decode stored transaction %s: %w", st.Signature, err)
+			}
+			info.Transaction = tx
+		}
+		accountTxs.Transactions = append(accountTxs.Transactions, info)
+	}
+	return accountTxs, nil
+}
+
+// MergeHistory adds the transactions in fresh that are not already in stored,
+// keeping newest-first order by slot. Both must belong to the same account.
+func MergeHistory(stored, fresh *fetch.AccountTransactions) (*fetch.AccountTransactions, error) {
+	if stored == nil {
+		return fresh, nil
+	}
+	if stored.Account != fresh.Account {
+		return nil, fmt.Errorf("stored history belongs to %s, not %s", stored.Account, fresh.Account)
+	}
+	seen := make(map[string]bool, len(stored.Transactions))
+	merged := make([]decode.TransactionInfo, 0, len(stored.Transactions)+len(fresh.Transactions))
+	for _, tx := range fresh.Transactions {
+		seen[tx.Signature] = true
+		merged = append(merged, tx)
+	}
+	for _, tx := range stored.Transactions {
+		if !seen[tx.Signature] {
+			merged = append(merged, tx)
+		}
+	}
+	sortTransactionsBySlotDesc(merged)
+	return &fetch.AccountTransactions{
+		Account:      fresh.Account,
+		LastFetched:  fresh.LastFetched,
+		Transactions: merged,
+	}, nil
+}
+
+func sortTransactionsBySlotDesc(txs []decode.TransactionInfo) {
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].Slot > txs[j].Slot })
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gorilla/websocket"
+
+	"go-solana-tx-explorer/addressbook"
+	"go-solana-tx-explorer/classify"
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+	"go-solana-tx-explorer/rpc/client"
+)
+
+const (
+	// maxStreamWallets bounds how many wallets one stream may subscribe to.
+	maxStreamWallets = 100
+	// streamBuffer is how many events a subscriber may fall behind before it
+	// is disconnected; clients are expected to reconnect.
+	streamBuffer = 256
+	// streamHeartbeat keeps idle connections open through proxies.
+	streamHeartbeat = 15 * time.Second
+	// wsWriteTimeout bounds a single WebSocket write.
+	wsWriteTimeout = 10 * time.Second
+)
+
+// Stream event types.
+const (
+	StreamTransaction = "transaction"
+	StreamStatus      = "status"
+)
+
+// StreamEvent is one message on a live stream: a newly seen, classified
+// transaction, or a commitment status change of one.
+type StreamEvent struct {
+	Type        string           `json:"type"`
+	Wallet      solana.PublicKey `json:"wallet"`
+	Signature   string           `json:"signature"`
+	Slot        uint64           `json:"slot"`
+	Status      TxStatus         `json:"status,omitempty"`
+	PrevStatus  TxStatus         `json:"previous_status,omitempty"`
+	Reason      string           `json:"reason,omitempty"`
+	Transaction *TransactionView `json:"transaction,omitempty"`
+	Time        time.Time        `json:"time"`
+
+	// tx is kept for filtering transaction events
+	tx *decode.TransactionInfo
+}
+
+// StreamHub fans live wallet activity out to stream subscribers. Each wallet
+// has at most one upstream poller, started with its first subscriber and
+// stopped with its last, however many clients watch it.
+type StreamHub struct {
+	ctx       context.Context
+	client    *rpc.Client
+	cluster   *client.Cluster
+	service   *fetch.TransactionService
+	tracker   *StatusTracker
+	wsURL     string
+	processed bool
+
+	mu    sync.Mutex
+	feeds map[solana.PublicKey]*walletFeed
+}
+
+type walletFeed struct {
+	cancel context.CancelFunc
+	subs   map[*streamSubscriber]struct{}
+}
+
+// streamSubscriber is one connected client.
+type streamSubscriber struct {
+	wallets []solana.PublicKey
+	filter  *classify.TransactionFilter
+	types   map[string]bool
+	events  chan StreamEvent
+	closed  bool
+}
+
+// NewStreamHub creates a hub whose feeds live until ctx ends. With processed
+// set, feeds also subscribe over wsURL to report transactions before they are
+// confirmed.
+func NewStreamHub(ctx context.Context, client *rpc.Client, cluster *client.Cluster, wsURL string, processed bool) *StreamHub {
+	// The default commitment always validates
+	service, _ := fetch.NewTransactionService(client, fetch.Config{Now: now})
+	h := &StreamHub{
+		ctx:       ctx,
+		client:    client,
+		cluster:   cluster,
+		service:   service,
+		wsURL:     wsURL,
+		processed: processed,
+		feeds:     make(map[solana.PublicKey]*walletFeed),
+	}
+	h.tracker = NewStatusTracker(client, func(_ context.Context, ch StatusChange) {
+		h.publish(ch.Wallet.Account, StreamEvent{
+			Type:       StreamStatus,
+			Wallet:     ch.Wallet.Account,
+			Signature:  ch.Signature.String(),
+			Slot:       ch.Slot,
+			Status:     ch.To,
+			PrevStatus: ch.From,
+			Reason:     ch.Reason,
+			Time:       ch.Time,
+		})
+	})
+	go h.tracker.Run(ctx)
+	return h
+}
+
+// Subscribers returns how many clients are connected.
+func (h *StreamHub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	seen := make(map[*streamSubscriber]struct{})
+	for _, f := range h.feeds {
+		for s := range f.subs {
+			seen[s] = struct{}{}
+		}
+	}
+	return len(seen)
+}
+
+func (h *StreamHub) subscribe(sub *streamSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	streamSubscribers.Add(1)
+	for _, w := range sub.wallets {
+		f, ok := h.feeds[w]
+		if !ok {
+			ctx, cancel := context.WithCancel(h.ctx)
+			f = &walletFeed{cancel: cancel, subs: make(map[*streamSubscriber]struct{})}
+			h.feeds[w] = f
+			ww := WatchedWallet{Address: w.String(), Label: shortAddress(w.String()), Account: w}
+			go h.runFeed(walletLogContext(ctx, ww), ww)
+		}
+		f.subs[sub] = struct{}{}
+	}
+}
+
+func (h *StreamHub) unsubscribe(sub *streamSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	streamSubscribers.Add(-1)
+	for _, w := range sub.wallets {
+		f, ok := h.feeds[w]
+		if !ok {
+			continue
+		}
+		delete(f.subs, sub)
+		if len(f.subs) == 0 {
+			f.cancel()
+			delete(h.feeds, w)
+		}
+	}
+	if !sub.closed {
+		sub.closed = true
+		close(sub.events)
+	}
+}
+
+// runFeed is the single upstream for one wallet.
+func (h *StreamHub) runFeed(ctx context.Context, w WatchedWallet) {
+	if h.processed {
+		go subscribeProcessed(ctx, h.wsURL, w, h.tracker)
+	}
+	onNew := func(ctx context.Context, sig *rpc.TransactionSignature) error {
+		h.tracker.Observe(ctx, w, sig.Signature, sig.Slot, txStatusOf(sig.ConfirmationStatus), sig.Err != nil)
+		tx, err := h.service.FetchTransaction(ctx, sig.Signature, rpc.CommitmentConfirmed)
+		if err != nil {
+			return fmt.Errorf("fetch transaction: %w", err)
+		}
+		if tx.Slot == 0 {
+			tx.Slot = sig.Slot
+		}
+		view := NewTransactionView(*tx, &w.Account, h.cluster)
+		h.publish(w.Account, StreamEvent{
+			Type:        StreamTransaction,
+			Wallet:      w.Account,
+			Signature:   tx.Signature,
+			Slot:        tx.Slot,
+			Transaction: &view,
+			Time:        time.Now(),
+			tx:          tx,
+		})
+		return nil
+	}
+	if err := pollWalletTransactions(ctx, h.client, w.Account, defaultPollInterval, nil, onNew); err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "Stream feed stopped", "err", err)
+	}
+}
+
+// publish delivers ev to every interested subscriber of wallet. A subscriber
+// whose buffer is full is disconnected rather than slowing the others.
+func (h *StreamHub) publish(wallet solana.PublicKey, ev StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, ok := h.feeds[wallet]
+	if !ok {
+		return
+	}
+	for sub := range f.subs {
+		if sub.closed || !sub.wants(ev) {
+			continue
+		}
+		select {
+		case sub.events <- ev:
+		default:
+			slog.Warn("Stream subscriber fell behind, disconnecting", "buffer", streamBuffer)
+			sub.closed = true
+			close(sub.events)
+		}
+	}
+}
+
+func (s *streamSubscriber) wants(ev StreamEvent) bool {
+	if !s.types[ev.Type] {
+		return false
+	}
+	if ev.Type == StreamTransaction && ev.tx != nil {
+		return s.filter.Match(*ev.tx, ev.Wallet)
+	}
+	return true
+}
+
+// parseStreamRequest reads the wallets, filter and events query parameters.
+// labels resolves labels in the filter.
+func parseStreamRequest(r *http.Request, labels *addressbook.Book) (*streamSubscriber, error) {
+	q := r.URL.Query()
+	sub := &streamSubscriber{
+		types:  map[string]bool{StreamTransaction: true, StreamStatus: true},
+		events: make(chan StreamEvent, streamBuffer),
+	}
+	seen := make(map[solana.PublicKey]bool)
+	for _, v := range strings.Split(q.Get("wallets"), ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		pk, err := solana.PublicKeyFromBase58(v)
+		if err != nil {
+			return nil, badRequest("invalid wallet address %q", v)
+		}
+		if !seen[pk] {
+			seen[pk] = true
+			sub.wallets = append(sub.wallets, pk)
+		}
+	}
+	if len(sub.wallets) == 0 {
+		return nil, badRequest("wallets is required (comma-separated addresses)")
+	}
+	if len(sub.wallets) > maxStreamWallets {
+		return nil, badRequest("at most %d wallets per stream", maxStreamWallets)
+	}
+	filter, err := classify.ParseFilter(q.Get("filter"), now(), labels)
+	if err != nil {
+		return nil, badRequest("filter: %v", err)
+	}
+	sub.filter = filter
+	if v := q.Get("events"); v != "" {
+		sub.types = make(map[string]bool)
+		for _, t := range strings.Split(v, ",") {
+			switch t = strings.TrimSpace(t); t {
+			case StreamTransaction, StreamStatus:
+				sub.types[t] = true
+			default:
+				return nil, badRequest("unknown event type %q (want transaction or status)", t)
+			}
+		}
+	}
+	return sub, nil
+}
+
+// handleSSE serves GET /stream as Server-Sent Events.
+func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request) {
+	sub, err := parseStreamRequest(r, s.cfg.Labels)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, fmt.Errorf("streaming is not supported by this connection"))
+		return
+	}
+	s.hub.subscribe(sub)
+	defer s.hub.unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": subscribed\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case ev, ok := <-sub.events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Encoding stream event failed", "signature", ev.Signature, "err", err)
+				continue
+			}
+			id := ev.Signature
+			if ev.Status != "" {
+				id += ":" + string(ev.Status)
+			}
+			fmt.Fprintf(w, "event: %s\nid: %s\ndata: %s\n\n", ev.Type, id, data)
+		}
+		flusher.Flush()
+	}
+}
+
+var wsUpgrader = websocket.Upgrader{
+	// Browsers on other origins are allowed; put the API behind a proxy to restrict them
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// handleWebSocket serves GET /ws: the same events as /stream, one JSON
+// message each.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	sub, err := parseStreamRequest(r, s.cfg.Labels)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied to the client
+		return
+	}
+	defer conn.Close()
+	s.hub.subscribe(sub)
+	defer s.hub.unsubscribe(sub)
+
+	// Read only to notice the client going away; incoming messages are ignored
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), time.Now().Add(time.Second))
+			return
+		case <-gone:
+			return
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		case ev, ok := <-sub.events:
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow"), time.Now().Add(time.Second))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(ev); err != nil {
+				return
+			}
+		}
+	}
+}
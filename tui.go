@@ -0,0 +1,586 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"go-solana-tx-explorer/addressbook"
+	"go-solana-tx-explorer/classify"
+	"go-solana-tx-explorer/decode"
+	"go-solana-tx-explorer/fetch"
+	"go-solana-tx-explorer/portfolio"
+	"go-solana-tx-explorer/render"
+	"go-solana-tx-explorer/rpc/client"
+)
+
+const (
+	// defaultTUIPageSize is how many transactions each history page loads.
+	defaultTUIPageSize = 25
+	// tuiTailLines bounds the live tail pane.
+	tuiTailLines = 200
+	// tuiLogBuffer is how many log lines may wait for the UI before new ones
+	// are dropped.
+	tuiLogBuffer = 256
+)
+
+const tuiHelp = "[yellow]1[-]/[yellow]2[-] tabs  [yellow]/[-] filter  [yellow]Enter[-] details  [yellow]Esc[-] back  [yellow]m[-] older  [yellow]r[-] refresh portfolio  [yellow]q[-] quit"
+
+// runTUI implements `tui [flags] [address]`: an interactive browser for one
+// wallet with its transaction history, a detail pane, the token portfolio and
+// a live tail of new activity. Older history loads as the list is scrolled to
+// its end. Logs go to the tail pane while the UI owns the terminal.
+func runTUI(ctx context.Context, cfg *Config, args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	pageSize := fs.Int("page-size", defaultTUIPageSize, "transactions loaded per history page")
+	filterExpr := fs.String("filter", "", "initial filter expression, e.g. 'type:swap time>=7d' (editable with /)")
+	commitment := fs.String("commitment", string(rpc.CommitmentConfirmed), "earliest commitment the live tail reports transactions at: processed (WebSocket) or confirmed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *pageSize <= 0 {
+		return fmt.Errorf("-page-size must be positive, got %d", *pageSize)
+	}
+	processed := false
+	switch rpc.CommitmentType(*commitment) {
+	case rpc.CommitmentProcessed:
+		processed = true
+	case rpc.CommitmentConfirmed:
+	default:
+		return fmt.Errorf("unsupported -commitment %q; use processed or confirmed", *commitment)
+	}
+
+	account, err := walletArg(cfg, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	filter, err := classify.ParseFilter(*filterExpr, now(), cfg.Labels)
+	if err != nil {
+		return err
+	}
+	wsURL := ""
+	if processed {
+		if wsURL, err = cfg.RequireWSURL(); err != nil {
+			return err
+		}
+	}
+
+	rpcClient, err := cfg.NewClient()
+	if err != nil {
+		return err
+	}
+	cfg.ResolveCluster(ctx, rpcClient)
+	service, err := fetch.NewTransactionService(rpcClient, fetch.Config{Now: now})
+	if err != nil {
+		return err
+	}
+
+	// The UI owns the terminal from here on: send logs to the tail pane
+	logs := make(chan string, tuiLogBuffer)
+	prevLogger := slog.Default()
+	slog.SetDefault(newLogger(tuiLogWriter(logs), cfg.LogFormat, cfg.LogLevel))
+	defer slog.SetDefault(prevLogger)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cfg.Labels.AddWallet(account, "")
+	ui := newExplorerUI(ctx, service, portfolio.NewUserPortfolioService(rpcClient, portfolio.Config{Cluster: cfg.Cluster.Name, Registry: tokenRegistry}),
+		cfg.Cluster, cfg.Labels, account, *pageSize)
+	ui.filter = filter
+	ui.filterInput.SetText(*filterExpr)
+
+	hub := NewStreamHub(ctx, rpcClient, cfg.Cluster, wsURL, processed)
+	sub := &streamSubscriber{
+		wallets: []solana.PublicKey{account},
+		types:   map[string]bool{StreamTransaction: true, StreamStatus: true},
+		events:  make(chan StreamEvent, streamBuffer),
+	}
+	hub.subscribe(sub)
+	defer hub.unsubscribe(sub)
+
+	go ui.follow(sub.events, logs)
+	ui.startLoadOlder()
+	go ui.loadPortfolio()
+	go func() {
+		<-ctx.Done()
+		ui.app.Stop()
+	}()
+
+	if err := ui.app.Run(); err != nil {
+		return fmt.Errorf("terminal UI: %w", err)
+	}
+	return nil
+}
+
+// explorerUI is the state behind the tui command. Fields other than the
+// constructor's arguments are owned by the UI goroutine: background loaders
+// hand their results over with app.QueueUpdateDraw.
+type explorerUI struct {
+	ctx       context.Context
+	service   *fetch.TransactionService
+	portfolio *portfolio.UserPortfolioService
+	cluster   *client.Cluster
+	labels    *addressbook.Book
+	account   solana.PublicKey
+	pageSize  int
+
+	history   []decode.TransactionInfo // everything loaded, newest first
+	shown     []decode.TransactionInfo // history matching filter
+	filter    *classify.TransactionFilter
+	loading   bool
+	before    solana.Signature // cursor of the next older page
+	exhausted bool             // the oldest transaction has been loaded
+	rendering bool             // renderList is restoring the selection
+
+	app         *tview.Application
+	pages       *tview.Pages
+	tabs        *tview.TextView
+	filterInput *tview.InputField
+	list        *tview.Table
+	detail      *tview.TextView
+	holdings    *tview.Table
+	tail        *tview.TextView
+	status      *tview.TextView
+}
+
+func newExplorerUI(ctx context.Context, service *fetch.TransactionService, portfolioService *portfolio.UserPortfolioService, cluster *client.Cluster, labels *addressbook.Book, account solana.PublicKey, pageSize int) *explorerUI {
+	u := &explorerUI{
+		ctx:       ctx,
+		service:   service,
+		portfolio: portfolioService,
+		cluster:   cluster,
+		labels:    labels,
+		account:   account,
+		pageSize:  pageSize,
+		app:       tview.NewApplication(),
+	}
+
+	u.tabs = tview.NewTextView().SetDynamicColors(true).SetRegions(true)
+	fmt.Fprintf(u.tabs, ` ["transactions"] 1 Transactions [""]  ["portfolio"] 2 Portfolio [""]   [::d]%s on %s[::-]`, account, cluster.Name)
+
+	u.filterInput = tview.NewInputField().
+		SetLabel("Filter: ").
+		SetPlaceholder("e.g. status:failed type:swap sol>=1 time>=7d")
+	u.filterInput.SetDoneFunc(u.onFilterDone)
+
+	u.list = tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	u.list.SetBorder(true).SetTitle(" History ")
+	u.list.SetSelectionChangedFunc(u.onSelectionChanged)
+	u.list.SetSelectedFunc(func(int, int) { u.app.SetFocus(u.detail) })
+
+	u.detail = tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(true)
+	u.detail.SetBorder(true).SetTitle(" Details ")
+
+	u.holdings = tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	u.holdings.SetBorder(true).SetTitle(" SPL Token Holdings ")
+
+	u.tail = tview.NewTextView().SetDynamicColors(true).SetMaxLines(tuiTailLines)
+	u.tail.SetBorder(true).SetTitle(" Live ")
+
+	u.status = tview.NewTextView().SetDynamicColors(true)
+
+	browser := tview.NewFlex().
+		AddItem(u.list, 0, 2, true).
+		AddItem(u.detail, 0, 3, false)
+	transactions := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.filterInput, 1, 0, false).
+		AddItem(browser, 0, 1, true)
+	u.pages = tview.NewPages().
+		AddPage("transactions", transactions, true, true).
+		AddPage("portfolio", u.holdings, true, false)
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.tabs, 1, 0, false).
+		AddItem(u.pages, 0, 1, true).
+		AddItem(u.tail, 8, 0, false).
+		AddItem(u.status, 1, 0, false)
+
+	u.app.SetRoot(root, true).SetFocus(u.list).SetInputCapture(u.onKey)
+	u.showTab("transactions")
+	u.renderList()
+	u.setStatus("")
+	return u
+}
+
+// onKey handles the global shortcuts. Keys typed into the filter field are
+// left alone.
+func (u *explorerUI) onKey(ev *tcell.EventKey) *tcell.EventKey {
+	if u.app.GetFocus() == u.filterInput {
+		return ev
+	}
+	switch ev.Key() {
+	case tcell.KeyEsc:
+		if name, _ := u.pages.GetFrontPage(); name == "transactions" {
+			u.app.SetFocus(u.list)
+		}
+		return nil
+	case tcell.KeyTab:
+		if name, _ := u.pages.GetFrontPage(); name == "transactions" {
+			u.showTab("portfolio")
+		} else {
+			u.showTab("transactions")
+		}
+		return nil
+	case tcell.KeyRune:
+	default:
+		return ev
+	}
+	switch ev.Rune() {
+	case '1':
+		u.showTab("transactions")
+	case '2':
+		u.showTab("portfolio")
+	case '/':
+		u.showTab("transactions")
+		u.app.SetFocus(u.filterInput)
+	case 'm':
+		u.startLoadOlder()
+	case 'r':
+		go u.loadPortfolio()
+	case 'q':
+		u.app.Stop()
+	default:
+		return ev
+	}
+	return nil
+}
+
+func (u *explorerUI) showTab(name string) {
+	u.pages.SwitchToPage(name)
+	u.tabs.Highlight(name)
+	if name == "portfolio" {
+		u.app.SetFocus(u.holdings)
+	} else {
+		u.app.SetFocus(u.list)
+	}
+}
+
+// setStatus shows msg, or the key help when msg is empty.
+func (u *explorerUI) setStatus(msg string) {
+	if msg == "" {
+		msg = tuiHelp
+	}
+	u.status.SetText(" " + msg)
+}
+
+func (u *explorerUI) onFilterDone(key tcell.Key) {
+	if key == tcell.KeyEnter {
+		filter, err := classify.ParseFilter(u.filterInput.GetText(), now(), u.labels)
+		if err != nil {
+			u.setStatus("[red]filter: " + tview.Escape(err.Error()) + "[-]")
+			return
+		}
+		u.filter = filter
+		u.renderList()
+		u.setStatus("")
+	}
+	u.app.SetFocus(u.list)
+}
+
+// onSelectionChanged shows the selected transaction and loads older history
+// once the selection reaches the end of the list.
+func (u *explorerUI) onSelectionChanged(row, _ int) {
+	i := row - 1
+	if i < 0 || i >= len(u.shown) {
+		return
+	}
+	u.detail.SetText(u.describe(u.shown[i])).ScrollToBeginning()
+	if i == len(u.shown)-1 && !u.rendering {
+		u.startLoadOlder()
+	}
+}
+
+// renderList redraws the history table from history and filter, keeping the
+// selected transaction selected.
+func (u *explorerUI) renderList() {
+	selected := ""
+	if row, _ := u.list.GetSelection(); row > 0 && row-1 < len(u.shown) {
+		selected = u.shown[row-1].Signature
+	}
+
+	u.shown = u.shown[:0]
+	for _, tx := range u.history {
+		if u.filter.Match(tx, u.account) {
+			u.shown = append(u.shown, tx)
+		}
+	}
+
+	u.list.Clear()
+	for col, h := range []string{"Signature", "Status", "Type", "Time", "SOL Change"} {
+		u.list.SetCell(0, col, tview.NewTableCell(h).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+	}
+	selectRow := 1
+	for i, tx := range u.shown {
+		c := classify.ClassifyTransaction(tx, u.account)
+		status, color := "success", tcell.ColorGreen
+		if tx.Meta != nil && tx.Meta.Err != nil {
+			status, color = "failed", tcell.ColorRed
+		}
+		txType := string(c.Type)
+		if c.Direction != "" {
+			txType += " (" + c.Direction + ")"
+		}
+		when := "N/A"
+		if tx.BlockTime != nil {
+			when = time.Unix(*tx.BlockTime, 0).Format("01-02 15:04")
+		}
+		row := i + 1
+		u.list.SetCell(row, 0, tview.NewTableCell(render.ShortAddress(tx.Signature)))
+		u.list.SetCell(row, 1, tview.NewTableCell(status).SetTextColor(color))
+		u.list.SetCell(row, 2, tview.NewTableCell(txType))
+		u.list.SetCell(row, 3, tview.NewTableCell(when))
+		u.list.SetCell(row, 4, tview.NewTableCell(fmt.Sprintf("%+.6f", float64(c.SOLChange)/1e9)).SetAlign(tview.AlignRight))
+		if tx.Signature == selected {
+			selectRow = row
+		}
+	}
+
+	title := fmt.Sprintf(" History: %d of %d loaded ", len(u.shown), len(u.history))
+	switch {
+	case u.loading:
+		title += "(loading…) "
+	case u.exhausted:
+		title += "(complete) "
+	}
+	u.list.SetTitle(title)
+	if len(u.shown) == 0 {
+		u.detail.SetText("[::d]No transactions match.[::-]")
+		return
+	}
+	u.rendering = true
+	u.list.Select(selectRow, 0)
+	u.rendering = false
+}
+
+// startLoadOlder loads the next page of history in the background unless a
+// load is running or the history is complete. It runs on the UI goroutine,
+// which also marks the load as running so repeated keys start only one.
+func (u *explorerUI) startLoadOlder() {
+	if u.loading || u.exhausted {
+		return
+	}
+	u.loading = true
+	u.renderList()
+	go u.loadOlder(u.before)
+}
+
+// loadOlder fetches the page of history before the given cursor.
+func (u *explorerUI) loadOlder(before solana.Signature) {
+	page, err := u.service.FetchAccountTransactionsBefore(u.ctx, u.account, u.pageSize, before)
+	if u.ctx.Err() != nil {
+		return
+	}
+	u.app.QueueUpdateDraw(func() {
+		u.loading = false
+		if err != nil {
+			u.setStatus("[red]loading history: " + tview.Escape(err.Error()) + "[-]")
+			u.renderList()
+			return
+		}
+		// As in the HTTP API, the signature listing tells whether older
+		// history remains, even when some of its transactions failed to load
+		u.before = page.NextBefore
+		u.exhausted = page.NextBefore.IsZero()
+		u.history = append(u.history, page.Transactions...)
+		u.renderList()
+	})
+}
+
+// loadPortfolio fetches the wallet's token holdings into the portfolio tab.
+func (u *explorerUI) loadPortfolio() {
+	u.app.QueueUpdateDraw(func() { u.holdings.SetTitle(" SPL Token Holdings (loading…) ") })
+	holdings, err := u.portfolio.FetchUserTokens(u.ctx, u.account)
+	if u.ctx.Err() != nil {
+		return
+	}
+	u.app.QueueUpdateDraw(func() {
+		u.holdings.Clear()
+		if err != nil {
+			u.holdings.SetTitle(" SPL Token Holdings ")
+			u.setStatus("[red]loading portfolio: " + tview.Escape(err.Error()) + "[-]")
+			return
+		}
+		for col, h := range []string{"Name", "Symbol", "Mint", "Amount", "Decimals"} {
+			u.holdings.SetCell(0, col, tview.NewTableCell(h).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+		}
+		for i, h := range holdings {
+			name, symbol := h.Name, h.Symbol
+			if name == "" {
+				name = "—"
+			}
+			if symbol == "" {
+				symbol = "—"
+			}
+			row := i + 1
+			u.holdings.SetCell(row, 0, tview.NewTableCell(tview.Escape(name)))
+			u.holdings.SetCell(row, 1, tview.NewTableCell(tview.Escape(symbol)))
+			mint := h.Mint
+			if key, err := solana.PublicKeyFromBase58(h.Mint); err == nil {
+				mint = accountName(u.labels, key, false)
+			}
+			u.holdings.SetCell(row, 2, tview.NewTableCell(tview.Escape(mint)))
+			u.holdings.SetCell(row, 3, tview.NewTableCell(h.UiAmount).SetAlign(tview.AlignRight))
+			u.holdings.SetCell(row, 4, tview.NewTableCell(fmt.Sprint(h.Decimals)).SetAlign(tview.AlignRight))
+		}
+		u.holdings.SetTitle(fmt.Sprintf(" SPL Token Holdings: %d (refreshed %s) ", len(holdings), now().Format("15:04:05")))
+	})
+}
+
+// follow writes live events and log lines to the tail pane until ctx ends.
+// New transactions also join the history list.
+func (u *explorerUI) follow(events <-chan StreamEvent, logs <-chan string) {
+	for {
+		select {
+		case <-u.ctx.Done():
+			return
+		case line := <-logs:
+			u.app.QueueUpdateDraw(func() {
+				fmt.Fprintf(u.tail, "[::d]%s[::-]\n", tview.Escape(strings.TrimRight(line, "\n")))
+				u.tail.ScrollToEnd()
+			})
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			u.app.QueueUpdateDraw(func() {
+				u.onStreamEvent(ev)
+				u.tail.ScrollToEnd()
+			})
+		}
+	}
+}
+
+func (u *explorerUI) onStreamEvent(ev StreamEvent) {
+	at := ev.Time.Format("15:04:05")
+	switch ev.Type {
+	case StreamStatus:
+		change := fmt.Sprintf("%s → %s", ev.PrevStatus, ev.Status)
+		if ev.PrevStatus == "" {
+			change = fmt.Sprintf("seen %s", ev.Status)
+		}
+		line := fmt.Sprintf("%s %s %s (slot %d)", at, render.ShortAddress(ev.Signature), change, ev.Slot)
+		if ev.Reason != "" {
+			line += ": " + tview.Escape(ev.Reason)
+		}
+		fmt.Fprintln(u.tail, line)
+	case StreamTransaction:
+		if ev.tx == nil {
+			return
+		}
+		for _, tx := range u.history {
+			if tx.Signature == ev.tx.Signature {
+				return
+			}
+		}
+		c := classify.ClassifyTransaction(*ev.tx, u.account)
+		fmt.Fprintf(u.tail, "%s [green]NEW[-] %s %s %+.6f SOL (slot %d)\n", at, render.ShortAddress(ev.Signature), c.Type, float64(c.SOLChange)/1e9, ev.Slot)
+		u.history = append([]decode.TransactionInfo{*ev.tx}, u.history...)
+		u.renderList()
+	}
+}
+
+// describe renders tx for the detail pane: what it did for the wallet, its
+// balance changes, decoded instructions and logs.
+func (u *explorerUI) describe(tx decode.TransactionInfo) string {
+	u.labels.LabelTokenAccounts(tx)
+	v := NewTransactionView(tx, &u.account, u.cluster)
+	var b strings.Builder
+	section := func(title string) { fmt.Fprintf(&b, "\n[yellow::b]%s[-::-]\n", title) }
+	field := func(name string, value any) { fmt.Fprintf(&b, "[::b]%-12s[::-] %v\n", name, value) }
+
+	field("Signature", v.Signature)
+	field("Slot", v.Slot)
+	if v.BlockTime != nil {
+		field("Block Time", v.BlockTime.Format(time.RFC3339))
+	}
+	if v.Error != nil {
+		field("Status", fmt.Sprintf("[red]failed[-]: %s", tview.Escape(fmt.Sprint(v.Error))))
+	} else {
+		field("Status", "[green]success[-]")
+	}
+	if v.Fee != nil {
+		field("Fee", fmt.Sprintf("%.9f SOL (priority %d lamports)", float64(v.Fee.TotalFee)/1e9, v.Fee.PriorityFee))
+	}
+	if c := v.Classification; c != nil {
+		txType := string(c.Type)
+		if c.Direction != "" {
+			txType += " (" + c.Direction + ")"
+		}
+		field("Type", txType)
+		field("SOL Change", fmt.Sprintf("%+.9f", float64(c.SOLChange)/1e9))
+		for _, tc := range c.TokenChanges {
+			field("Token", fmt.Sprintf("%+.*f %s", int(tc.Decimals), tc.Delta, tview.Escape(accountName(u.labels, tc.Mint, true))))
+		}
+	}
+	if v.Explorer != "" {
+		field("Explorer", v.Explorer)
+	}
+
+	if changes := render.SOLBalanceChanges(tx); len(changes) > 0 {
+		section("SOL Balance Changes")
+		for _, c := range changes {
+			color := "green"
+			if c.Delta() < 0 {
+				color = "red"
+			}
+			fmt.Fprintf(&b, "  %s  %.6f → %.6f  [%s]%+.6f[-]\n", tview.Escape(accountName(u.labels, c.Account, false)), float64(c.Pre)/1e9, float64(c.Post)/1e9, color, float64(c.Delta())/1e9)
+		}
+	}
+	if changes := render.TokenBalanceChanges(tx); len(changes) > 0 {
+		section("Token Balances")
+		for _, c := range changes {
+			owner := "unknown owner"
+			if c.Owner != nil {
+				owner = accountName(u.labels, *c.Owner, true)
+			}
+			line := fmt.Sprintf("  %s: %s (%s)  %s → %s", accountName(u.labels, c.Account, true),
+				accountName(u.labels, c.Mint, true), owner, c.Pre, c.Post)
+			fmt.Fprintln(&b, tview.Escape(line))
+		}
+	}
+
+	if len(v.Instructions) > 0 {
+		section("Instructions")
+		for i, instr := range v.Instructions {
+			program := instr.Program
+			if program == "" {
+				program = accountName(u.labels, instr.ProgramID, false)
+			}
+			line := fmt.Sprintf("  %d. %s: %s", i+1, program, instr.Name)
+			if instr.Details != "" {
+				line += " (" + instr.Details + ")"
+			}
+			fmt.Fprintln(&b, tview.Escape(line))
+			for _, acc := range instr.Accounts {
+				fmt.Fprintf(&b, "       [::d]%s[::-]\n", tview.Escape(accountName(u.labels, acc, false)))
+			}
+		}
+	}
+
+	if len(v.Logs) > 0 {
+		section("Program Logs")
+		for _, l := range v.Logs {
+			fmt.Fprintf(&b, "  %s\n", tview.Escape(l))
+		}
+	}
+	return b.String()
+}
+
+// tuiLogWriter sends each log line to lines, dropping it when the UI has
+// fallen behind rather than blocking the caller.
+type tuiLogWriter chan<- string
+
+func (w tuiLogWriter) Write(p []byte) (int, error) {
+	select {
+	case w <- string(p):
+	default:
+	}
+	return len(p), nil
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"errors"
-	"log"
-	"os"
+	"net/http"
+	"time"
 
 	"github.com/gagliardetto/solana-go"
-	"github.com/joho/godotenv"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/registry"
+	"go-solana-tx-explorer/rpc/client"
 )
 
 func GetAccountFromPublicKey(pubKey string) (solana.PublicKey, error) {
@@ -18,61 +22,39 @@ func GetAccountFromPublicKey(pubKey string) (solana.PublicKey, error) {
 	return account, nil
 }
 
-func GetRPCURL() string {
-	err := godotenv.Load()
-	if err != nil {
-		log.Printf("Warning: Error loading .env file: %v", err)
-	}
-
-	rpcURL := os.Getenv("RPC_URL")
-	if rpcURL == "" {
-		log.Fatal("RPC_URL environment variable is required")
-	}
-	return rpcURL
+// now is the clock behind user-visible times such as "last fetched" and
+// relative filters. Replaying fixtures pins it to the recording time.
+var now = time.Now
+
+// rpcTransport, when set, carries every RPC client's HTTP traffic; recording
+// fixtures sets it.
+var rpcTransport http.RoundTripper
+
+// tokenRegistry names mints for every command. Replaying fixtures swaps in an
+// offline one.
+var tokenRegistry = registry.New(registry.Config{})
+
+// NewRateLimitedClient returns an RPC client whose requests all draw from one
+// token bucket, so goroutines sharing it stay under requestsPerSecond together.
+// A non-positive rate disables limiting. Every call is recorded in the RPC
+// metrics and, at debug level, the log.
+func NewRateLimitedClient(rpcURL string, requestsPerSecond float64) *rpc.Client {
+	return client.New(rpcURL, client.Config{
+		RequestsPerSecond: requestsPerSecond,
+		Transport:         rpcTransport,
+		OnCall:            recordRPCCall,
+	})
 }
 
-func GetWalletAddress() string {
-	err := godotenv.Load()
-	if err != nil {
-		log.Printf("Warning: Error loading .env file: %v", err)
-	}
-
-	walletAddr := os.Getenv("WALLET_ADDRESS")
-	if walletAddr == "" {
-		log.Fatal("WALLET_ADDRESS environment variable is required")
-	}
-	return walletAddr
-}
-
-// GetWSURL returns the WebSocket RPC URL. If WS_URL is not set, it tries to
-// derive it from RPC_URL by replacing the scheme with wss:// when possible.
-func GetWSURL() string {
-	err := godotenv.Load()
-	if err != nil {
-		log.Printf("Warning: Error loading .env file: %v", err)
-	}
-
-	wsURL := os.Getenv("WS_URL")
-	if wsURL != "" {
-		return wsURL
-	}
-	httpURL := os.Getenv("RPC_URL")
-	if httpURL == "" {
-		log.Fatal("WS_URL or RPC_URL environment variable is required")
-	}
-	// naive derive: support https:// → wss://, http:// → ws://
-	if len(httpURL) >= 8 && httpURL[:8] == "https://" {
-		return "wss://" + httpURL[8:]
-	}
-	if len(httpURL) >= 7 && httpURL[:7] == "http://" {
-		return "ws://" + httpURL[7:]
-	}
-	// if already ws/wss, return as-is
-	if len(httpURL) >= 6 && httpURL[:6] == "wss://" {
-		return httpURL
-	}
-	if len(httpURL) >= 5 && httpURL[:5] == "ws://" {
-		return httpURL
+// recordRPCCall updates the RPC metrics after every call.
+func recordRPCCall(_ context.Context, call client.Call) {
+	rpcRequestDuration.Observe(call.Duration.Seconds(), call.Method, call.Endpoint)
+	rpcRequestsTotal.Inc(call.Method, call.Endpoint, call.Result)
+	if call.Method == "getTransaction" {
+		result := "ok"
+		if call.Err != nil {
+			result = "failed"
+		}
+		transactionsFetchedTotal.Inc(result)
 	}
-	return httpURL
 }
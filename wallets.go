@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+
+	"go-solana-tx-explorer/portfolio"
+)
+
+// walletFlags are the flags shared by every command that can run across a
+// watchlist instead of a single address.
+type walletFlags struct {
+	watchlist *string
+	tags      *string
+	rps       *float64
+}
+
+func addWalletFlags(fs *flag.FlagSet) walletFlags {
+	return walletFlags{
+		watchlist: fs.String("watchlist", "", "watchlist file (.yaml, .json or .toml) with the wallets to process"),
+		tags:      fs.String("tag", "", "only process watchlist wallets with one of these comma-separated tags"),
+		rps:       fs.Float64("rps", 0, "shared RPC requests per second across wallets (default: watchlist setting or 10)"),
+	}
+}
+
+// resolve returns the watchlist and the selected wallets. Without -watchlist
+// the positional address (or the configured wallet) becomes a one-wallet list.
+// The rate limit comes from -rps, then the watchlist, then the config.
+func (wf walletFlags) resolve(cfg *Config, address string) (*Watchlist, []WatchedWallet, error) {
+	var wl *Watchlist
+	var err error
+	if *wf.watchlist != "" {
+		wl, err = LoadWatchlist(*wf.watchlist, cfg.Labels)
+	} else {
+		if address == "" {
+			wallet, werr := cfg.RequireWallet()
+			if werr != nil {
+				return nil, nil, werr
+			}
+			address = wallet.String()
+		}
+		wl, err = SingleWalletWatchlist(address, cfg.Labels)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if *wf.rps > 0 {
+		wl.RequestsPerSecond = *wf.rps
+	} else if wl.RequestsPerSecond == 0 {
+		wl.RequestsPerSecond = cfg.RequestsPerSecond
+	}
+
+	var tags []string
+	for _, t := range strings.Split(*wf.tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	wallets := wl.Select(tags)
+	if len(wallets) == 0 {
+		return nil, nil, fmt.Errorf("no enabled wallets match tags %v", tags)
+	}
+	return wl, wallets, nil
+}
+
+// walletArg returns the wallet named by a command's positional address, or
+// the configured wallet when there is none.
+func walletArg(cfg *Config, address string) (solana.PublicKey, error) {
+	if address == "" {
+		return cfg.RequireWallet()
+	}
+	account, err := solana.PublicKeyFromBase58(address)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	return account, nil
+}
+
+// forEachWallet runs fn for every wallet with at most workers in flight and
+// returns when all are done. Results are written by fn itself (by index).
+// Once ctx ends the remaining wallets no longer wait for a worker slot, so fn
+// records ctx's error for each of them promptly.
+func forEachWallet(ctx context.Context, wallets []WatchedWallet, workers int, fn func(ctx context.Context, i int, w WatchedWallet)) {
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, w := range wallets {
+		wg.Add(1)
+		go func(i int, w WatchedWallet) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				// Still call fn so it records ctx's error for this wallet
+			}
+			fn(walletLogContext(ctx, w), i, w)
+		}(i, w)
+	}
+	wg.Wait()
+}
+
+// runPortfolio implements `portfolio [flags] [address]`.
+func runPortfolio(ctx context.Context, cfg *Config, args []string) error {
+	fs := flag.NewFlagSet("portfolio", flag.ContinueOnError)
+	wf := addWalletFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	wl, wallets, err := wf.resolve(cfg, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	rpcURL, err := cfg.RequireRPCURL()
+	if err != nil {
+		return err
+	}
+
+	client := NewRateLimitedClient(rpcURL, wl.RateLimit())
+	cfg.ResolveCluster(ctx, client)
+	service := portfolio.NewUserPortfolioService(client, portfolio.Config{Cluster: cfg.Cluster.Name, Registry: tokenRegistry})
+
+	results := make([]WalletPortfolio, len(wallets))
+	forEachWallet(ctx, wallets, wl.Workers(), func(ctx context.Context, i int, w WatchedWallet) {
+		holdings, err := service.FetchUserTokens(ctx, w.Account)
+		results[i] = WalletPortfolio{Wallet: w, Holdings: holdings, Err: err}
+	})
+
+	formatter := newFormatter(cfg, false, cfg.Cluster)
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			slog.Error("Portfolio failed", "wallet", r.Wallet.Account.String(), "label", r.Wallet.Label, "err", r.Err)
+			failed++
+			continue
+		}
+		if len(wallets) > 1 {
+			if err := formatter.FormatWalletHeader(r.Wallet); err != nil {
+				return err
+			}
+		}
+		if err := formatter.FormatUserPortfolio(r.Wallet.Account, r.Holdings); err != nil {
+			return err
+		}
+	}
+	if len(wallets) > 1 {
+		if err := formatter.FormatCombinedPortfolio(results); err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d wallets failed", failed, len(wallets))
+	}
+	return nil
+}
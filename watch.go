@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/classify"
+	"go-solana-tx-explorer/fetch"
+	"go-solana-tx-explorer/rpc/client"
+	"go-solana-tx-explorer/store"
+)
+
+// runWatch implements `watch [flags] [address]`: poll every selected wallet
+// for new transactions on a shared, rate-limited client. With -rules or -notify
+// each new transaction is fetched and classified, checked against the alert
+// rules and sent to the notification sinks. Every transaction is followed to
+// finalized (or reported dropped); -commitment processed reports it earlier.
+func runWatch(ctx context.Context, cfg *Config, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	wf := addWalletFlags(fs)
+	rulesPath := fs.String("rules", "", "alert rules file (.yaml, .json or .toml) evaluated on every new transaction")
+	notifyPath := fs.String("notify", "", "notification sinks file (.yaml, .json or .toml) for alerts and new transactions")
+	commitment := fs.String("commitment", string(rpc.CommitmentConfirmed), "earliest commitment to report new transactions at: processed (WebSocket) or confirmed")
+	checkpointPath := fs.String("checkpoint", "", "JSON file recording the last processed signature per wallet; catch up from it on start")
+	metricsAddr := fs.String("metrics-addr", "", "serve /metrics, /healthz and /readyz on this address (e.g. :9090)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	processed := false
+	switch rpc.CommitmentType(*commitment) {
+	case rpc.CommitmentProcessed:
+		processed = true
+	case rpc.CommitmentConfirmed:
+	default:
+		return fmt.Errorf("unsupported -commitment %q; use processed or confirmed", *commitment)
+	}
+
+	wl, wallets, err := wf.resolve(cfg, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	wsURL, err := cfg.RequireWSURL()
+	if err != nil {
+		return err
+	}
+
+	var engine *AlertEngine
+	if *rulesPath != "" {
+		if engine, err = LoadAlertRules(*rulesPath); err != nil {
+			return err
+		}
+	}
+	var dispatcher *Dispatcher
+	if *notifyPath != "" {
+		if dispatcher, err = LoadNotifySinks(*notifyPath); err != nil {
+			return err
+		}
+	}
+	var checkpoints *store.CheckpointStore
+	if *checkpointPath != "" {
+		if checkpoints, err = store.OpenCheckpointStore(*checkpointPath); err != nil {
+			return err
+		}
+	}
+	fetchTxs := engine != nil || dispatcher.Wants(NotifyTransaction)
+
+	client := NewRateLimitedClient(httpURLFromWS(wsURL), wl.RateLimit())
+	cfg.ResolveCluster(ctx, client)
+	service, err := fetch.NewTransactionService(client, fetch.Config{Now: now})
+	if err != nil {
+		return err
+	}
+	formatter := newFormatter(cfg, false, cfg.Cluster)
+	if *metricsAddr != "" {
+		intervals := make(map[solana.PublicKey]time.Duration, len(wallets))
+		for _, w := range wallets {
+			intervals[w.Account] = wl.Resolve(w).PollInterval
+		}
+		if err := serveOps(ctx, cfg, *metricsAddr, rpcReadiness(client), listenerReadiness(intervals)); err != nil {
+			return err
+		}
+	}
+	slog.Info("Listening (poll) for transactions", "wallets", len(wallets))
+
+	// Follow every transaction until it finalizes or is dropped
+	tracker := NewStatusTracker(client, func(ctx context.Context, ch StatusChange) {
+		if err := formatter.FormatStatusChange(ch); err != nil {
+			slog.ErrorContext(ctx, "Writing output failed", "err", err)
+		}
+		dispatcher.Dispatch(StatusNotification(ch, cfg.Cluster))
+	})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tracker.Run(ctx)
+	}()
+
+	for _, w := range wallets {
+		settings := wl.Resolve(w)
+		wctx := walletLogContext(ctx, w)
+
+		onNew := func(ctx context.Context, sig *rpc.TransactionSignature) error {
+			tracker.Observe(ctx, w, sig.Signature, sig.Slot, txStatusOf(sig.ConfirmationStatus), sig.Err != nil)
+			if !fetchTxs {
+				return nil
+			}
+			return handleNewTransaction(ctx, service, engine, dispatcher, formatter, cfg.Cluster, w, sig)
+		}
+		if processed {
+			wg.Add(1)
+			go func(w WatchedWallet) {
+				defer wg.Done()
+				subscribeProcessed(wctx, wsURL, w, tracker)
+			}(w)
+		}
+
+		wg.Add(1)
+		go func(w WatchedWallet) {
+			defer wg.Done()
+			if engine != nil {
+				seedAlertRules(wctx, service, engine, w)
+			}
+			if err := pollWalletTransactions(wctx, client, w.Account, settings.PollInterval, checkpoints, onNew); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(wctx, "Listener stopped", "err", err)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// Listeners have stopped; flush what they produced within the shutdown timeout
+	flushCtx, cancel := shutdownContext(ctx, cfg)
+	defer cancel()
+	if n := tracker.Pending(); n > 0 {
+		slog.Info("Transactions not finalized yet are no longer tracked", "pending", n)
+	}
+	if err := checkpoints.Flush(); err != nil {
+		slog.Error("Flushing checkpoints failed", "err", err)
+	}
+	if dispatcher != nil {
+		if err := dispatcher.Close(flushCtx); err != nil {
+			slog.Error("Flushing notifications failed", "err", err)
+		}
+		if err := formatter.FormatDeliveryStatus(dispatcher.Status()); err != nil {
+			slog.Error("Writing output failed", "err", err)
+		}
+	}
+	return ctx.Err()
+}
+
+// seedAlertRules primes stateful rules with as much of the wallet's recent
+// history as they ask for, so existing counterparties are not reported as new.
+func seedAlertRules(ctx context.Context, service *fetch.TransactionService, engine *AlertEngine, w WatchedWallet) {
+	limit := engine.SeedLimit(w)
+	if limit == 0 {
+		return
+	}
+	history, err := service.FetchAccountTransactions(ctx, w.Account, limit)
+	if err != nil {
+		slog.WarnContext(ctx, "Could not seed alert rules", "err", err)
+		return
+	}
+	engine.Seed(w, history.Transactions)
+}
+
+// handleNewTransaction fetches and classifies a newly observed transaction,
+// notifies the sinks, and prints and notifies every alert it raises. engine
+// and dispatcher may be nil. It fails when the transaction cannot be fetched
+// or a sink could not take a notification, so the caller retries it later.
+func handleNewTransaction(ctx context.Context, service *fetch.TransactionService, engine *AlertEngine, dispatcher *Dispatcher, formatter *cliFormatter, cluster *client.Cluster, w WatchedWallet, sig *rpc.TransactionSignature) error {
+	info, err := service.FetchTransaction(ctx, sig.Signature, rpc.CommitmentConfirmed)
+	if err != nil {
+		return fmt.Errorf("fetch transaction: %w", err)
+	}
+	if info.Slot == 0 {
+		info.Slot = sig.Slot
+	}
+	ev := TransactionEvent{Wallet: w, Tx: *info, Classification: classify.ClassifyTransaction(*info, w.Account)}
+	if err := dispatcher.Deliver(ctx, TransactionNotification(ev, cluster)); err != nil {
+		return fmt.Errorf("notify transaction: %w", err)
+	}
+	if engine == nil {
+		return nil
+	}
+	alerts := engine.Evaluate(ev)
+	for i, a := range alerts {
+		// The alert still goes to the sinks when it cannot be printed
+		if err := formatter.FormatAlert(a); err != nil {
+			slog.ErrorContext(ctx, "Writing output failed", "err", err)
+		}
+		if err := dispatcher.Deliver(ctx, AlertNotification(a, cluster)); err != nil {
+			// Let the undelivered alerts fire again on the retry
+			for _, rest := range alerts[i:] {
+				engine.Release(rest)
+			}
+			return fmt.Errorf("notify alert %s: %w", a.Rule, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"go-solana-tx-explorer/addressbook"
+	"go-solana-tx-explorer/classify"
+)
+
+const (
+	defaultPollInterval      = 4 * time.Second
+	defaultRequestsPerSecond = 10
+	defaultWalletConcurrency = 4
+)
+
+// WalletSettings are the per-wallet knobs. Zero values fall back to the
+// watchlist defaults, then to the built-in defaults.
+type WalletSettings struct {
+	Limit        int    `json:"limit,omitempty" yaml:"limit,omitempty" toml:"limit,omitempty"`
+	Filter       string `json:"filter,omitempty" yaml:"filter,omitempty" toml:"filter,omitempty"`
+	PollInterval string `json:"poll_interval,omitempty" yaml:"poll_interval,omitempty" toml:"poll_interval,omitempty"`
+}
+
+// WatchedWallet is one entry of a watchlist.
+type WatchedWallet struct {
+	Address  string   `json:"address" yaml:"address" toml:"address"`
+	Label    string   `json:"label,omitempty" yaml:"label,omitempty" toml:"label,omitempty"`
+	Tags     []string `json:"tags,omitempty" yaml:"tags,omitempty" toml:"tags,omitempty"`
+	Disabled bool     `json:"disabled,omitempty" yaml:"disabled,omitempty" toml:"disabled,omitempty"`
+
+	WalletSettings `yaml:",inline"`
+
+	Account solana.PublicKey `json:"-" yaml:"-" toml:"-"`
+}
+
+// Watchlist is a set of wallets monitored together. All wallets share one RPC
+// client, so RequestsPerSecond caps the combined request rate.
+type Watchlist struct {
+	RequestsPerSecond float64         `json:"requests_per_second,omitempty" yaml:"requests_per_second,omitempty" toml:"requests_per_second,omitempty"`
+	Concurrency       int             `json:"concurrency,omitempty" yaml:"concurrency,omitempty" toml:"concurrency,omitempty"`
+	Defaults          WalletSettings  `json:"defaults,omitempty" yaml:"defaults,omitempty" toml:"defaults,omitempty"`
+	Wallets           []WatchedWallet `json:"wallets" yaml:"wallets" toml:"wallets"`
+
+	// labels resolves labels in filters.
+	labels *addressbook.Book
+}
+
+// ResolvedWalletSettings are a wallet's effective settings after defaults.
+type ResolvedWalletSettings struct {
+	Limit        int
+	Filter       *classify.TransactionFilter
+	PollInterval time.Duration
+}
+
+// LoadWatchlist reads a watchlist file. The format is picked from the file
+// extension: .yaml/.yml, .json or .toml. Its wallets are added to labels,
+// which also resolves labels in their filters.
+func LoadWatchlist(path string, labels *addressbook.Book) (*Watchlist, error) {
+	wl := Watchlist{labels: labels}
+	if err := decodeConfigFile(path, &wl); err != nil {
+		return nil, fmt.Errorf("watchlist %s: %w", path, err)
+	}
+	if err := wl.validate(); err != nil {
+		return nil, fmt.Errorf("watchlist %s: %w", path, err)
+	}
+	return &wl, nil
+}
+
+// SingleWalletWatchlist wraps one address so single-wallet commands can share
+// the watchlist code path.
+func SingleWalletWatchlist(address string, labels *addressbook.Book) (*Watchlist, error) {
+	wl := &Watchlist{Wallets: []WatchedWallet{{Address: address}}, labels: labels}
+	if err := wl.validate(); err != nil {
+		return nil, err
+	}
+	return wl, nil
+}
+
+func (wl *Watchlist) validate() error {
+	if len(wl.Wallets) == 0 {
+		return errors.New("no wallets defined")
+	}
+	if wl.RequestsPerSecond < 0 {
+		return errors.New("requests_per_second must not be negative")
+	}
+	if wl.Concurrency < 0 {
+		return errors.New("concurrency must not be negative")
+	}
+	seen := make(map[solana.PublicKey]int)
+	for i := range wl.Wallets {
+		w := &wl.Wallets[i]
+		name := fmt.Sprintf("wallet #%d", i+1)
+		if w.Label != "" {
+			name = fmt.Sprintf("wallet #%d (%s)", i+1, w.Label)
+		}
+		account, err := GetAccountFromPublicKey(strings.TrimSpace(w.Address))
+		if err != nil {
+			return fmt.Errorf("%s: invalid address %q", name, w.Address)
+		}
+		if prev, dup := seen[account]; dup {
+			return fmt.Errorf("%s: address %s already listed as wallet #%d", name, account, prev)
+		}
+		seen[account] = i + 1
+		w.Account = account
+		wl.labels.AddWallet(account, w.Label)
+		if w.Label == "" {
+			w.Label = shortAddress(account.String())
+		}
+	}
+
+	// Filters may name any wallet of the list, so they are checked last
+	if err := wl.validateSettings(wl.Defaults); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+	for i, w := range wl.Wallets {
+		if err := wl.validateSettings(w.WalletSettings); err != nil {
+			return fmt.Errorf("wallet #%d (%s): %w", i+1, w.Label, err)
+		}
+	}
+	return nil
+}
+
+func (wl *Watchlist) validateSettings(s WalletSettings) error {
+	if s.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	if _, err := classify.ParseFilter(s.Filter, now(), wl.labels); err != nil {
+		return err
+	}
+	if s.PollInterval != "" {
+		d, err := time.ParseDuration(s.PollInterval)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid poll_interval %q", s.PollInterval)
+		}
+	}
+	return nil
+}
+
+// Select returns the enabled wallets carrying at least one of tags, or every
+// enabled wallet when tags is empty.
+func (wl *Watchlist) Select(tags []string) []WatchedWallet {
+	var out []WatchedWallet
+	for _, w := range wl.Wallets {
+		if w.Disabled {
+			continue
+		}
+		if len(tags) == 0 || w.hasAnyTag(tags) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func (w WatchedWallet) hasAnyTag(tags []string) bool {
+	for _, want := range tags {
+		for _, have := range w.Tags {
+			if strings.EqualFold(want, have) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Resolve merges the wallet's settings over the watchlist defaults. Settings
+// were validated on load, so parse errors cannot happen here.
+func (wl *Watchlist) Resolve(w WatchedWallet) ResolvedWalletSettings {
+	r := ResolvedWalletSettings{Limit: TRANSACTIONS_LIMIT, PollInterval: defaultPollInterval}
+	if wl.Defaults.Limit > 0 {
+		r.Limit = wl.Defaults.Limit
+	}
+	if w.Limit > 0 {
+		r.Limit = w.Limit
+	}
+
+	expr := wl.Defaults.Filter
+	if w.Filter != "" {
+		expr = w.Filter
+	}
+	r.Filter, _ = classify.ParseFilter(expr, now(), wl.labels)
+
+	interval := wl.Defaults.PollInterval
+	if w.PollInterval != "" {
+		interval = w.PollInterval
+	}
+	if d, err := time.ParseDuration(interval); err == nil && d > 0 {
+		r.PollInterval = d
+	}
+	return r
+}
+
+// RateLimit returns the shared requests-per-second budget.
+func (wl *Watchlist) RateLimit() float64 {
+	if wl.RequestsPerSecond > 0 {
+		return wl.RequestsPerSecond
+	}
+	return defaultRequestsPerSecond
+}
+
+// Workers returns how many wallets are processed at once.
+func (wl *Watchlist) Workers() int {
+	if wl.Concurrency > 0 {
+		return wl.Concurrency
+	}
+	return defaultWalletConcurrency
+}
+
+// shortAddress abbreviates a base58 string the same way the summary tables do.
+func shortAddress(s string) string {
+	if len(s) > 16 {
+		return s[:8] + "..." + s[len(s)-8:]
+	}
+	return s
+}
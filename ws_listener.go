@@ -2,12 +2,15 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/store"
 )
 
 // ListenWalletTransactions provides a minimal "live" listener using HTTP polling
@@ -15,46 +18,238 @@ import (
 // wsURL and repeatedly call getSignaturesForAddress, printing any new signatures.
 // This keeps dependencies minimal and works against Helius endpoints too.
 func ListenWalletTransactions(ctx context.Context, wsURL string, wallet solana.PublicKey) error {
-	httpURL := wsURL
-	if strings.HasPrefix(httpURL, "wss://") {
-		httpURL = "https://" + strings.TrimPrefix(httpURL, "wss://")
-	} else if strings.HasPrefix(httpURL, "ws://") {
-		httpURL = "http://" + strings.TrimPrefix(httpURL, "ws://")
+	httpURL := httpURLFromWS(wsURL)
+
+	client := NewRateLimitedClient(httpURL, 0)
+	slog.Info("Listening (poll) for transactions", "wallet", wallet.String())
+
+	return pollWalletTransactions(withLogAttrs(ctx, "wallet", wallet.String()), client, wallet, defaultPollInterval, nil, nil)
+}
+
+// httpURLFromWS maps a ws:// or wss:// endpoint to its HTTP counterpart.
+func httpURLFromWS(wsURL string) string {
+	if strings.HasPrefix(wsURL, "wss://") {
+		return "https://" + strings.TrimPrefix(wsURL, "wss://")
+	}
+	if strings.HasPrefix(wsURL, "ws://") {
+		return "http://" + strings.TrimPrefix(wsURL, "ws://")
 	}
+	return wsURL
+}
 
-	client := rpc.New(httpURL)
-	log.Printf("🔌 Listening (poll) for transactions mentioning %s ...", wallet.String())
+// Paging limits for getSignaturesForAddress while catching up.
+const (
+	signaturesPageSize   = 1000
+	maxCatchUpSignatures = 10 * signaturesPageSize
+)
 
-	seen := make(map[string]struct{})
-	// Seed with current known signatures so we only report NEW ones going forward
-	if sigs, err := client.GetSignaturesForAddress(ctx, wallet); err == nil {
-		for _, s := range sigs {
-			seen[s.Signature.String()] = struct{}{}
+// pollWalletTransactions polls getSignaturesForAddress every interval for
+// signatures newer than the wallet's last processed one and logs each, oldest
+// first, passing it to onNew when set. Log lines carry ctx's log attributes,
+// so callers attach the wallet with walletLogContext.
+//
+// With a checkpoint store the position survives restarts: the first poll
+// catches up on everything that landed since the stored signature. Without a
+// stored checkpoint only transactions after startup are reported. The
+// checkpoint advances only once onNew succeeds, so a failing handler sees the
+// same transaction again on the next poll (at-least-once). RPC failures are
+// retried, so it only returns once ctx ends.
+func pollWalletTransactions(ctx context.Context, client *rpc.Client, wallet solana.PublicKey, interval time.Duration, checkpoints *store.CheckpointStore, onNew func(ctx context.Context, sig *rpc.TransactionSignature) error) error {
+	last, ok := checkpoints.Get(wallet)
+	if ok {
+		slog.InfoContext(ctx, "Resuming from checkpoint", "signature", last.Signature, "slot", last.Slot)
+	} else {
+		// Start from the current tip so we only report NEW ones going forward
+		tip, err := startingPosition(ctx, client, wallet)
+		if err != nil {
+			return err
+		}
+		if tip != nil {
+			last = store.Checkpoint{Signature: tip.Signature.String(), Slot: tip.Slot}
+			if err := checkpoints.Set(wallet, last.Signature, last.Slot); err != nil {
+				slog.ErrorContext(ctx, "Saving checkpoint failed", "err", err)
+			}
+		}
+	}
+
+	seen := newRecentSet(defaultDedupeCapacity)
+	if last.Signature != "" {
+		seen.Add(last.Signature)
+	}
+
+	poll := func() {
+		sigs, err := signaturesSince(ctx, client, wallet, last)
+		if err != nil {
+			slog.WarnContext(ctx, "Poll failed", "err", err)
+			return
+		}
+		listenerLastPoll.Set(float64(time.Now().Unix()), wallet.String())
+		var newest *rpc.TransactionSignature
+		defer func() {
+			if newest != nil {
+				recordListenerLag(ctx, client, wallet, newest)
+			}
+		}()
+		// Iterate in reverse so older new entries are printed first
+		for i := len(sigs) - 1; i >= 0; i-- {
+			s := sigs[i]
+			sigStr := s.Signature.String()
+			if _, dup := seen.members[sigStr]; !dup {
+				sctx := withLogAttrs(ctx, "signature", sigStr)
+				slog.InfoContext(sctx, "Transaction observed", "slot", s.Slot)
+				if onNew != nil {
+					if err := onNew(sctx, s); err != nil {
+						// Keep the checkpoint here; the next poll retries from it
+						slog.WarnContext(sctx, "Handler failed, will retry", "err", err)
+						return
+					}
+				}
+				seen.Add(sigStr)
+				newest = s
+			}
+			last = store.Checkpoint{Signature: sigStr, Slot: s.Slot}
+			if err := checkpoints.Set(wallet, sigStr, s.Slot); err != nil {
+				slog.ErrorContext(ctx, "Saving checkpoint failed", "err", err)
+			}
 		}
 	}
-	ticker := time.NewTicker(4 * time.Second)
-	defer ticker.Stop()
 
+	if ok {
+		poll()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
-			sigs, err := client.GetSignaturesForAddress(ctx, wallet)
-			if err != nil {
-				log.Printf("poll error: %v", err)
-				continue
+			poll()
+		}
+	}
+}
+
+// startingPosition returns the wallet's newest signature, or nil when it has
+// none. Failures are retried with backoff until ctx ends, so an RPC hiccup at
+// startup does not stop the listener for good.
+func startingPosition(ctx context.Context, client *rpc.Client, wallet solana.PublicKey) (*rpc.TransactionSignature, error) {
+	limit := 1
+	backoff := time.Second
+	for {
+		sigs, err := client.GetSignaturesForAddressWithOpts(ctx, wallet, &rpc.GetSignaturesForAddressOpts{
+			Limit:      &limit,
+			Commitment: rpc.CommitmentConfirmed,
+		})
+		if err == nil {
+			if len(sigs) == 0 {
+				return nil, nil
 			}
-			// Iterate in reverse so older new entries are printed first
-			for i := len(sigs) - 1; i >= 0; i-- {
-				s := sigs[i]
-				sigStr := s.Signature.String()
-				if _, ok := seen[sigStr]; ok {
-					continue
-				}
-				seen[sigStr] = struct{}{}
-				log.Printf("🆕 Tx observed: %s (slot %d)", sigStr, s.Slot)
+			return sigs[0], nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		slog.WarnContext(ctx, "Reading starting position failed, retrying", "backoff", backoff, "err", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+// signaturesSince returns the wallet's signatures newer than last, newest
+// first, paging back until last (or its slot) is reached. Catch-up is capped
+// at maxCatchUpSignatures; older ones are skipped with a warning.
+func signaturesSince(ctx context.Context, client *rpc.Client, wallet solana.PublicKey, last store.Checkpoint) ([]*rpc.TransactionSignature, error) {
+	var until, before solana.Signature
+	if last.Signature != "" {
+		sig, err := solana.SignatureFromBase58(last.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint signature %q: %w", last.Signature, err)
+		}
+		until = sig
+	}
+
+	var out []*rpc.TransactionSignature
+	for {
+		limit := signaturesPageSize
+		page, err := client.GetSignaturesForAddressWithOpts(ctx, wallet, &rpc.GetSignaturesForAddressOpts{
+			Limit:      &limit,
+			Before:     before,
+			Until:      until,
+			Commitment: rpc.CommitmentConfirmed,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range page {
+			// The checkpoint signature may be gone (dropped fork); stop at its slot
+			if last.Slot > 0 && s.Slot < last.Slot {
+				return out, nil
 			}
+			out = append(out, s)
 		}
+		if len(page) < limit || until.IsZero() {
+			return out, nil
+		}
+		if len(out) >= maxCatchUpSignatures {
+			slog.WarnContext(ctx, "Too many new signatures since the checkpoint; older ones are skipped", "max", maxCatchUpSignatures)
+			return out, nil
+		}
+		before = page[len(page)-1].Signature
 	}
 }
+
+// recordListenerLag updates the lag gauges from the newest transaction a poll
+// reported: its age by block time, and its distance from the confirmed tip.
+func recordListenerLag(ctx context.Context, client *rpc.Client, wallet solana.PublicKey, newest *rpc.TransactionSignature) {
+	if newest.BlockTime != nil {
+		listenerLagSeconds.Set(time.Since(newest.BlockTime.Time()).Seconds(), wallet.String())
+	}
+	tip, err := client.GetSlot(ctx, rpc.CommitmentConfirmed)
+	if err != nil || tip < newest.Slot {
+		return
+	}
+	listenerLagSlots.Set(float64(tip-newest.Slot), wallet.String())
+}
+
+// defaultDedupeCapacity bounds how many recent signatures the listener
+// remembers per wallet to suppress duplicates.
+const defaultDedupeCapacity = 4096
+
+// recentSet remembers the last capacity strings in insertion order; adding
+// beyond that evicts the oldest. It replaces the listener's unbounded map.
+type recentSet struct {
+	capacity int
+	order    []string
+	next     int
+	members  map[string]struct{}
+}
+
+func newRecentSet(capacity int) *recentSet {
+	return &recentSet{capacity: capacity, members: make(map[string]struct{}, capacity)}
+}
+
+// Add records s and reports whether it was new.
+func (r *recentSet) Add(s string) bool {
+	if _, ok := r.members[s]; ok {
+		return false
+	}
+	if len(r.order) < r.capacity {
+		r.order = append(r.order, s)
+	} else {
+		delete(r.members, r.order[r.next])
+		r.order[r.next] = s
+		r.next = (r.next + 1) % r.capacity
+	}
+	r.members[s] = struct{}{}
+	return true
+}
+
+// Has reports whether s is in the set.
+func (r *recentSet) Has(s string) bool {
+	_, ok := r.members[s]
+	return ok
+}
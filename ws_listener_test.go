@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"go-solana-tx-explorer/store"
+)
+
+// historySig is the signature of the wallet's i-th transaction, which landed
+// in slot i+1.
+func historySig(i int) solana.Signature {
+	var sig solana.Signature
+	binary.BigEndian.PutUint32(sig[:], uint32(i)+1)
+	return sig
+}
+
+func historyIndex(sig solana.Signature) int {
+	return int(binary.BigEndian.Uint32(sig[:])) - 1
+}
+
+// historyNode serves one wallet's confirmed signatures the way
+// getSignaturesForAddress pages them: newest first, after before and down to
+// until.
+type historyNode struct {
+	mu    sync.Mutex
+	count int
+	pages int
+}
+
+func newHistoryNode(t *testing.T, count int) (*historyNode, *rpc.Client) {
+	t.Helper()
+	h := &historyNode{count: count}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return h, rpc.New(srv.URL)
+}
+
+// land appends n transactions to the history.
+func (h *historyNode) land(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.count += n
+}
+
+func (h *historyNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	msgs, _, err := decodeJSONRPC(body)
+	if err != nil || len(msgs) != 1 {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var result any
+	switch msgs[0].Method {
+	case "getSlot":
+		result = h.count
+	case "getSignaturesForAddress":
+		ps, _ := params(msgs[0].Params)
+		cfg := paramConfig(ps, 1)
+		h.pages++
+		out := []map[string]any{}
+		i := h.count - 1
+		if cfg.Before != "" {
+			i = historyIndex(solana.MustSignatureFromBase58(cfg.Before)) - 1
+		}
+		for ; i >= 0 && len(out) < cfg.Limit; i-- {
+			sig := historySig(i).String()
+			if sig == cfg.Until {
+				break
+			}
+			out = append(out, map[string]any{"signature": sig, "slot": i + 1, "err": nil, "confirmationStatus": "confirmed"})
+		}
+		result = out
+	default:
+		http.Error(w, "unexpected method "+msgs[0].Method, http.StatusBadRequest)
+		return
+	}
+	raw, _ := json.Marshal(result)
+	json.NewEncoder(w).Encode(jsonrpcMessage{JSONRPC: "2.0", ID: msgs[0].ID, Result: raw})
+}
+
+func checkpointAt(i int) store.Checkpoint {
+	return store.Checkpoint{Signature: historySig(i).String(), Slot: uint64(i + 1)}
+}
+
+func indexes(sigs []*rpc.TransactionSignature) []int {
+	out := make([]int, len(sigs))
+	for i, s := range sigs {
+		out[i] = historyIndex(s.Signature)
+	}
+	return out
+}
+
+func TestSignaturesSinceStopsAtCheckpoint(t *testing.T) {
+	_, client := newHistoryNode(t, 50)
+	wallet := solana.PublicKey{1}
+
+	sigs, err := signaturesSince(context.Background(), client, wallet, checkpointAt(44))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := indexes(sigs), []int{49, 48, 47, 46, 45}; !slices.Equal(got, want) {
+		t.Errorf("signatures = %v, want %v", got, want)
+	}
+
+	// A checkpoint signature on a dropped fork is never found; its slot stops
+	// the paging instead
+	dropped := store.Checkpoint{Signature: solana.Signature{0xff}.String(), Slot: 47}
+	if sigs, err = signaturesSince(context.Background(), client, wallet, dropped); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := indexes(sigs), []int{49, 48, 47, 46}; !slices.Equal(got, want) {
+		t.Errorf("signatures past a dropped checkpoint = %v, want %v", got, want)
+	}
+}
+
+func TestSignaturesSinceCapsCatchUp(t *testing.T) {
+	node, client := newHistoryNode(t, maxCatchUpSignatures+2*signaturesPageSize+10)
+
+	sigs, err := signaturesSince(context.Background(), client, solana.PublicKey{1}, checkpointAt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sigs) != maxCatchUpSignatures {
+		t.Fatalf("caught up on %d signatures, want the cap of %d", len(sigs), maxCatchUpSignatures)
+	}
+	// The newest are kept, contiguous
+	newest := node.count - 1
+	for i, s := range sigs {
+		if historyIndex(s.Signature) != newest-i {
+			t.Fatalf("signature %d is transaction %d, want %d", i, historyIndex(s.Signature), newest-i)
+		}
+	}
+	if node.pages != maxCatchUpSignatures/signaturesPageSize {
+		t.Errorf("read %d pages, want %d", node.pages, maxCatchUpSignatures/signaturesPageSize)
+	}
+}
+
+func TestRecentSetEvictsOldest(t *testing.T) {
+	set := newRecentSet(defaultDedupeCapacity)
+	for i := range defaultDedupeCapacity {
+		if !set.Add(historySig(i).String()) {
+			t.Fatalf("signature %d reported as seen", i)
+		}
+	}
+	// Re-adding a member neither counts as new nor evicts anything
+	if set.Add(historySig(0).String()) {
+		t.Error("member reported as new")
+	}
+	if !set.Has(historySig(0).String()) {
+		t.Fatal("oldest evicted before the set was full")
+	}
+
+	set.Add(historySig(defaultDedupeCapacity).String())
+	if set.Has(historySig(0).String()) {
+		t.Error("oldest member survived past capacity")
+	}
+	for _, i := range []int{1, defaultDedupeCapacity - 1, defaultDedupeCapacity} {
+		if !set.Has(historySig(i).String()) {
+			t.Errorf("signature %d was evicted", i)
+		}
+	}
+	if len(set.members) != defaultDedupeCapacity {
+		t.Errorf("set holds %d members, want %d", len(set.members), defaultDedupeCapacity)
+	}
+}
+
+// runPoll runs pollWalletTransactions until onNew has seen transaction last.
+func runPoll(t *testing.T, client *rpc.Client, wallet solana.PublicKey, checkpoints *store.CheckpointStore, last int, onNew func(i int) error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- pollWalletTransactions(ctx, client, wallet, 10*time.Millisecond, checkpoints, func(_ context.Context, sig *rpc.TransactionSignature) error {
+			i := historyIndex(sig.Signature)
+			err := onNew(i)
+			if err == nil && i == last {
+				cancel()
+			}
+			return err
+		})
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("listener stopped: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatalf("transaction %d was not delivered", last)
+	}
+}
+
+func TestPollWalletTransactionsResumesAndRetries(t *testing.T) {
+	node, client := newHistoryNode(t, 8)
+	wallet := solana.PublicKey{1}
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	checkpoints, err := store.OpenCheckpointStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkpoints.Set(wallet, checkpointAt(4).Signature, checkpointAt(4).Slot); err != nil {
+		t.Fatal(err)
+	}
+
+	// Delivery of transaction 6 fails once: the checkpoint stays on 5 and the
+	// next poll hands 6 over again
+	var attempts []int
+	failed := false
+	runPoll(t, client, wallet, checkpoints, 7, func(i int) error {
+		attempts = append(attempts, i)
+		if i == 6 && !failed {
+			failed = true
+			if cp, _ := checkpoints.Get(wallet); cp.Signature != historySig(5).String() {
+				t.Errorf("checkpoint = %s before the failed delivery, want transaction 5", cp.Signature)
+			}
+			return errors.New("sink unavailable")
+		}
+		return nil
+	})
+	if want := []int{5, 6, 6, 7}; !slices.Equal(attempts, want) {
+		t.Errorf("deliveries = %v, want %v", attempts, want)
+	}
+
+	// A restart picks up after the stored checkpoint only
+	reopened, err := store.OpenCheckpointStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp, _ := reopened.Get(wallet); cp.Signature != historySig(7).String() || cp.Slot != 8 {
+		t.Fatalf("stored checkpoint = %+v, want transaction 7", cp)
+	}
+	node.land(2)
+	attempts = nil
+	runPoll(t, client, wallet, reopened, 9, func(i int) error {
+		attempts = append(attempts, i)
+		return nil
+	})
+	if want := []int{8, 9}; !slices.Equal(attempts, want) {
+		t.Errorf("deliveries after restart = %v, want %v", attempts, want)
+	}
+}